@@ -1,10 +1,16 @@
 package action
 
 import (
+	"slices"
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/tower/targeting"
 	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/ability"
+	"github.com/xescugc/maze-wars/unit/buff"
+	"github.com/xescugc/maze-wars/unit/hero"
 	"github.com/xescugc/maze-wars/utils"
 	"github.com/xescugc/maze-wars/utils/graph"
 )
@@ -16,7 +22,10 @@ type Action struct {
 	CursorMove           *CursorMovePayload           `json:"cursor_move,omitempty"`
 	SummonUnit           *SummonUnitPayload           `json:"summon_unit,omitempty"`
 	UpdateUnit           *UpdateUnitPayload           `json:"update_unit,omitempty"`
+	SummonHero           *SummonHeroPayload           `json:"summon_hero,omitempty"`
+	UseHeroAbility       *UseHeroAbilityPayload       `json:"use_hero_ability,omitempty"`
 	UpdateTower          *UpdateTowerPayload          `json:"update_tower,omitempty"`
+	UpdateTowerTargeting *UpdateTowerTargetingPayload `json:"update_tower_targeting,omitempty"`
 	CameraZoom           *CameraZoomPayload           `json:"camera_zoom,omitempty"`
 	SelectTower          *SelectTowerPayload          `json:"select_tower,omitempty"`
 	PlaceTower           *PlaceTowerPayload           `json:"place_tower,omitempty"`
@@ -50,6 +59,12 @@ type Action struct {
 	UpdateLobby *UpdateLobbyPayload `json:"update_lobby,omitempty"`
 	StartLobby  *StartLobbyPayload  `json:"start_lobby,omitempty"`
 
+	AddGames         *AddGamesPayload         `json:"add_games,omitempty"`
+	AddBalances      *AddBalancesPayload      `json:"add_balances,omitempty"`
+	SpectateGame     *SpectateGamePayload     `json:"spectate_game,omitempty"`
+	ExitSpectateGame *ExitSpectateGamePayload `json:"exit_spectate_game,omitempty"`
+	GoToLine         *GoToLinePayload         `json:"go_to_line,omitempty"`
+
 	UserSignUp            *UserSignUpPayload            `json:"user_sign_up,omitempty"`
 	UserSignUpChangeImage *UserSignUpChangeImagePayload `json:"user_sign_up_change_image,omitempty"`
 	SignUpError           *SignUpErrorPayload           `json:"sign_in_error,omitempty"`
@@ -59,7 +74,10 @@ type Action struct {
 	AddPlayer       *AddPlayerPayload       `json:"add_player,omitempty"`
 	RemovePlayer    *RemovePlayerPayload    `json:"remove_player,omitempty"`
 	SyncState       *SyncStatePayload       `json:"sync_state,omitempty"`
+	AckSyncState    *AckSyncStatePayload    `json:"ack_sync_state,omitempty"`
 	SyncWaitingRoom *SyncWaitingRoomPayload `json:"sync_waiting_room,omitempty"`
+	SyncUser        *SyncUserPayload        `json:"sync_user,omitempty"`
+	UserDisconnect  *UserDisconnectPayload  `json:"user_disconnect,omitempty"`
 }
 
 type CursorMovePayload struct {
@@ -98,6 +116,9 @@ func NewSummonUnit(t, pid string, plid, clid int) *Action {
 	}
 }
 
+// TPSPayload advances the simulation. If Time is set
+// it'll advance as many ticks as needed to reach it from
+// the start of the game, if not it'll advance just one
 type TPSPayload struct {
 	Time time.Time
 }
@@ -264,13 +285,29 @@ func NewNavigateTo(route string) *Action {
 
 type StartGamePayload struct {
 	State SyncStatePayload
+
+	// Seed is used to initialize the RNG of the simulation
+	Seed int64
+
+	// ResumeToken is used to resume the Game if
+	// the connection is lost, it's only set for
+	// the players
+	ResumeToken string
+
+	// BalanceHash is the hash of the balance pack
+	// the Game uses, empty if it's the default one
+	BalanceHash string
+
+	// Rules are the GameRules picked on the lobby
+	Rules GameRules
 }
 
-func NewStartGame(state SyncStatePayload) *Action {
+func NewStartGame(state SyncStatePayload, seed int64) *Action {
 	return &Action{
 		Type: StartGame,
 		StartGame: &StartGamePayload{
 			State: state,
+			Seed:  seed,
 		},
 	}
 }
@@ -356,6 +393,18 @@ type UserSignInPayload struct {
 	Websocket  *websocket.Conn
 	RemoteAddr string
 	ImageKey   string
+
+	// Codec is the name of the codec.Codec the
+	// client wants to receive the messages with
+	Codec string
+
+	// Token is the Session token returned
+	// when signing up or in
+	Token string
+
+	// ResumeToken is the one from the StartGame, if
+	// present the Game in progress is resumed
+	ResumeToken string
 }
 
 // NewUserSignIn initializes the UserSignIn with just the username
@@ -383,14 +432,20 @@ func NewUserSignOut(un string) *Action {
 }
 
 type UserSignUpPayload struct {
+	// ID is the ID of the Account of the User
+	ID       string
 	Username string
 	ImageKey string
+
+	// Rating is the ranked Rating of the Account
+	Rating int
 }
 
-func NewUserSignUp(un, ik string) *Action {
+func NewUserSignUp(id, un, ik string) *Action {
 	return &Action{
 		Type: UserSignUp,
 		UserSignUp: &UserSignUpPayload{
+			ID:       id,
 			Username: un,
 			ImageKey: ik,
 		},
@@ -411,9 +466,26 @@ func NewUserSignUpChangeImage(ik string) *Action {
 }
 
 type SyncStatePayload struct {
+	// Version is the SyncStateVersion used
+	// to build this payload
+	Version int
+
+	// SnapshotID identifies this state so the client can
+	// acknowledge it and be used as the base of the next delta
+	SnapshotID int
+
+	// BaseSnapshotID is the snapshot this delta has to be applied
+	// on, it's only used when it's not a Keyframe
+	BaseSnapshotID int
+
+	// Keyframe is when the payload has the full state
+	// and not only the changes from the BaseSnapshotID
+	Keyframe bool
+
 	Players   *SyncStatePlayersPayload
 	Lines     *SyncStateLinesPayload
 	StartedAt time.Time
+	Tick      int
 
 	Error   string
 	ErrorAt time.Time
@@ -421,6 +493,8 @@ type SyncStatePayload struct {
 
 type SyncStateLinesPayload struct {
 	Lines map[int]*SyncStateLinePayload
+
+	RemovedLines []int `json:",omitempty"`
 }
 
 type SyncStateLinePayload struct {
@@ -428,11 +502,21 @@ type SyncStateLinePayload struct {
 	Towers      map[string]*SyncStateTowerPayload
 	Units       map[string]*SyncStateUnitPayload
 	Projectiles map[string]*SyncStateProjectilePayload
+
+	RemovedTowers      []string `json:",omitempty"`
+	RemovedUnits       []string `json:",omitempty"`
+	RemovedProjectiles []string `json:",omitempty"`
+
+	// UnitsPathConsumed has, for the Units of the delta without Path,
+	// the number of Steps consumed from the Path they had on the base
+	UnitsPathConsumed map[string]int `json:",omitempty"`
 }
 
 type SyncStatePlayersPayload struct {
 	Players     map[string]*SyncStatePlayerPayload
 	IncomeTimer int
+
+	RemovedPlayers []string `json:",omitempty"`
 }
 
 type SyncStatePlayerPayload struct {
@@ -449,6 +533,14 @@ type SyncStatePlayerPayload struct {
 	Capacity int
 
 	UnitUpdates map[string]SyncStatePlayerUnitUpdatePayload
+	Hero        SyncStatePlayerHeroPayload
+}
+
+type SyncStatePlayerHeroPayload struct {
+	UnitID   string
+	Summoned bool
+	XP       int
+	UsedAt   map[hero.Ability]time.Time
 }
 
 type SyncStatePlayerUnitUpdatePayload struct {
@@ -468,6 +560,7 @@ type SyncStateTowerPayload struct {
 
 	Health float64
 
+	Targeting    targeting.Targeting
 	TargetUnitID string
 	LastAttack   time.Time
 }
@@ -491,15 +584,19 @@ type SyncStateUnitPayload struct {
 	MovementSpeed float64
 	Bounty        int
 
+	Damage float64
+
 	Level int
+	Hero  bool
 
-	Path      []graph.Step
-	HashPath  string
-	CreatedAt time.Time
+	Path     []graph.Step
+	HashPath string
 
 	Abilities map[string]interface{}
 	Buffs     map[string]interface{}
 
+	Auras []ability.Ability
+
 	TargetTowerID string
 	LastAttack    time.Time
 }
@@ -515,6 +612,10 @@ type SyncStateProjectilePayload struct {
 	AoE       int
 	AoEDamage float64
 
+	DamageType damage.Damage
+
+	Effect *buff.Effect
+
 	PlayerID string
 
 	ImageKey string
@@ -522,18 +623,67 @@ type SyncStateProjectilePayload struct {
 	Type string
 }
 
-// TODO: or make the action.Action separated or make the store.Player separated
-func NewSyncState(players *SyncStatePlayersPayload, lines *SyncStateLinesPayload, sa time.Time) *Action {
+// AckSyncStatePayload acknowledges that the SnapshotID has been
+// applied, the Username is set by the server from the connection
+type AckSyncStatePayload struct {
+	Username   string
+	SnapshotID int
+}
+
+func NewAckSyncState(sid int) *Action {
 	return &Action{
-		Type: SyncState,
-		SyncState: &SyncStatePayload{
-			Players:   players,
-			Lines:     lines,
-			StartedAt: sa,
+		Type: AckSyncState,
+		AckSyncState: &AckSyncStatePayload{
+			SnapshotID: sid,
 		},
 	}
 }
 
+// SyncUserPayload is the profile of the User, it's
+// only sent by the server
+type SyncUserPayload struct {
+	Username string
+	Rating   int
+
+	// RatingChange is the change of the
+	// Rating on the last ranked Game
+	RatingChange int
+}
+
+func NewSyncUser(un string, r, rc int) *Action {
+	return &Action{
+		Type: SyncUser,
+		SyncUser: &SyncUserPayload{
+			Username:     un,
+			Rating:       r,
+			RatingChange: rc,
+		},
+	}
+}
+
+// UserDisconnectPayload is dispatched by the server when
+// the connection of a User is lost while on a Game
+type UserDisconnectPayload struct {
+	Username string
+}
+
+func NewUserDisconnect(un string) *Action {
+	return &Action{
+		Type: UserDisconnect,
+		UserDisconnect: &UserDisconnectPayload{
+			Username: un,
+		},
+	}
+}
+
+// TODO: or make the action.Action separated or make the store.Player separated
+func NewSyncState(ss SyncStatePayload) *Action {
+	return &Action{
+		Type:      SyncState,
+		SyncState: &ss,
+	}
+}
+
 type VersionErrorPayload struct {
 	Error string
 }
@@ -562,6 +712,40 @@ func NewUpdateUnit(pid, t string) *Action {
 	}
 }
 
+type SummonHeroPayload struct {
+	PlayerID      string
+	PlayerLineID  int
+	CurrentLineID int
+}
+
+// NewSummonHero summons the Hero of the Player pid to the line clid
+func NewSummonHero(pid string, plid, clid int) *Action {
+	return &Action{
+		Type: SummonHero,
+		SummonHero: &SummonHeroPayload{
+			PlayerID:      pid,
+			PlayerLineID:  plid,
+			CurrentLineID: clid,
+		},
+	}
+}
+
+type UseHeroAbilityPayload struct {
+	PlayerID string
+	Ability  hero.Ability
+}
+
+// NewUseHeroAbility uses the Ability a of the Hero of the Player pid
+func NewUseHeroAbility(pid string, a hero.Ability) *Action {
+	return &Action{
+		Type: UseHeroAbility,
+		UseHeroAbility: &UseHeroAbilityPayload{
+			PlayerID: pid,
+			Ability:  a,
+		},
+	}
+}
+
 type UpdateTowerPayload struct {
 	TowerID   string
 	PlayerID  string
@@ -579,14 +763,73 @@ func NewUpdateTower(pid, tid, tt string) *Action {
 	}
 }
 
+type UpdateTowerTargetingPayload struct {
+	TowerID   string
+	PlayerID  string
+	Targeting targeting.Targeting
+}
+
+// NewUpdateTowerTargeting changes the Targeting of the Tower tid
+func NewUpdateTowerTargeting(pid, tid string, tg targeting.Targeting) *Action {
+	return &Action{
+		Type: UpdateTowerTargeting,
+		UpdateTowerTargeting: &UpdateTowerTargetingPayload{
+			TowerID:   tid,
+			PlayerID:  pid,
+			Targeting: tg,
+		},
+	}
+}
+
 type CreateLobbyPayload struct {
 	LobbyID         string
 	Owner           string
 	LobbyName       string
 	LobbyMaxPlayers int
+
+	// LobbyBalance is the name of the balance
+	// pack, empty to use the default one
+	LobbyBalance string
+
+	// LobbyRules are the rules of the Game of the lobby
+	LobbyRules GameRules
 }
 
-func NewCreateLobby(lid, o, ln string, lmp int) *Action {
+// GameRules are the rules of a Game that can be picked on the
+// lobby, the zero values fallback to the ones of the balance
+type GameRules struct {
+	// Gold, Income and Lives are the ones each Player starts with
+	Gold   int
+	Income int
+	Lives  int
+
+	// IncomeTimer is the seconds between each income
+	IncomeTimer int
+
+	// MaxCapacity is the number of Units each Player can have summoned
+	MaxCapacity int
+
+	// SuddenDeath is the seconds after which any Unit that reaches
+	// the end steals all the lives of the Player, 0 disables it
+	SuddenDeath int
+
+	// Units and Towers are the types that can be
+	// used on the Game, if empty all of them can
+	Units  []string
+	Towers []string
+}
+
+// AllowsUnit returns if the Unit type ut can be used
+func (gr GameRules) AllowsUnit(ut string) bool {
+	return len(gr.Units) == 0 || slices.Contains(gr.Units, ut)
+}
+
+// AllowsTower returns if the Tower type tt can be used
+func (gr GameRules) AllowsTower(tt string) bool {
+	return len(gr.Towers) == 0 || slices.Contains(gr.Towers, tt)
+}
+
+func NewCreateLobby(lid, o, ln string, lmp int, lb string, gr GameRules) *Action {
 	return &Action{
 		Type: CreateLobby,
 		CreateLobby: &CreateLobbyPayload{
@@ -594,6 +837,8 @@ func NewCreateLobby(lid, o, ln string, lmp int) *Action {
 			Owner:           o,
 			LobbyName:       ln,
 			LobbyMaxPlayers: lmp,
+			LobbyBalance:    lb,
+			LobbyRules:      gr,
 		},
 	}
 }
@@ -636,6 +881,7 @@ type LobbyPayload struct {
 	ID         string
 	Name       string
 	MaxPlayers int
+	Balance    string
 
 	Players map[string]bool
 
@@ -703,6 +949,83 @@ func NewStartLobby(lid string) *Action {
 	}
 }
 
+type AddGamesPayload struct {
+	Games []*GamePayload
+}
+
+// GamePayload is a Game that is currently being played
+type GamePayload struct {
+	ID        string
+	Players   []string
+	StartedAt time.Time
+}
+
+func NewAddGames(gms *AddGamesPayload) *Action {
+	return &Action{
+		Type:     AddGames,
+		AddGames: gms,
+	}
+}
+
+// AddBalancesPayload has the names of the balance
+// packs that can be picked when creating a lobby
+type AddBalancesPayload struct {
+	Balances []string
+}
+
+func NewAddBalances(bs []string) *Action {
+	return &Action{
+		Type: AddBalances,
+		AddBalances: &AddBalancesPayload{
+			Balances: bs,
+		},
+	}
+}
+
+// SpectateGamePayload joins the user as a spectator
+// of the Game running on the Room
+type SpectateGamePayload struct {
+	Username string
+	RoomID   string
+}
+
+func NewSpectateGame(un, rid string) *Action {
+	return &Action{
+		Type: SpectateGame,
+		SpectateGame: &SpectateGamePayload{
+			Username: un,
+			RoomID:   rid,
+		},
+	}
+}
+
+// ExitSpectateGamePayload stops spectating the current Game,
+// the Username is set by the server from the connection
+type ExitSpectateGamePayload struct {
+	Username string
+}
+
+func NewExitSpectateGame() *Action {
+	return &Action{
+		Type:             ExitSpectateGame,
+		ExitSpectateGame: &ExitSpectateGamePayload{},
+	}
+}
+
+// GoToLinePayload moves the camera to the LineID
+type GoToLinePayload struct {
+	LineID int
+}
+
+func NewGoToLine(lid int) *Action {
+	return &Action{
+		Type: GoToLine,
+		GoToLine: &GoToLinePayload{
+			LineID: lid,
+		},
+	}
+}
+
 type SetupGamePayload struct {
 	Display bool
 }
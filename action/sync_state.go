@@ -0,0 +1,179 @@
+package action
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+)
+
+const (
+	// SyncStateVersion is the version of the SyncState protocol, it has to
+	// be increased every time the way the deltas are computed changes
+	SyncStateVersion = 3
+
+	// SyncStateHistory is the number of snapshots kept
+	// to be used as the base of the deltas
+	SyncStateHistory = 20
+)
+
+// Diff returns the delta from the base to ss. It only has the entities that
+// changed or are new and the IDs of the removed ones as tombstones, the
+// rest of the values are always the ones from ss.
+func (ss SyncStatePayload) Diff(base SyncStatePayload) SyncStatePayload {
+	d := ss
+	d.Keyframe = false
+	d.BaseSnapshotID = base.SnapshotID
+
+	d.Players = &SyncStatePlayersPayload{
+		IncomeTimer: ss.Players.IncomeTimer,
+	}
+	d.Players.Players, d.Players.RemovedPlayers = diffEntities(base.Players.Players, ss.Players.Players)
+
+	d.Lines = &SyncStateLinesPayload{
+		Lines: make(map[int]*SyncStateLinePayload),
+	}
+	for lid, l := range ss.Lines.Lines {
+		bl, ok := base.Lines.Lines[lid]
+		if !ok {
+			d.Lines.Lines[lid] = l
+			continue
+		}
+		dl := &SyncStateLinePayload{
+			ID: l.ID,
+		}
+		dl.Towers, dl.RemovedTowers = diffEntities(bl.Towers, l.Towers)
+		dl.Units, dl.RemovedUnits = diffEntities(bl.Units, l.Units)
+		dl.Units, dl.UnitsPathConsumed = omitUnitsPath(bl.Units, dl.Units)
+		dl.Projectiles, dl.RemovedProjectiles = diffEntities(bl.Projectiles, l.Projectiles)
+		if len(dl.Towers) != 0 || len(dl.Units) != 0 || len(dl.Projectiles) != 0 ||
+			len(dl.RemovedTowers) != 0 || len(dl.RemovedUnits) != 0 || len(dl.RemovedProjectiles) != 0 {
+			d.Lines.Lines[lid] = dl
+		}
+	}
+	for lid := range base.Lines.Lines {
+		if _, ok := ss.Lines.Lines[lid]; !ok {
+			d.Lines.RemovedLines = append(d.Lines.RemovedLines, lid)
+		}
+	}
+	sort.Ints(d.Lines.RemovedLines)
+
+	return d
+}
+
+// Apply returns the full SyncStatePayload result of applying
+// the delta d, created with Diff, on top of ss
+func (ss SyncStatePayload) Apply(d SyncStatePayload) SyncStatePayload {
+	f := d
+	f.Keyframe = true
+	f.BaseSnapshotID = 0
+
+	f.Players = &SyncStatePlayersPayload{
+		IncomeTimer: d.Players.IncomeTimer,
+		Players:     applyEntities(ss.Players.Players, d.Players.Players, d.Players.RemovedPlayers),
+	}
+
+	f.Lines = &SyncStateLinesPayload{
+		Lines: make(map[int]*SyncStateLinePayload),
+	}
+	for lid, l := range ss.Lines.Lines {
+		f.Lines.Lines[lid] = l
+	}
+	for _, lid := range d.Lines.RemovedLines {
+		delete(f.Lines.Lines, lid)
+	}
+	for lid, dl := range d.Lines.Lines {
+		bl, ok := f.Lines.Lines[lid]
+		// A new line is always sent complete
+		if !ok {
+			f.Lines.Lines[lid] = dl
+			continue
+		}
+		f.Lines.Lines[lid] = &SyncStateLinePayload{
+			ID:          dl.ID,
+			Towers:      applyEntities(bl.Towers, dl.Towers, dl.RemovedTowers),
+			Units:       restoreUnitsPath(bl.Units, applyEntities(bl.Units, dl.Units, dl.RemovedUnits), dl.UnitsPathConsumed),
+			Projectiles: applyEntities(bl.Projectiles, dl.Projectiles, dl.RemovedProjectiles),
+		}
+	}
+
+	return f
+}
+
+// diffEntities returns the entities of cur that are new or different
+// from the ones on base and the sorted IDs of the ones removed
+func diffEntities[T any](base, cur map[string]*T) (map[string]*T, []string) {
+	changed := make(map[string]*T)
+	for id, e := range cur {
+		if be, ok := base[id]; !ok || !reflect.DeepEqual(*be, *e) {
+			changed[id] = e
+		}
+	}
+	var removed []string
+	for id := range base {
+		if _, ok := cur[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// omitUnitsPath returns the changed Units without the Path if it's the one of the
+// base with some Steps consumed, as the Units move every tick but the Path rarely
+// changes, and the number of Steps consumed of each so the client can trim it
+func omitUnitsPath(base, changed map[string]*SyncStateUnitPayload) (map[string]*SyncStateUnitPayload, map[string]int) {
+	var consumed map[string]int
+	for id, u := range changed {
+		bu, ok := base[id]
+		if !ok || len(u.Path) == 0 || bu.HashPath != u.HashPath || len(u.Path) > len(bu.Path) {
+			continue
+		}
+		n := len(bu.Path) - len(u.Path)
+		if !slices.Equal(bu.Path[n:], u.Path) {
+			continue
+		}
+		if consumed == nil {
+			consumed = make(map[string]int)
+		}
+		cu := *u
+		cu.Path = nil
+		changed[id] = &cu
+		consumed[id] = n
+	}
+	return changed, consumed
+}
+
+// restoreUnitsPath sets to the Units which Path was omitted by omitUnitsPath
+// the Path from the base without the Steps consumed
+func restoreUnitsPath(base, units map[string]*SyncStateUnitPayload, consumed map[string]int) map[string]*SyncStateUnitPayload {
+	for id, n := range consumed {
+		u, ok := units[id]
+		if !ok {
+			continue
+		}
+		bu, ok := base[id]
+		if !ok || n > len(bu.Path) {
+			continue
+		}
+		cu := *u
+		cu.Path = bu.Path[n:]
+		units[id] = &cu
+	}
+	return units
+}
+
+// applyEntities returns a new map with the base entities
+// updated with the changed ones and without the removed
+func applyEntities[T any](base, changed map[string]*T, removed []string) map[string]*T {
+	res := make(map[string]*T, len(base)+len(changed))
+	for id, e := range base {
+		res[id] = e
+	}
+	for _, id := range removed {
+		delete(res, id)
+	}
+	for id, e := range changed {
+		res[id] = e
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+package action_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/utils"
+	"github.com/xescugc/maze-wars/utils/graph"
+)
+
+func newSyncState(sid int, units map[string]*action.SyncStateUnitPayload, towers map[string]*action.SyncStateTowerPayload) action.SyncStatePayload {
+	return action.SyncStatePayload{
+		Version:    action.SyncStateVersion,
+		SnapshotID: sid,
+		Keyframe:   true,
+		Players: &action.SyncStatePlayersPayload{
+			Players: map[string]*action.SyncStatePlayerPayload{
+				"p1": {ID: "p1", Gold: sid},
+				"p2": {ID: "p2"},
+			},
+			IncomeTimer: sid,
+		},
+		Lines: &action.SyncStateLinesPayload{
+			Lines: map[int]*action.SyncStateLinePayload{
+				0: {
+					ID:          0,
+					Towers:      towers,
+					Units:       units,
+					Projectiles: map[string]*action.SyncStateProjectilePayload{},
+				},
+			},
+		},
+		Tick: sid,
+	}
+}
+
+func TestSyncStatePayload_DiffApply(t *testing.T) {
+	tw := &action.SyncStateTowerPayload{ID: "t1", Object: utils.Object{X: 1, Y: 1}}
+	base := newSyncState(1,
+		map[string]*action.SyncStateUnitPayload{
+			"u1": {ID: "u1", Health: 10},
+			"u2": {ID: "u2", Health: 10},
+		},
+		map[string]*action.SyncStateTowerPayload{"t1": tw},
+	)
+	cur := newSyncState(2,
+		map[string]*action.SyncStateUnitPayload{
+			"u1": {ID: "u1", Health: 5},
+			"u3": {ID: "u3", Health: 10},
+		},
+		map[string]*action.SyncStateTowerPayload{"t1": tw},
+	)
+	delete(cur.Players.Players, "p2")
+
+	d := cur.Diff(base)
+
+	assert.False(t, d.Keyframe)
+	assert.Equal(t, 1, d.BaseSnapshotID)
+	assert.Equal(t, 2, d.SnapshotID)
+	assert.Equal(t, []string{"p1"}, keys(d.Players.Players))
+	assert.Equal(t, []string{"p2"}, d.Players.RemovedPlayers)
+
+	dl := d.Lines.Lines[0]
+	assert.Empty(t, dl.Towers)
+	assert.ElementsMatch(t, []string{"u1", "u3"}, keys(dl.Units))
+	assert.Equal(t, []string{"u2"}, dl.RemovedUnits)
+
+	assert.Equal(t, cur, base.Apply(d))
+
+	t.Run("UnchangedPath", func(t *testing.T) {
+		path := []graph.Step{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}}
+		unit := func(id string, hp string, p []graph.Step) *action.SyncStateUnitPayload {
+			u := &action.SyncStateUnitPayload{ID: id, Path: p, HashPath: hp}
+			u.X, u.Y = 1, 3-float64(len(p))
+			return u
+		}
+		base := newSyncState(1,
+			map[string]*action.SyncStateUnitPayload{
+				"u1": unit("u1", "h1", path),
+				"u2": unit("u2", "h1", path),
+			},
+			map[string]*action.SyncStateTowerPayload{},
+		)
+		cur := newSyncState(2,
+			map[string]*action.SyncStateUnitPayload{
+				"u1": unit("u1", "h1", path[2:]),
+				"u2": unit("u2", "h2", path[:1]),
+			},
+			map[string]*action.SyncStateTowerPayload{},
+		)
+
+		d := cur.Diff(base)
+		dl := d.Lines.Lines[0]
+		assert.Nil(t, dl.Units["u1"].Path)
+		assert.Equal(t, map[string]int{"u1": 2}, dl.UnitsPathConsumed)
+		assert.Equal(t, path[:1], dl.Units["u2"].Path)
+		// The current state is not changed
+		assert.Equal(t, path[2:], cur.Lines.Lines[0].Units["u1"].Path)
+
+		// The omitted Path is the one of the base without the consumed Steps
+		f := base.Apply(d)
+		fu := f.Lines.Lines[0].Units["u1"]
+		assert.Equal(t, path[2:], fu.Path)
+		assert.Equal(t, float64(1), fu.X)
+		assert.Equal(t, float64(2), fu.Y)
+		assert.Equal(t, cur, f)
+	})
+	t.Run("NoChanges", func(t *testing.T) {
+		d := base.Diff(base)
+		assert.Empty(t, d.Lines.Lines)
+		assert.Empty(t, d.Players.Players)
+		assert.Equal(t, base, base.Apply(d))
+	})
+}
+
+func keys[T any](m map[string]T) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	return res
+}
@@ -50,6 +50,10 @@ const (
 	StartLobby
 	SeenLobbies
 
+	AddGames
+	AddBalances
+	GoToLine
+
 	// Specific to WS
 	AddPlayer
 	RemovePlayer
@@ -58,4 +62,12 @@ const (
 	SyncSearchingRoom
 	SyncWaitingRoom
 	SyncWaitingRooms
+	SpectateGame
+	ExitSpectateGame
+	AckSyncState
+	SyncUser
+	UserDisconnect
+	UpdateTowerTargeting
+	SummonHero
+	UseHeroAbility
 )
@@ -8,11 +8,11 @@ import (
 	"strings"
 )
 
-const _TypeName = "cursor_movecamera_zoomsummon_unitupdate_unitupdate_towertpsplace_towerremove_towerselect_towerselected_towerselected_tower_invaliddeselect_towerincome_tickwindow_resizingnavigate_tostart_gameopen_tower_menuopen_unit_menuclose_tower_menuclose_unit_menugo_homesign_up_erroruser_sign_upuser_sign_up_change_imageuser_sign_inuser_sign_outversion_errorsetup_gamefind_gameexit_searching_gameaccept_waiting_gamecancel_waiting_gameshow_scoreboardadd_errorcreate_lobbydelete_lobbyjoin_lobbyadd_lobbiesselect_lobbyleave_lobbyupdate_lobbystart_lobbyseen_lobbiesadd_playerremove_playersync_statesync_lobbiessync_searching_roomsync_waiting_roomsync_waiting_rooms"
+const _TypeName = "cursor_movecamera_zoomsummon_unitupdate_unitupdate_towertpsplace_towerremove_towerselect_towerselected_towerselected_tower_invaliddeselect_towerincome_tickwindow_resizingnavigate_tostart_gameopen_tower_menuopen_unit_menuclose_tower_menuclose_unit_menugo_homesign_up_erroruser_sign_upuser_sign_up_change_imageuser_sign_inuser_sign_outversion_errorsetup_gamefind_gameexit_searching_gameaccept_waiting_gamecancel_waiting_gameshow_scoreboardadd_errorcreate_lobbydelete_lobbyjoin_lobbyadd_lobbiesselect_lobbyleave_lobbyupdate_lobbystart_lobbyseen_lobbiesadd_gamesadd_balancesgo_to_lineadd_playerremove_playersync_statesync_lobbiessync_searching_roomsync_waiting_roomsync_waiting_roomsspectate_gameexit_spectate_gameack_sync_statesync_useruser_disconnectupdate_tower_targetingsummon_herouse_hero_ability"
 
-var _TypeIndex = [...]uint16{0, 11, 22, 33, 44, 56, 59, 70, 82, 94, 108, 130, 144, 155, 170, 181, 191, 206, 220, 236, 251, 258, 271, 283, 308, 320, 333, 346, 356, 365, 384, 403, 422, 437, 446, 458, 470, 480, 491, 503, 514, 526, 537, 549, 559, 572, 582, 594, 613, 630, 648}
+var _TypeIndex = [...]uint16{0, 11, 22, 33, 44, 56, 59, 70, 82, 94, 108, 130, 144, 155, 170, 181, 191, 206, 220, 236, 251, 258, 271, 283, 308, 320, 333, 346, 356, 365, 384, 403, 422, 437, 446, 458, 470, 480, 491, 503, 514, 526, 537, 549, 558, 570, 580, 590, 603, 613, 625, 644, 661, 679, 692, 710, 724, 733, 748, 770, 781, 797}
 
-const _TypeLowerName = "cursor_movecamera_zoomsummon_unitupdate_unitupdate_towertpsplace_towerremove_towerselect_towerselected_towerselected_tower_invaliddeselect_towerincome_tickwindow_resizingnavigate_tostart_gameopen_tower_menuopen_unit_menuclose_tower_menuclose_unit_menugo_homesign_up_erroruser_sign_upuser_sign_up_change_imageuser_sign_inuser_sign_outversion_errorsetup_gamefind_gameexit_searching_gameaccept_waiting_gamecancel_waiting_gameshow_scoreboardadd_errorcreate_lobbydelete_lobbyjoin_lobbyadd_lobbiesselect_lobbyleave_lobbyupdate_lobbystart_lobbyseen_lobbiesadd_playerremove_playersync_statesync_lobbiessync_searching_roomsync_waiting_roomsync_waiting_rooms"
+const _TypeLowerName = "cursor_movecamera_zoomsummon_unitupdate_unitupdate_towertpsplace_towerremove_towerselect_towerselected_towerselected_tower_invaliddeselect_towerincome_tickwindow_resizingnavigate_tostart_gameopen_tower_menuopen_unit_menuclose_tower_menuclose_unit_menugo_homesign_up_erroruser_sign_upuser_sign_up_change_imageuser_sign_inuser_sign_outversion_errorsetup_gamefind_gameexit_searching_gameaccept_waiting_gamecancel_waiting_gameshow_scoreboardadd_errorcreate_lobbydelete_lobbyjoin_lobbyadd_lobbiesselect_lobbyleave_lobbyupdate_lobbystart_lobbyseen_lobbiesadd_gamesadd_balancesgo_to_lineadd_playerremove_playersync_statesync_lobbiessync_searching_roomsync_waiting_roomsync_waiting_roomsspectate_gameexit_spectate_gameack_sync_statesync_useruser_disconnectupdate_tower_targetingsummon_herouse_hero_ability"
 
 func (i Type) String() string {
 	if i < 0 || i >= Type(len(_TypeIndex)-1) {
@@ -68,16 +68,27 @@ func _TypeNoOp() {
 	_ = x[UpdateLobby-(40)]
 	_ = x[StartLobby-(41)]
 	_ = x[SeenLobbies-(42)]
-	_ = x[AddPlayer-(43)]
-	_ = x[RemovePlayer-(44)]
-	_ = x[SyncState-(45)]
-	_ = x[SyncLobbies-(46)]
-	_ = x[SyncSearchingRoom-(47)]
-	_ = x[SyncWaitingRoom-(48)]
-	_ = x[SyncWaitingRooms-(49)]
+	_ = x[AddGames-(43)]
+	_ = x[AddBalances-(44)]
+	_ = x[GoToLine-(45)]
+	_ = x[AddPlayer-(46)]
+	_ = x[RemovePlayer-(47)]
+	_ = x[SyncState-(48)]
+	_ = x[SyncLobbies-(49)]
+	_ = x[SyncSearchingRoom-(50)]
+	_ = x[SyncWaitingRoom-(51)]
+	_ = x[SyncWaitingRooms-(52)]
+	_ = x[SpectateGame-(53)]
+	_ = x[ExitSpectateGame-(54)]
+	_ = x[AckSyncState-(55)]
+	_ = x[SyncUser-(56)]
+	_ = x[UserDisconnect-(57)]
+	_ = x[UpdateTowerTargeting-(58)]
+	_ = x[SummonHero-(59)]
+	_ = x[UseHeroAbility-(60)]
 }
 
-var _TypeValues = []Type{CursorMove, CameraZoom, SummonUnit, UpdateUnit, UpdateTower, TPS, PlaceTower, RemoveTower, SelectTower, SelectedTower, SelectedTowerInvalid, DeselectTower, IncomeTick, WindowResizing, NavigateTo, StartGame, OpenTowerMenu, OpenUnitMenu, CloseTowerMenu, CloseUnitMenu, GoHome, SignUpError, UserSignUp, UserSignUpChangeImage, UserSignIn, UserSignOut, VersionError, SetupGame, FindGame, ExitSearchingGame, AcceptWaitingGame, CancelWaitingGame, ShowScoreboard, AddError, CreateLobby, DeleteLobby, JoinLobby, AddLobbies, SelectLobby, LeaveLobby, UpdateLobby, StartLobby, SeenLobbies, AddPlayer, RemovePlayer, SyncState, SyncLobbies, SyncSearchingRoom, SyncWaitingRoom, SyncWaitingRooms}
+var _TypeValues = []Type{CursorMove, CameraZoom, SummonUnit, UpdateUnit, UpdateTower, TPS, PlaceTower, RemoveTower, SelectTower, SelectedTower, SelectedTowerInvalid, DeselectTower, IncomeTick, WindowResizing, NavigateTo, StartGame, OpenTowerMenu, OpenUnitMenu, CloseTowerMenu, CloseUnitMenu, GoHome, SignUpError, UserSignUp, UserSignUpChangeImage, UserSignIn, UserSignOut, VersionError, SetupGame, FindGame, ExitSearchingGame, AcceptWaitingGame, CancelWaitingGame, ShowScoreboard, AddError, CreateLobby, DeleteLobby, JoinLobby, AddLobbies, SelectLobby, LeaveLobby, UpdateLobby, StartLobby, SeenLobbies, AddGames, AddBalances, GoToLine, AddPlayer, RemovePlayer, SyncState, SyncLobbies, SyncSearchingRoom, SyncWaitingRoom, SyncWaitingRooms, SpectateGame, ExitSpectateGame, AckSyncState, SyncUser, UserDisconnect, UpdateTowerTargeting, SummonHero, UseHeroAbility}
 
 var _TypeNameToValueMap = map[string]Type{
 	_TypeName[0:11]:         CursorMove,
@@ -166,20 +177,42 @@ var _TypeNameToValueMap = map[string]Type{
 	_TypeLowerName[526:537]: StartLobby,
 	_TypeName[537:549]:      SeenLobbies,
 	_TypeLowerName[537:549]: SeenLobbies,
-	_TypeName[549:559]:      AddPlayer,
-	_TypeLowerName[549:559]: AddPlayer,
-	_TypeName[559:572]:      RemovePlayer,
-	_TypeLowerName[559:572]: RemovePlayer,
-	_TypeName[572:582]:      SyncState,
-	_TypeLowerName[572:582]: SyncState,
-	_TypeName[582:594]:      SyncLobbies,
-	_TypeLowerName[582:594]: SyncLobbies,
-	_TypeName[594:613]:      SyncSearchingRoom,
-	_TypeLowerName[594:613]: SyncSearchingRoom,
-	_TypeName[613:630]:      SyncWaitingRoom,
-	_TypeLowerName[613:630]: SyncWaitingRoom,
-	_TypeName[630:648]:      SyncWaitingRooms,
-	_TypeLowerName[630:648]: SyncWaitingRooms,
+	_TypeName[549:558]:      AddGames,
+	_TypeLowerName[549:558]: AddGames,
+	_TypeName[558:570]:      AddBalances,
+	_TypeLowerName[558:570]: AddBalances,
+	_TypeName[570:580]:      GoToLine,
+	_TypeLowerName[570:580]: GoToLine,
+	_TypeName[580:590]:      AddPlayer,
+	_TypeLowerName[580:590]: AddPlayer,
+	_TypeName[590:603]:      RemovePlayer,
+	_TypeLowerName[590:603]: RemovePlayer,
+	_TypeName[603:613]:      SyncState,
+	_TypeLowerName[603:613]: SyncState,
+	_TypeName[613:625]:      SyncLobbies,
+	_TypeLowerName[613:625]: SyncLobbies,
+	_TypeName[625:644]:      SyncSearchingRoom,
+	_TypeLowerName[625:644]: SyncSearchingRoom,
+	_TypeName[644:661]:      SyncWaitingRoom,
+	_TypeLowerName[644:661]: SyncWaitingRoom,
+	_TypeName[661:679]:      SyncWaitingRooms,
+	_TypeLowerName[661:679]: SyncWaitingRooms,
+	_TypeName[679:692]:      SpectateGame,
+	_TypeLowerName[679:692]: SpectateGame,
+	_TypeName[692:710]:      ExitSpectateGame,
+	_TypeLowerName[692:710]: ExitSpectateGame,
+	_TypeName[710:724]:      AckSyncState,
+	_TypeLowerName[710:724]: AckSyncState,
+	_TypeName[724:733]:      SyncUser,
+	_TypeLowerName[724:733]: SyncUser,
+	_TypeName[733:748]:      UserDisconnect,
+	_TypeLowerName[733:748]: UserDisconnect,
+	_TypeName[748:770]:      UpdateTowerTargeting,
+	_TypeLowerName[748:770]: UpdateTowerTargeting,
+	_TypeName[770:781]:      SummonHero,
+	_TypeLowerName[770:781]: SummonHero,
+	_TypeName[781:797]:      UseHeroAbility,
+	_TypeLowerName[781:797]: UseHeroAbility,
 }
 
 var _TypeNames = []string{
@@ -226,13 +259,24 @@ var _TypeNames = []string{
 	_TypeName[514:526],
 	_TypeName[526:537],
 	_TypeName[537:549],
-	_TypeName[549:559],
-	_TypeName[559:572],
-	_TypeName[572:582],
-	_TypeName[582:594],
-	_TypeName[594:613],
-	_TypeName[613:630],
-	_TypeName[630:648],
+	_TypeName[549:558],
+	_TypeName[558:570],
+	_TypeName[570:580],
+	_TypeName[580:590],
+	_TypeName[590:603],
+	_TypeName[603:613],
+	_TypeName[613:625],
+	_TypeName[625:644],
+	_TypeName[644:661],
+	_TypeName[661:679],
+	_TypeName[679:692],
+	_TypeName[692:710],
+	_TypeName[710:724],
+	_TypeName[724:733],
+	_TypeName[733:748],
+	_TypeName[748:770],
+	_TypeName[770:781],
+	_TypeName[781:797],
 }
 
 // TypeString retrieves an enum value from the enum constants string name.
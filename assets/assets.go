@@ -1,7 +1,7 @@
 package assets
 
 import (
-	_ "embed"
+	"embed"
 	_ "image/png"
 )
 
@@ -99,20 +99,11 @@ var TilesetHouse_png []byte
 //go:embed TilesetLogic.png
 var TilesetLogic_png []byte
 
-//go:embed maps/2.png
-var Map_2_png []byte
-
-//go:embed maps/3.png
-var Map_3_png []byte
-
-//go:embed maps/4.png
-var Map_4_png []byte
-
-//go:embed maps/5.png
-var Map_5_png []byte
-
-//go:embed maps/6.png
-var Map_6_png []byte
+// Tilesets are the tilesets used by the
+// maps on the tiled/ directory
+//
+//go:embed ninja_adventure_asset_pack/Backgrounds/Tilesets
+var Tilesets embed.FS
 
 //go:embed YesButton.png
 var YesButton_png []byte
@@ -123,6 +114,9 @@ var NoButton_png []byte
 //go:embed units.json
 var Units_json []byte
 
+//go:embed towers.json
+var Towers_json []byte
+
 //go:embed munro.ttf
 var Munro_ttf []byte
 
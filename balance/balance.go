@@ -0,0 +1,393 @@
+package balance
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/ability"
+	"github.com/xescugc/maze-wars/unit/armor"
+	"github.com/xescugc/maze-wars/unit/buff"
+)
+
+var (
+	ErrInvalidBalance = errors.New("invalid balance")
+
+	// Default is the Balance of the Game without
+	// any pack, it's the one from the assets
+	Default = &Balance{
+		Units:  unit.Units,
+		Towers: tower.Towers,
+		Game: Game{
+			IncomeTimer:       15,
+			Gold:              40,
+			Income:            25,
+			Lives:             20,
+			UpdateCostFactor:  5,
+			UpdateFactor:      0.1,
+			AbilityRankLevels: []int{1, 3, 5},
+			Effectiveness: Effectiveness{
+				damage.Piercing: {armor.Light: 1.5, armor.Medium: 1, armor.Heavy: 0.75, armor.Fortified: 0.5},
+				damage.Siege:    {armor.Light: 0.75, armor.Medium: 1, armor.Heavy: 1, armor.Fortified: 1.5},
+				damage.Magic:    {armor.Light: 1, armor.Medium: 1, armor.Heavy: 1.5, armor.Fortified: 0.5},
+			},
+		},
+	}
+)
+
+// Balance are all the numbers a Game is simulated
+// with. It's the Default or a pack that overrides it
+type Balance struct {
+	// Name is the name of the pack, empty for the Default
+	Name string
+
+	// Units and Towers are the same as unit.Units and
+	// tower.Towers but with the stats of the pack
+	Units  map[string]*unit.Unit
+	Towers map[string]*tower.Tower
+
+	Game
+
+	// raw is the pack it was parsed from
+	// and hash the checksum of it
+	raw  []byte
+	hash string
+}
+
+// Game are the numbers of the Game that are not from Units or Towers
+type Game struct {
+	// IncomeTimer is the seconds between each income
+	IncomeTimer int `json:"income_timer"`
+
+	// Gold, Income and Lives are the ones each Player starts with
+	Gold   int `json:"gold"`
+	Income int `json:"income"`
+	Lives  int `json:"lives"`
+
+	// UpdateCostFactor is the multiplier of the
+	// Unit Gold to know the cost of the update
+	UpdateCostFactor int `json:"update_cost_factor"`
+	// UpdateFactor is the exponent used
+	// to increase the stats on each update
+	UpdateFactor float64 `json:"update_factor"`
+
+	// AbilityRankLevels are the Unit levels in which
+	// each rank of the Abilities is reached
+	AbilityRankLevels []int `json:"ability_rank_levels"`
+
+	// Effectiveness is the multiplier of the
+	// damage of the Towers to the Units
+	Effectiveness Effectiveness `json:"effectiveness"`
+}
+
+// AbilityRank returns the rank of the Abilities of a Unit of level lvl
+func (g Game) AbilityRank(lvl int) int {
+	r := 1
+	for i, l := range g.AbilityRankLevels {
+		if lvl >= l {
+			r = i + 1
+		}
+	}
+	return r
+}
+
+// Effectiveness is the multiplier of the damage each damage.Damage does to
+// each armor.Armor, the ones not defined do the full damage. On a pack each
+// damage.Damage defined replaces all the default ones of that damage.Damage
+type Effectiveness map[damage.Damage]map[armor.Armor]float64
+
+// Factor returns the multiplier of the damage d to the armor a
+func (e Effectiveness) Factor(d damage.Damage, a armor.Armor) float64 {
+	f, ok := e[d][a]
+	if !ok {
+		return 1
+	}
+	return f
+}
+
+// Strong returns the armor.Armor that receive more damage from d
+func (e Effectiveness) Strong(d damage.Damage) []armor.Armor {
+	res := make([]armor.Armor, 0)
+	for _, a := range armor.ArmorValues() {
+		if e.Factor(d, a) > 1 {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// Weak returns the armor.Armor that receive less damage from d
+func (e Effectiveness) Weak(d damage.Damage) []armor.Armor {
+	res := make([]armor.Armor, 0)
+	for _, a := range armor.ArmorValues() {
+		if e.Factor(d, a) < 1 {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// pack is the format of a balance pack, everything is
+// optional and only the defined values are overridden
+type pack struct {
+	Name   string                     `json:"name"`
+	Game   json.RawMessage            `json:"game"`
+	Units  map[string]json.RawMessage `json:"units"`
+	Towers map[string]json.RawMessage `json:"towers"`
+}
+
+// Hash returns the checksum of the pack, it's
+// empty for the Default as it has no pack
+func (b *Balance) Hash() string { return b.hash }
+
+// Raw returns the pack the Balance was parsed
+// from, it's nil for the Default
+func (b *Balance) Raw() []byte { return b.raw }
+
+// IsDefault returns if b is not from a pack
+func (b *Balance) IsDefault() bool { return b.hash == "" }
+
+// Unit returns the unit.Unit of type ut with the stats of the Balance
+func (b *Balance) Unit(ut string) *unit.Unit { return b.Units[ut] }
+
+// Tower returns the tower.Tower of type tt with the stats of the Balance
+func (b *Balance) Tower(tt string) *tower.Tower { return b.Towers[tt] }
+
+// Parse parses the pack from the JSON raw overriding the Default
+func Parse(raw []byte) (*Balance, error) {
+	var p pack
+	err := decode(raw, &p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode balance: %w", err)
+	}
+
+	sum := sha256.Sum256(raw)
+	b := &Balance{
+		Name:   p.Name,
+		Units:  make(map[string]*unit.Unit, len(Default.Units)),
+		Towers: make(map[string]*tower.Tower, len(Default.Towers)),
+		Game:   Default.Game,
+		raw:    raw,
+		hash:   hex.EncodeToString(sum[:]),
+	}
+
+	// The JSON decoding reuses the map and the slice
+	// so they can not be shared with the Default
+	b.Effectiveness = make(Effectiveness, len(Default.Effectiveness))
+	for d, ea := range Default.Effectiveness {
+		b.Effectiveness[d] = ea
+	}
+	b.AbilityRankLevels = append([]int(nil), Default.AbilityRankLevels...)
+	if len(p.Game) != 0 {
+		err = decode(p.Game, &b.Game)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode balance game: %w", err)
+		}
+	}
+	switch {
+	case b.IncomeTimer <= 0:
+		return nil, fmt.Errorf("%w: income_timer has to be positive", ErrInvalidBalance)
+	case b.Lives <= 0:
+		return nil, fmt.Errorf("%w: lives has to be positive", ErrInvalidBalance)
+	case b.Gold < 0 || b.Income < 0:
+		return nil, fmt.Errorf("%w: gold and income can not be negative", ErrInvalidBalance)
+	case b.UpdateCostFactor <= 0:
+		return nil, fmt.Errorf("%w: update_cost_factor has to be positive", ErrInvalidBalance)
+	case b.UpdateFactor < 0:
+		return nil, fmt.Errorf("%w: update_factor can not be negative", ErrInvalidBalance)
+	case len(b.AbilityRankLevels) == 0 || b.AbilityRankLevels[0] != 1:
+		return nil, fmt.Errorf("%w: ability_rank_levels has to start at level 1", ErrInvalidBalance)
+	}
+	for i := 1; i < len(b.AbilityRankLevels); i++ {
+		if b.AbilityRankLevels[i] <= b.AbilityRankLevels[i-1] {
+			return nil, fmt.Errorf("%w: ability_rank_levels has to be increasing", ErrInvalidBalance)
+		}
+	}
+	for d, ea := range b.Effectiveness {
+		for a, f := range ea {
+			if f < 0 {
+				return nil, fmt.Errorf("%w: effectiveness of %q to %q can not be negative", ErrInvalidBalance, d, a)
+			}
+		}
+	}
+
+	for ut, u := range Default.Units {
+		cu := *u
+		// The JSON decoding reuses the slice so
+		// it can not be shared with the Default
+		cu.Abilities = append([]ability.Ability(nil), u.Abilities...)
+		cu.AbilityParams = maps.Clone(u.AbilityParams)
+		b.Units[ut] = &cu
+	}
+	for ut, ru := range p.Units {
+		u, ok := b.Units[ut]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown unit %q", ErrInvalidBalance, ut)
+		}
+		err = overrideUnit(u, ru)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unit %q: %w", ErrInvalidBalance, ut, err)
+		}
+	}
+
+	for tt, t := range Default.Towers {
+		ct := *t
+		// The Effect is decoded on the pointer so
+		// it can not be shared with the Default
+		if t.Effect != nil {
+			e := *t.Effect
+			ct.Effect = &e
+		}
+		b.Towers[tt] = &ct
+	}
+	for tt, rt := range p.Towers {
+		t, ok := b.Towers[tt]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown tower %q", ErrInvalidBalance, tt)
+		}
+		err = overrideTower(t, rt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tower %q: %w", ErrInvalidBalance, tt, err)
+		}
+	}
+	err = tower.Validate(b.Towers)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBalance, err)
+	}
+
+	return b, nil
+}
+
+// overrideUnit sets the stats and abilities defined on raw to u
+func overrideUnit(u *unit.Unit, raw json.RawMessage) error {
+	v := struct {
+		*unit.Stats
+		Abilities     *[]ability.Ability `json:"abilities"`
+		AbilityParams *ability.Params    `json:"ability_params"`
+		ArmorType     *armor.Armor       `json:"armor_type"`
+	}{
+		Stats:         &u.Stats,
+		Abilities:     &u.Abilities,
+		AbilityParams: &u.AbilityParams,
+		ArmorType:     &u.ArmorType,
+	}
+	err := decode(raw, &v)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case u.Health <= 0:
+		return errors.New("health has to be positive")
+	case u.Gold <= 0:
+		return errors.New("gold has to be positive")
+	case u.MovementSpeed <= 0:
+		return errors.New("movement_speed has to be positive")
+	case u.Damage < 0 || u.AttackSpeed < 0 || u.Shield < 0:
+		return errors.New("damage, attack_speed and shield can not be negative")
+	case len(u.Abilities) == 0:
+		return errors.New("abilities are required")
+	}
+	for _, a := range u.Abilities {
+		if !a.IsAAbility() {
+			return fmt.Errorf("unknown ability %q", a)
+		}
+	}
+	err = u.AbilityParams.Validate(u.Abilities)
+	if err != nil {
+		return err
+	}
+
+	// The Income is always calculated from the
+	// Gold as it's done for the default Units
+	u.Income = u.Gold / 5
+
+	return nil
+}
+
+// overrideTower sets the stats defined on raw to t, the rest of
+// fields (like the Updates) are not stats so they can not be changed
+func overrideTower(t *tower.Tower, raw json.RawMessage) error {
+	v := struct {
+		Damage      *float64       `json:"damage"`
+		Gold        *int           `json:"gold"`
+		Health      *float64       `json:"health"`
+		Range       *float64       `json:"range"`
+		AttackSpeed *float64       `json:"attack_speed"`
+		AoE         *int           `json:"aoe"`
+		AoEDamage   *float64       `json:"aoe_damage"`
+		Effect      **buff.Effect  `json:"effect"`
+		DamageType  *damage.Damage `json:"damage_type"`
+	}{
+		Damage:      &t.Damage,
+		Gold:        &t.Gold,
+		Health:      &t.Health,
+		Range:       &t.Range,
+		AttackSpeed: &t.AttackSpeed,
+		AoE:         &t.AoE,
+		AoEDamage:   &t.AoEDamage,
+		Effect:      &t.Effect,
+		DamageType:  &t.DamageType,
+	}
+	return decode(raw, &v)
+}
+
+// decode decodes the JSON b into v failing on unknown fields
+func decode(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Load loads the packs from p, which can be a file with
+// one pack or a directory with one pack on each .json file.
+// The packs without name are named like the file
+func Load(p string) ([]*Balance, error) {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balance: %w", err)
+	}
+
+	files := []string{p}
+	if fi.IsDir() {
+		files, err = filepath.Glob(filepath.Join(p, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list balances: %w", err)
+		}
+		sort.Strings(files)
+	}
+
+	res := make([]*Balance, 0, len(files))
+	names := make(map[string]string)
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read balance %q: %w", f, err)
+		}
+		b, err := Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse balance %q: %w", f, err)
+		}
+		if b.Name == "" {
+			b.Name = strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		}
+		if of, ok := names[b.Name]; ok {
+			return nil, fmt.Errorf("%w: name %q of %q already used by %q", ErrInvalidBalance, b.Name, f, of)
+		}
+		names[b.Name] = f
+		res = append(res, b)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,180 @@
+package balance_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/ability"
+	"github.com/xescugc/maze-wars/unit/armor"
+	"github.com/xescugc/maze-wars/unit/buff"
+)
+
+func TestDefault(t *testing.T) {
+	assert.True(t, balance.Default.IsDefault())
+	assert.Empty(t, balance.Default.Hash())
+	assert.Equal(t, unit.Units["ninja"], balance.Default.Unit("ninja"))
+	assert.Equal(t, tower.Towers["range1"], balance.Default.Tower("range1"))
+	assert.Equal(t, []armor.Armor{armor.Light}, balance.Default.Effectiveness.Strong(damage.Piercing))
+	assert.Equal(t, []armor.Armor{armor.Heavy, armor.Fortified}, balance.Default.Effectiveness.Weak(damage.Piercing))
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		raw := []byte(`{
+			"name": "fast",
+			"game": {"income_timer": 10, "gold": 100, "ability_rank_levels": [1, 2], "effectiveness": {"magic": {"light": 2}}},
+			"units": {"ninja": {"health": 30, "abilities": ["attack"], "armor_type": "heavy"}, "mole": {"ability_params": {"burrow": [{"duration": 5}, {}, {}, {"duration": 1}]}}},
+			"towers": {"range1": {"damage": 5, "gold": 10, "effect": {"buff": "slowed", "value": 0.5, "duration": 1}, "damage_type": "magic"}}
+		}`)
+		b, err := balance.Parse(raw)
+		require.NoError(t, err)
+
+		assert.Equal(t, "fast", b.Name)
+		assert.False(t, b.IsDefault())
+		assert.Len(t, b.Hash(), 64)
+		assert.Equal(t, raw, b.Raw())
+
+		assert.Equal(t, 10, b.IncomeTimer)
+		assert.Equal(t, 100, b.Gold)
+		assert.Equal(t, balance.Default.Lives, b.Lives)
+		assert.Equal(t, 1, b.AbilityRank(1))
+		assert.Equal(t, 2, b.AbilityRank(2))
+		assert.Equal(t, 2, b.AbilityRank(10))
+		assert.Equal(t, float64(2), b.Effectiveness.Factor(damage.Magic, armor.Light))
+		assert.Equal(t, float64(1), b.Effectiveness.Factor(damage.Magic, armor.Heavy))
+		assert.Equal(t, balance.Default.Effectiveness[damage.Siege], b.Effectiveness[damage.Siege])
+
+		assert.Equal(t, float64(30), b.Unit("ninja").Health)
+		assert.Equal(t, balance.Default.Unit("ninja").Gold, b.Unit("ninja").Gold)
+		assert.True(t, b.Unit("ninja").HasAbility(ability.Attack))
+		assert.False(t, b.Unit("ninja").HasAbility(ability.Efficiency))
+		assert.Equal(t, armor.Heavy, b.Unit("ninja").ArmorType)
+		assert.Equal(t, balance.Default.Unit("statue"), b.Unit("statue"))
+		assert.Equal(t, 4, ability.Ranks(b.Unit("mole").AbilityParams, ability.Burrow))
+		assert.Equal(t, ability.BurrowParams{Health: 0.5, Duration: 5, MaxDuration: 45}, ability.ParamsOf[ability.BurrowParams](b.Unit("mole").AbilityParams, ability.Burrow, 1))
+		assert.Equal(t, ability.BurrowParams{Health: 0.5, Duration: 10, MaxDuration: 35}, ability.ParamsOf[ability.BurrowParams](b.Unit("mole").AbilityParams, ability.Burrow, 2))
+		// The new ranks start from the previous one
+		assert.Equal(t, ability.BurrowParams{Health: 0.5, Duration: 1, MaxDuration: 25}, ability.ParamsOf[ability.BurrowParams](b.Unit("mole").AbilityParams, ability.Burrow, 4))
+
+		assert.Equal(t, float64(5), b.Tower("range1").Damage)
+		assert.Equal(t, 10, b.Tower("range1").Gold)
+		assert.Equal(t, balance.Default.Tower("range1").Updates, b.Tower("range1").Updates)
+		assert.Equal(t, &buff.Effect{Buff: buff.Slowed, Value: 0.5, Duration: 1}, b.Tower("range1").Effect)
+		assert.Equal(t, damage.Magic, b.Tower("range1").DamageType)
+
+		// The Default is not changed
+		assert.Equal(t, float64(15), balance.Default.Unit("ninja").Health)
+		assert.True(t, balance.Default.Unit("ninja").HasAbility(ability.Efficiency))
+		assert.Equal(t, 7, balance.Default.Tower("range1").Gold)
+		assert.Nil(t, balance.Default.Tower("range1").Effect)
+		assert.Equal(t, damage.Piercing, balance.Default.Tower("range1").DamageType)
+		assert.Equal(t, armor.Light, balance.Default.Unit("ninja").ArmorType)
+		assert.Equal(t, float64(1), balance.Default.Effectiveness.Factor(damage.Magic, armor.Light))
+		assert.Equal(t, float64(15), ability.ParamsOf[ability.BurrowParams](balance.Default.Unit("mole").AbilityParams, ability.Burrow, 1).Duration)
+		assert.Equal(t, 3, ability.Ranks(balance.Default.Unit("mole").AbilityParams, ability.Burrow))
+		assert.Equal(t, []int{1, 3, 5}, balance.Default.AbilityRankLevels)
+	})
+	t.Run("NewAbilityParams", func(t *testing.T) {
+		b, err := balance.Parse([]byte(`{"units": {"ninja": {"abilities": ["burrow"], "ability_params": {"burrow": [{"health": 0.5, "max_duration": 2}]}}}}`))
+		require.NoError(t, err)
+
+		// The fields not set are the zero value
+		assert.Equal(t, ability.BurrowParams{Health: 0.5, MaxDuration: 2}, ability.ParamsOf[ability.BurrowParams](b.Unit("ninja").AbilityParams, ability.Burrow, 1))
+	})
+	t.Run("TowerEffect", func(t *testing.T) {
+		de := *balance.Default.Tower("rangefrost").Effect
+		b, err := balance.Parse([]byte(`{"towers": {"rangefrost": {"effect": {"value": 0.9, "duration": 9}}}}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, &buff.Effect{Buff: de.Buff, Value: 0.9, Duration: 9}, b.Tower("rangefrost").Effect)
+		// The Default is not changed
+		assert.Equal(t, &de, balance.Default.Tower("rangefrost").Effect)
+		assert.Equal(t, tower.Towers["rangefrost"].Effect, balance.Default.Tower("rangefrost").Effect)
+	})
+	t.Run("SameHash", func(t *testing.T) {
+		raw := []byte(`{"game": {"lives": 10}}`)
+		b1, err := balance.Parse(raw)
+		require.NoError(t, err)
+		b2, err := balance.Parse(raw)
+		require.NoError(t, err)
+		assert.Equal(t, b1.Hash(), b2.Hash())
+
+		b3, err := balance.Parse([]byte(`{"game": {"lives": 11}}`))
+		require.NoError(t, err)
+		assert.NotEqual(t, b1.Hash(), b3.Hash())
+	})
+
+	tcs := map[string]string{
+		"InvalidJSON":          `{`,
+		"UnknownField":         `{"unknown": 1}`,
+		"UnknownGameField":     `{"game": {"unknown": 1}}`,
+		"InvalidIncomeTimer":   `{"game": {"income_timer": 0}}`,
+		"InvalidLives":         `{"game": {"lives": -1}}`,
+		"InvalidEffectiveness": `{"game": {"effectiveness": {"magic": {"light": -1}}}}`,
+		"UnknownDamageType":    `{"game": {"effectiveness": {"fire": {"light": 1}}}}`,
+		"UnknownArmorType":     `{"units": {"ninja": {"armor_type": "plate"}}}`,
+		"UnknownUnit":          `{"units": {"dragon": {"health": 1}}}`,
+		"UnknownUnitField":     `{"units": {"ninja": {"environment": "aerial"}}}`,
+		"InvalidUnitHealth":    `{"units": {"ninja": {"health": 0}}}`,
+		"UnknownAbility":       `{"units": {"ninja": {"abilities": ["teleport"]}}}`,
+		"MissingAbilities":     `{"units": {"ninja": {"abilities": []}}}`,
+		"InvalidAbilityParams": `{"units": {"mole": {"ability_params": {"burrow": [{"health": 2}]}}}}`,
+		"UnknownAbilityParam":  `{"units": {"mole": {"ability_params": {"burrow": [{"depth": 2}]}}}}`,
+		"AbilityWithoutParams": `{"units": {"ninja": {"ability_params": {"tank": [{}]}}}}`,
+		"AbilityWithoutRanks":  `{"units": {"mole": {"ability_params": {"burrow": []}}}}`,
+		"MissingAbilityParams": `{"units": {"ninja": {"abilities": ["burrow"]}}}`,
+		"InvalidRankLevels":    `{"game": {"ability_rank_levels": [2, 4]}}`,
+		"UnsortedRankLevels":   `{"game": {"ability_rank_levels": [1, 4, 3]}}`,
+		"UnknownTower":         `{"towers": {"range9": {"damage": 1}}}`,
+		"UnknownTowerField":    `{"towers": {"range1": {"updates": []}}}`,
+		"InvalidTowerGold":     `{"towers": {"range1": {"gold": 0}}}`,
+		"InvalidTowerEffect":   `{"towers": {"range1": {"effect": {"buff": "burrowoed", "duration": 1}}}}`,
+	}
+	for n, raw := range tcs {
+		t.Run(n, func(t *testing.T) {
+			_, err := balance.Parse([]byte(raw))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fast.json"), []byte(`{"game": {"income_timer": 10}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rich.json"), []byte(`{"name": "Rich", "game": {"gold": 100}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`not a pack`), 0644))
+
+	t.Run("Directory", func(t *testing.T) {
+		bls, err := balance.Load(dir)
+		require.NoError(t, err)
+		require.Len(t, bls, 2)
+		assert.Equal(t, "fast", bls[0].Name)
+		assert.Equal(t, 10, bls[0].IncomeTimer)
+		assert.Equal(t, "Rich", bls[1].Name)
+		assert.Equal(t, 100, bls[1].Gold)
+	})
+	t.Run("File", func(t *testing.T) {
+		bls, err := balance.Load(filepath.Join(dir, "fast.json"))
+		require.NoError(t, err)
+		require.Len(t, bls, 1)
+		assert.Equal(t, "fast", bls[0].Name)
+	})
+	t.Run("DuplicatedName", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"name": "fast"}`), 0644))
+		defer os.Remove(filepath.Join(dir, "other.json"))
+
+		_, err := balance.Load(dir)
+		assert.ErrorIs(t, err, balance.ErrInvalidBalance)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := balance.Load(filepath.Join(dir, "none.json"))
+		assert.Error(t, err)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -13,10 +15,11 @@ import (
 
 	"github.com/adrg/xdg"
 	"github.com/coder/websocket"
-	"github.com/coder/websocket/wsjson"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
 	cutils "github.com/xescugc/maze-wars/client/utils"
+	"github.com/xescugc/maze-wars/codec"
 	"github.com/xescugc/maze-wars/server/models"
 	"github.com/xescugc/maze-wars/store"
 	"github.com/xescugc/maze-wars/utils"
@@ -65,10 +68,19 @@ func (ac *ActionDispatcher) NavigateTo(route string) {
 	if route == utils.LobbiesRoute {
 		ac.RefreshLobbies()
 	}
+	// The new lobby needs the balance packs
+	// that can be picked on the server
+	if route == utils.NewLobbyRoute {
+		ac.RefreshBalances()
+	}
 	ac.Dispatch(nt)
 }
 
 func (ac *ActionDispatcher) RefreshLobbies() {
+	// The Games are listed with the Lobbies so we refresh them
+	// first as the table is only rebuilt when the Lobbies change
+	ac.RefreshGames()
+
 	httpu, _ := url.Parse(ac.opt.HostURL)
 	httpu.Path = "/lobbies"
 	resp, err := http.Get(httpu.String())
@@ -94,6 +106,93 @@ func (ac *ActionDispatcher) RefreshLobbies() {
 	ac.Dispatch(action.NewAddLobbies(&action.AddLobbiesPayload{Lobbies: lbs}))
 }
 
+// loadBalance sets the balance pack with the hash h to the Store, the
+// one of the Game, so it's simulated with the same numbers as the server
+func (ac *ActionDispatcher) loadBalance(h string) error {
+	if ac.store.Balance().Hash() == h {
+		return nil
+	}
+	if h == "" {
+		ac.store.SetBalance(nil)
+		return nil
+	}
+
+	httpu, _ := url.Parse(ac.opt.HostURL)
+	httpu.Path = "/balances/" + h
+	resp, err := http.Get(httpu.String())
+	if err != nil {
+		return fmt.Errorf("failed to get balance %q: %w", h, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get balance %q: status %d", h, resp.StatusCode)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read balance %q: %w", h, err)
+	}
+	b, err := balance.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if b.Hash() != h {
+		return fmt.Errorf("invalid balance %q: got hash %q", h, b.Hash())
+	}
+	ac.store.SetBalance(b)
+
+	return nil
+}
+
+// RefreshGames loads the current Games that can be spectated
+func (ac *ActionDispatcher) RefreshGames() {
+	httpu, _ := url.Parse(ac.opt.HostURL)
+	httpu.Path = "/games"
+	resp, err := http.Get(httpu.String())
+	if err != nil {
+		ac.logger.Error(err.Error())
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	mgms := &models.GamesResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&mgms)
+	if err != nil {
+		ac.logger.Error(err.Error())
+		return
+	}
+
+	gms := make([]*action.GamePayload, 0, len(mgms.Games))
+	for _, mg := range mgms.Games {
+		agp := action.GamePayload(mg)
+		gms = append(gms, &agp)
+	}
+	ac.Dispatch(action.NewAddGames(&action.AddGamesPayload{Games: gms}))
+}
+
+// RefreshBalances gets the names of the balance packs of the server
+func (ac *ActionDispatcher) RefreshBalances() {
+	httpu, _ := url.Parse(ac.opt.HostURL)
+	httpu.Path = "/balances"
+	resp, err := http.Get(httpu.String())
+	if err != nil {
+		ac.logger.Error(err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	mbs := &models.BalancesResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&mbs)
+	if err != nil {
+		ac.logger.Error(err.Error())
+		return
+	}
+
+	ac.Dispatch(action.NewAddBalances(mbs.Balances))
+}
+
 func (ac *ActionDispatcher) CheckVersion() {
 	httpu, _ := url.Parse(ac.opt.HostURL)
 	httpu.Path = "/version"
@@ -122,53 +221,37 @@ func (ac *ActionDispatcher) UserSignUpChangeImage(ik string) {
 	ac.Dispatch(usci)
 }
 
-func (ac *ActionDispatcher) SignUpSubmit(un, ik string) {
-	httpu, _ := url.Parse(ac.opt.HostURL)
-	httpu.Path = "/users"
-	resp, err := http.Post(httpu.String(), "application/json", bytes.NewBuffer([]byte(fmt.Sprintf(`{"username":"%s","image_key":"%s"}`, un, ik))))
+// SignUpSubmit signs in the user un, if the tk is present it'll be used
+// to sign in and if not the user is created or signed in with the pw
+func (ac *ActionDispatcher) SignUpSubmit(un, pw, ik, tk string) {
+	sr, err := ac.signIn(un, pw, ik, tk)
 	if err != nil {
 		ac.Dispatch(action.NewSignUpError(err.Error()))
 		return
 	}
-	body := struct {
-		Error string `json:"error"`
-	}{}
-	if resp.StatusCode != http.StatusCreated {
-		err = json.NewDecoder(resp.Body).Decode(&body)
-		if err != nil {
-			ac.Dispatch(action.NewSignUpError(err.Error()))
-			return
-		}
-		ac.Dispatch(action.NewSignUpError(body.Error))
-		return
-	}
 
 	ac.Dispatch(action.NewSignUpError(""))
 
-	ctx := context.Background()
-
-	// We manually clone it to then change it for the WS
-	chttpu := *httpu
-	wsu := &chttpu
-	wsu.Scheme = "ws"
-	if httpu.Scheme == "https" {
-		wsu.Scheme = "wss"
-	}
-	wsu.Path = "/ws"
-
-	wsc, _, err = websocket.Dial(ctx, wsu.String(), nil)
+	// If the codec is unknown we fallback to the default one
+	wsCodec, err = codec.New(ac.opt.Codec)
 	if err != nil {
-		panic(fmt.Errorf("failed to dial the server %q: %w", wsu.String(), err))
+		wsCodec, _ = codec.New(codec.JSON)
 	}
 
-	wsc.SetReadLimit(-1)
+	usia := action.NewUserSignIn(sr.Username)
+	usia.UserSignIn.ImageKey = sr.ImageKey
+	usia.UserSignIn.Codec = wsCodec.Name()
+	usia.UserSignIn.Token = sr.Token
+
+	ctx := context.Background()
 
-	usia := action.NewUserSignIn(un)
-	usia.UserSignIn.ImageKey = ik
-	err = wsjson.Write(ctx, wsc, usia)
+	c, err := ac.dialWS(ctx, usia)
 	if err != nil {
-		panic(fmt.Errorf("failed to write JSON: %w", err))
+		ac.Dispatch(action.NewSignUpError(err.Error()))
+		return
 	}
+	setWSConn(c)
+	signInAction = usia
 
 	ac.Dispatch(usia)
 
@@ -181,12 +264,14 @@ func (ac *ActionDispatcher) SignUpSubmit(un, ik string) {
 		ac.logger.Error(fmt.Errorf("Failed to ConfigFile: %w", err).Error())
 	}
 	cfg := cutils.Config{
-		Username: un,
-		ImageKey: ik,
+		Username: sr.Username,
+		ImageKey: sr.ImageKey,
+		Token:    sr.Token,
 	}
 	b, err := json.Marshal(cfg)
 	if err != nil {
-		panic(fmt.Errorf("Failed to Marshal Config: %w", err))
+		ac.logger.Error(fmt.Errorf("Failed to Marshal Config: %w", err).Error())
+		return
 	}
 	err = os.WriteFile(configFilePath, b, 0666)
 	if err != nil {
@@ -195,14 +280,96 @@ func (ac *ActionDispatcher) SignUpSubmit(un, ik string) {
 
 }
 
+// dialWS opens a new WS connection to the server
+// and sends the UserSignIn usia through it
+func (ac *ActionDispatcher) dialWS(ctx context.Context, usia *action.Action) (*websocket.Conn, error) {
+	httpu, _ := url.Parse(ac.opt.HostURL)
+	// We manually clone it to then change it for the WS
+	chttpu := *httpu
+	wsu := &chttpu
+	wsu.Scheme = "ws"
+	if httpu.Scheme == "https" {
+		wsu.Scheme = "wss"
+	}
+	wsu.Path = "/ws"
+
+	c, _, err := websocket.Dial(ctx, wsu.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the server %q: %w", wsu.String(), err)
+	}
+
+	c.SetReadLimit(-1)
+
+	err = codec.Write(ctx, c, wsCodec, usia)
+	if err != nil {
+		c.CloseNow()
+		return nil, fmt.Errorf("failed to write the sign in: %w", err)
+	}
+
+	return c, nil
+}
+
+// signIn returns the Session of the user un. If the tk is valid it's used, if not the
+// user is created and if it already exists it's signed in with the pw
+func (ac *ActionDispatcher) signIn(un, pw, ik, tk string) (*models.SessionResponse, error) {
+	if tk != "" {
+		sr, _, err := ac.postSession("/sessions", map[string]string{"token": tk, "image_key": ik})
+		if err == nil {
+			return sr, nil
+		}
+		ac.logger.Info("the token is no longer valid", "error", err.Error())
+	}
+
+	cred := map[string]string{"username": un, "password": pw, "image_key": ik}
+	sr, sc, err := ac.postSession("/users", cred)
+	if sc == http.StatusConflict {
+		sr, _, err = ac.postSession("/sessions", cred)
+	}
+	return sr, err
+}
+
+// postSession sends the body to the path and returns the
+// Session from the response and the status code
+func (ac *ActionDispatcher) postSession(p string, body interface{}) (*models.SessionResponse, int, error) {
+	httpu, _ := url.Parse(ac.opt.HostURL)
+	httpu.Path = p
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := http.Post(httpu.String(), "application/json", bytes.NewBuffer(b))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body := struct {
+			Error string `json:"error"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		if err != nil {
+			return nil, resp.StatusCode, err
+		}
+		return nil, resp.StatusCode, errors.New(body.Error)
+	}
+
+	var sr models.SessionResponse
+	err = json.NewDecoder(resp.Body).Decode(&sr)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return &sr, resp.StatusCode, nil
+}
+
 // GoHome will move the camera to the current player home line
 func (ac *ActionDispatcher) GoHome() {
 	gha := action.NewGoHome()
 	ac.Dispatch(gha)
 }
 
-func (ac *ActionDispatcher) CreateLobby(lid, o, ln string, lmp int) {
-	cla := action.NewCreateLobby(lid, o, ln, lmp)
+func (ac *ActionDispatcher) CreateLobby(lid, o, ln string, lmp int, lb string, gr action.GameRules) {
+	cla := action.NewCreateLobby(lid, o, ln, lmp, lb, gr)
 	wsSend(cla)
 	ac.Dispatch(cla)
 }
@@ -235,6 +402,21 @@ func (ac *ActionDispatcher) StartLobby(lid string) {
 	wsSend(sla)
 }
 
+// AckSyncState acknowledges the last SyncState applied
+func (ac *ActionDispatcher) AckSyncState() {
+	sid := ac.store.Game.GetSnapshotID()
+	if sid == 0 {
+		return
+	}
+	wsSend(action.NewAckSyncState(sid))
+}
+
+// SpectateGame joins the Game rid as a spectator
+func (ac *ActionDispatcher) SpectateGame(un, rid string) {
+	sga := action.NewSpectateGame(un, rid)
+	wsSend(sga)
+}
+
 func (ac *ActionDispatcher) SetupGame(d bool) {
 	sga := action.NewSetupGame(d)
 	ac.Dispatch(sga)
@@ -7,6 +7,8 @@ import (
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
 	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/tower/targeting"
+	"github.com/xescugc/maze-wars/unit/hero"
 	"github.com/xescugc/maze-wars/utils"
 )
 
@@ -77,7 +79,8 @@ func (ac *ActionDispatcher) CameraZoom(d int) {
 func (ac *ActionDispatcher) PlaceTower(t, pid string, x, y int) {
 	// TODO: Add the LineID in the action
 	p := ac.store.Game.FindPlayerByID(pid)
-	if l := ac.store.Game.FindLineByID(p.LineID); l != nil && l.Graph.CanAddTower(x, y, 32, 32) {
+	w, h := ac.store.Balance().Tower(t).Size()
+	if l := ac.store.Game.FindLineByID(p.LineID); l != nil && l.Graph.CanAddTower(x, y, w, h) {
 		pta := action.NewPlaceTower(t, pid, x, y)
 		ac.wsSend(pta)
 	}
@@ -160,6 +163,20 @@ func (ac *ActionDispatcher) GoHome() {
 	ac.Dispatch(gha)
 }
 
+// GoToLine will move the camera to the line lid
+func (ac *ActionDispatcher) GoToLine(lid int) {
+	gtla := action.NewGoToLine(lid)
+	ac.Dispatch(gtla)
+}
+
+// ExitSpectateGame stops spectating the current Game
+func (ac *ActionDispatcher) ExitSpectateGame() {
+	esga := action.NewExitSpectateGame()
+	ac.wsSend(esga)
+	ac.Dispatch(esga)
+	ac.Dispatch(action.NewNavigateTo(utils.RootRoute))
+}
+
 // GoHome will move the camera to the current player home line
 func (ac *ActionDispatcher) UpdateTower(pid, tid, tt string) {
 	uta := action.NewUpdateTower(pid, tid, tt)
@@ -167,6 +184,27 @@ func (ac *ActionDispatcher) UpdateTower(pid, tid, tt string) {
 	ac.Dispatch(uta)
 }
 
+// SummonHero summons the hero of the player id 'pid' to the line
+// 'plid' and with the current line id 'clid'
+func (ac *ActionDispatcher) SummonHero(pid string, plid, clid int) {
+	sha := action.NewSummonHero(pid, plid, clid)
+	ac.wsSend(sha)
+}
+
+// UseHeroAbility uses the ability a of the hero of the player pid
+func (ac *ActionDispatcher) UseHeroAbility(pid string, a hero.Ability) {
+	uhaa := action.NewUseHeroAbility(pid, a)
+	ac.wsSend(uhaa)
+	ac.Dispatch(uhaa)
+}
+
+// UpdateTowerTargeting changes the targeting of the tower tid to tg
+func (ac *ActionDispatcher) UpdateTowerTargeting(pid, tid string, tg targeting.Targeting) {
+	utta := action.NewUpdateTowerTargeting(pid, tid, tg)
+	ac.wsSend(utta)
+	ac.Dispatch(utta)
+}
+
 // GoHome will move the camera to the current player home line
 func (ac *ActionDispatcher) ShowScoreboard(d bool) {
 	ssa := action.NewShowScoreboard(d)
@@ -136,6 +136,10 @@ func (cs *CameraStore) Reduce(state CameraState, act *action.Action) CameraState
 		x, y := cs.Store.Map.GetHomeCoordinates(cp.LineID)
 		x -= (state.W / 2) - ((18 * 16) / 2)
 		state.X, state.Y = float64(x), float64(y)
+	case action.GoToLine:
+		x, y := cs.Store.Map.GetHomeCoordinates(act.GoToLine.LineID)
+		x -= (state.W / 2) - ((18 * 16) / 2)
+		state.X, state.Y = float64(x), float64(y)
 	}
 
 	return state
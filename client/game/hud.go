@@ -1,11 +1,14 @@
 package game
 
 import (
+	"errors"
 	"fmt"
 	stdimage "image"
 	"image/color"
+	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ebitenui/ebitenui"
@@ -14,29 +17,46 @@ import (
 	"github.com/ebitenui/ebitenui/widget"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
 	cutils "github.com/xescugc/maze-wars/client/utils"
 	"github.com/xescugc/maze-wars/store"
 	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/tower/targeting"
 	"github.com/xescugc/maze-wars/unit"
 	"github.com/xescugc/maze-wars/unit/ability"
+	"github.com/xescugc/maze-wars/unit/armor"
+	"github.com/xescugc/maze-wars/unit/hero"
 	"github.com/xescugc/maze-wars/utils"
+	"github.com/xescugc/maze-wars/utils/graph"
 )
 
 const (
-	unitToolTipTmpl       = "Lvl: %d\nGold: %d\nHP: %.0f\nSpeed: %.f\nIncome: %d\nEnv: %s\nKeybind: %s"
+	unitToolTipTmpl       = "Lvl: %d\nGold: %d\nHP: %.0f\nSpeed: %.f\nIncome: %d\nEnv: %s\nArmor: %s\nKeybind: %s"
 	unitUpdateToolTipTmpl = "Lvl: %d\nCost: %d\nGold: %d\nHP: %.0f\nIncome: %d"
 
-	unitAttackToolTipTmpl       = "Lvl: %d\nGold: %d\nHP: %.0f\nDamage: %.0f\nAttack Speed: %.0f\nSpeed: %.f\nIncome: %d\nEnv: %s\nKeybind: %s"
+	unitAttackToolTipTmpl       = "Lvl: %d\nGold: %d\nHP: %.0f\nDamage: %.0f\nAttack Speed: %.0f\nSpeed: %.f\nIncome: %d\nEnv: %s\nArmor: %s\nKeybind: %s"
 	unitAttackUpdateToolTipTmpl = "Lvl: %d\nCost: %d\nGold: %d\nHP: %.0f\nDamage: %.0f\nIncome: %d"
 
 	towerRemoveToolTipTmpl = "Selling the tower will give back %d gold"
-	towerUpdateToolTipTmpl = "Cost: %d\nDamage: %.0f\nAttack Speed: %.0f\nHealth: %.0f\nKeybind: %s"
+	towerUpdateToolTipTmpl = "Cost: %d\nDamage: %.0f (%s)\nAttack Speed: %.0f\nHealth: %.0f\nKeybind: %s"
 	towerUpdateLimit       = "Tower is at it's max level"
 
 	unitToolTipTitleTmpl = "%s (%s)"
 
+	// The matchups are the armors and damages that
+	// receive and do more or less damage to each other
+	damageMatchupTmpl = "%s damage\nStrong vs: %s\nWeak vs: %s"
+	armorMatchupTmpl  = "Weak to: %s\nResists: %s"
+
+	abilityRankTmpl = "%s (rank %d/%d)"
+
+	heroToolTipTmpl = "Summons a stronger %s that can only be summoned once\nCost: %d\nLvl: %d (XP %d)\nGains XP when your towers kill units\nand your units steal lives"
+
 	isPressed = true
 )
 
@@ -66,11 +86,23 @@ type unitTooltips struct {
 	movementSpeed      *widget.Text
 	nmovementSpeed     *widget.Text
 	nmovementSpeedDiff *widget.Text
+	armor              *widget.Text
+	armorMatchup       *widget.Text
+	abilities          []*widget.Text
 }
 
 type towerTooltips struct {
 }
 
+// previewKey identifies a Tower preview so it's only
+// calculated again when any of the fields changes
+type previewKey struct {
+	graph         *graph.Graph
+	towersVersion int
+	x, y          int
+	tower         string
+}
+
 // HUDStore is in charge of keeping track of all the elements
 // on the player HUD that are static and always seen
 type HUDStore struct {
@@ -78,6 +110,20 @@ type HUDStore struct {
 
 	game *Game
 
+	// previewSteps is the path the units would follow if the
+	// SelectedTower was placed and previewDiff the tiles it
+	// adds to the current one
+	previewSteps []graph.Step
+	previewDiff  int
+	// previewKey is what the preview was calculated for, with its
+	// previewErr, so it's only calculated again if it changes
+	previewKey previewKey
+	previewErr error
+	// currentPathLen is the length of the current path of the
+	// Line which only changes when the Towers of it change
+	currentPathKey previewKey
+	currentPathLen int
+
 	ui *ebitenui.UI
 
 	winLoseTextW *widget.Text
@@ -105,6 +151,11 @@ type HUDStore struct {
 	displayTargetUnitAbilityTitle       *widget.Text
 	displayTargetUnitAbilityDescription *widget.Text
 
+	displayTargetUnitAbilityC2           *widget.Container
+	displayTargetUnitAbilityImage2       *widget.Graphic
+	displayTargetUnitAbilityTitle2       *widget.Text
+	displayTargetUnitAbilityDescription2 *widget.Text
+
 	// Towers Target
 	displayTargetTowerC                            *widget.Container
 	displayTargetTowerUpdateC1                     *widget.Container
@@ -130,6 +181,7 @@ type HUDStore struct {
 	displayTargetTowerDamageTxtW                   *widget.Text
 	displayTargetTowerAttackSpeedTxtW              *widget.Text
 	displayTargetTowerNameTxtW                     *widget.Text
+	displayTargetTowerTargetingBtnW                *widget.Button
 
 	towersGC *widget.Container
 
@@ -155,6 +207,11 @@ type HUDStore struct {
 
 	errorC     *widget.Container
 	textErrorW *widget.Text
+
+	heroSummonBtnW        *widget.Button
+	heroSummonToolTipTxtW *widget.Text
+	heroLevelTxtW         *widget.Text
+	heroAbilityBtnsW      map[hero.Ability]*widget.Button
 }
 
 // HUDState stores the HUD state
@@ -168,6 +225,10 @@ type HUDState struct {
 	ShowStats      bool
 	ShowScoreboard bool
 
+	// SpectateLineID is the line that is being
+	// watched when spectating the Game
+	SpectateLineID int
+
 	Error   string
 	ErrorAt time.Time
 }
@@ -189,8 +250,13 @@ var (
 	updateTowerKeybind1 = ebiten.KeyZ
 	updateTowerKeybind2 = ebiten.KeyX
 
-	rangeTowerKeybind = ebiten.KeyQ
-	meleeTowerKeybind = ebiten.KeyW
+	targetingTowerKeybind = ebiten.KeyC
+
+	summonHeroKeybind   = ebiten.KeyH
+	heroAbilityKeybinds = map[hero.Ability]ebiten.Key{
+		hero.Dash:   ebiten.KeyF,
+		hero.Shield: ebiten.KeyG,
+	}
 )
 
 func init() {
@@ -203,8 +269,14 @@ func init() {
 		unitKeybinds[u.Type.String()] = k
 	}
 
-	towerKeybinds[tower.Range1.String()] = rangeTowerKeybind
-	towerKeybinds[tower.Melee1.String()] = meleeTowerKeybind
+	for _, t := range tower.FirstTowers {
+		var k ebiten.Key
+		err := k.UnmarshalText([]byte(t.Keybind))
+		if err != nil {
+			panic(err)
+		}
+		towerKeybinds[t.Type] = k
+	}
 }
 
 // NewHUDStore creates a new HUDStore with the Dispatcher d and the Game g
@@ -258,6 +330,8 @@ func (hs *HUDStore) Update() error {
 	cp := hs.game.Store.Game.FindCurrentPlayer()
 	if cp.ID == "" {
 		// This means the player is no longer there and left
+		// or that we are spectating the Game
+		hs.updateSpectator(hst, x, y)
 		return nil
 	}
 	cl := hs.game.Store.Game.FindLineByID(cp.LineID)
@@ -292,18 +366,43 @@ func (hs *HUDStore) Update() error {
 		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
 			actionDispatcher.DeselectTower(hst.SelectedTower.Type)
 		} else {
-			invalid := !cp.CanPlaceTower(hst.SelectedTower.Type)
+			invalid := !cp.CanPlaceTower(hs.game.Store.Balance(), hs.game.Store.Rules(), hst.SelectedTower.Type)
 			if invalid {
-				selectedTowerErr = fmt.Sprintf("Not enough gold to place tower %s", tower.Towers[hst.SelectedTower.Type].Name())
+				selectedTowerErr = fmt.Sprintf("Not enough gold to place tower %s", tower.Towers[hst.SelectedTower.Type].Name)
 			}
 
 			neo := hst.SelectedTower.Object
 			neo.X += cs.X
 			neo.Y += cs.Y
 
-			if !invalid {
-				invalid = !cl.Graph.CanAddTower(int(neo.X), int(neo.Y), neo.W, neo.H)
+			// The preview is calculated even without gold so the player can
+			// see the effect before having it, and as it's a full search of
+			// the Line it's only done when the position or the Towers change
+			pk := previewKey{
+				graph:         cl.Graph,
+				towersVersion: cl.Graph.TowersVersion(),
+				x:             int(neo.X),
+				y:             int(neo.Y),
+				tower:         hst.SelectedTower.Type,
+			}
+			if pk != hs.previewKey {
+				hs.previewKey = pk
+				hs.previewSteps = nil
+				steps, l, err := cl.Graph.PreviewTower(pk.x, pk.y, neo.W, neo.H)
+				hs.previewErr = err
+				if err == nil {
+					hs.previewSteps = steps
+					hs.previewDiff = l - hs.currentPathLength(cl.Graph)
+				}
+			}
+			err := hs.previewErr
+
+			if !invalid && err != nil {
+				invalid = true
 				selectedTowerErr = "Cannot place the Tower here"
+				if errors.Is(err, graph.ErrInvalidBlockingPath) {
+					selectedTowerErr = "Cannot place the Tower here, it blocks the path"
+				}
 			}
 
 			if !invalid {
@@ -373,8 +472,10 @@ func (hs *HUDStore) Update() error {
 
 	for ut, kb := range unitKeybinds {
 		if inpututil.IsKeyJustPressed(kb) {
-			if cp.CanSummonUnit(ut) {
+			if cp.CanSummonUnit(hs.game.Store.Rules(), ut) {
 				hs.unitsBtns[ut].btn.Click()
+			} else if !hs.game.Store.Rules().AllowsUnit(ut) {
+				actionDispatcher.AddError(fmt.Sprintf("Unit %s is not allowed on this game", unit.Units[ut].Name()))
 			} else {
 				actionDispatcher.AddError(fmt.Sprintf("Not enough gold to summon unit %s", unit.Units[ut].Name()))
 			}
@@ -383,14 +484,31 @@ func (hs *HUDStore) Update() error {
 	}
 	for tt, kb := range towerKeybinds {
 		if inpututil.IsKeyJustPressed(kb) {
-			if cp.CanPlaceTower(tt) {
+			if cp.CanPlaceTower(hs.game.Store.Balance(), hs.game.Store.Rules(), tt) {
 				actionDispatcher.SelectTower(tt, x, y)
+			} else if !hs.game.Store.Rules().AllowsTower(tt) {
+				actionDispatcher.AddError(fmt.Sprintf("Tower %s is not allowed on this game", tower.Towers[tt].Name))
 			} else {
-				actionDispatcher.AddError(fmt.Sprintf("Not enough gold to place tower %s", tower.Towers[tt].Name()))
+				actionDispatcher.AddError(fmt.Sprintf("Not enough gold to place tower %s", tower.Towers[tt].Name))
 			}
 			return nil
 		}
 	}
+	if inpututil.IsKeyJustPressed(summonHeroKeybind) {
+		if cp.CanSummonHero(hs.game.Store.Balance(), hs.game.Store.Rules()) {
+			hs.heroSummonBtnW.Click()
+		} else {
+			actionDispatcher.AddError("Cannot summon the hero")
+		}
+		return nil
+	}
+	for a, kb := range heroAbilityKeybinds {
+		if inpututil.IsKeyJustPressed(kb) {
+			// The cooldown is checked by the Game
+			actionDispatcher.UseHeroAbility(cp.ID, a)
+			return nil
+		}
+	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
 		if hst.OpenTowerMenu != nil || hst.OpenUnitMenu != nil {
 			if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
@@ -414,16 +532,98 @@ func (hs *HUDStore) Update() error {
 		}
 		tw := tower.Towers[hst.OpenTowerMenu.Type]
 		if len(tw.Updates) >= 1 && inpututil.IsKeyJustPressed(updateTowerKeybind1) {
-			actionDispatcher.UpdateTower(cp.ID, hst.OpenTowerMenu.ID, tw.Updates[0].String())
+			actionDispatcher.UpdateTower(cp.ID, hst.OpenTowerMenu.ID, tw.Updates[0])
 		}
 		if len(tw.Updates) >= 2 && inpututil.IsKeyJustPressed(updateTowerKeybind2) {
-			actionDispatcher.UpdateTower(cp.ID, hst.OpenTowerMenu.ID, tw.Updates[1].String())
+			actionDispatcher.UpdateTower(cp.ID, hst.OpenTowerMenu.ID, tw.Updates[1])
+		}
+		if inpututil.IsKeyJustPressed(targetingTowerKeybind) {
+			hs.displayTargetTowerTargetingBtnW.Click()
 		}
 	}
 
 	return nil
 }
 
+// updateSpectator handles the inputs when spectating, which
+// are only the ones to move around and not to play
+// currentPathLength returns the length of the current path of the
+// Graph g, which is only calculated again when its Towers change
+func (hs *HUDStore) currentPathLength(g *graph.Graph) int {
+	k := previewKey{graph: g, towersVersion: g.TowersVersion()}
+	if k != hs.currentPathKey {
+		hs.currentPathKey = k
+		_, hs.currentPathLen = g.ShortestPath()
+	}
+	return hs.currentPathLen
+}
+
+func (hs *HUDStore) updateSpectator(hst HUDState, x, y int) {
+	cs := hs.game.Camera.GetState()
+	if int(cs.LastCursorPosition.X) != x || int(cs.LastCursorPosition.Y) != y || cs.MouseButtonMiddlePressed {
+		actionDispatcher.CursorMove(x, y, ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle))
+	}
+
+	if ebiten.IsKeyPressed(ebiten.KeyTab) && !hst.ShowScoreboard {
+		actionDispatcher.ShowScoreboard(true)
+	} else if !ebiten.IsKeyPressed(ebiten.KeyTab) && hst.ShowScoreboard {
+		actionDispatcher.ShowScoreboard(false)
+	}
+
+	lids := hs.spectateLineIDs()
+	if len(lids) == 0 {
+		return
+	}
+	idx := sort.SearchInts(lids, hst.SpectateLineID)
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		actionDispatcher.GoToLine(lids[(idx+1)%len(lids)])
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		actionDispatcher.GoToLine(lids[(idx-1+len(lids))%len(lids)])
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyF1) {
+		actionDispatcher.GoToLine(lids[idx%len(lids)])
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if hs.ui.IsWindowOpen(hs.menuW) {
+			hs.closeModal(hs.menuW)
+		} else if hs.ui.IsWindowOpen(hs.keybindsW) {
+			hs.closeModal(hs.keybindsW)
+		} else {
+			hs.menuBtnW.Click()
+		}
+	}
+}
+
+// spectateLineIDs returns the sorted lines of the players
+// that can be watched when spectating
+func (hs *HUDStore) spectateLineIDs() []int {
+	players := hs.game.Store.Game.ListPlayers()
+	lids := make([]int, 0, len(players))
+	for _, p := range players {
+		lids = append(lids, p.LineID)
+	}
+	sort.Ints(lids)
+	return lids
+}
+
+// spectatedPlayer returns the player of the line lid
+// or the first one if it has already left the Game
+func (hs *HUDStore) spectatedPlayer(lid int) store.Player {
+	var fp *store.Player
+	for _, p := range hs.game.Store.Game.ListPlayers() {
+		if p.LineID == lid {
+			return *p
+		}
+		if fp == nil || p.LineID < fp.LineID {
+			fp = p
+		}
+	}
+	if fp == nil {
+		return store.Player{}
+	}
+	return *fp
+}
+
 func (hs *HUDStore) Draw(screen *ebiten.Image) {
 	b := time.Now()
 	defer utils.LogTime(hs.game.Logger, b, "hud draw")
@@ -432,7 +632,16 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 	cs := hs.game.Camera.GetState()
 	lstate := hs.game.Store.Game.GetState()
 	cp := hs.game.Store.Game.FindCurrentPlayer()
+	// Without a Current player we are spectating so we
+	// display the information of the watched line
+	spectating := cp.ID == ""
+	if spectating {
+		cp = hs.spectatedPlayer(hst.SpectateLineID)
+	}
 	cl := hs.game.Store.Game.FindLineByID(cp.LineID)
+	if cl == nil {
+		return
+	}
 
 	hs.validateOpenTower(hst.OpenTowerMenu, cl.Towers)
 	hs.validateOpenUnit(hst.OpenUnitMenu, cl.Units)
@@ -457,14 +666,14 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 
 	hs.infoTimerTxt.Label = cutils.FmtDuration(time.Now().Sub(hs.game.Store.Game.GetStartedAt()))
 	hs.infoGoldTxt.Label = strconv.Itoa(cp.Gold)
-	hs.infoCapTxt.Label = fmt.Sprintf("%d/%d", cp.Capacity, utils.MaxCapacity)
+	hs.infoCapTxt.Label = fmt.Sprintf("%d/%d", cp.Capacity, hs.game.Store.Rules().MaxCapacity)
 	hs.infoLivesTxt.Label = strconv.Itoa(cp.Lives)
 	hs.infoIncomeTxt.Label = strconv.Itoa(cp.Income)
 	hs.infoIncomeTimerTxt.Label = fmt.Sprintf("%ds", psit)
 
 	for _, u := range sortedUnits() {
 		uu := cp.UnitUpdates[u.Type.String()]
-		cpcs := cp.CanSummonUnit(u.Type.String())
+		cpcs := cp.CanSummonUnit(hs.game.Store.Rules(), u.Type.String())
 		hs.unitsBtns[u.Type.String()].btn.GetWidget().Disabled = !cpcs
 		hs.unitsBtns[u.Type.String()].enabled.GetWidget().Visibility = widget.Visibility_Show
 		hs.unitsBtns[u.Type.String()].disabled.GetWidget().Visibility = widget.Visibility_Hide
@@ -487,10 +696,20 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 		hs.unitsTooltip[u.Type.String()].movementSpeed.Label = fmt.Sprint(uu.Current.MovementSpeed)
 		hs.unitsTooltip[u.Type.String()].nmovementSpeed.Label = fmt.Sprintf("%.0f", uu.Next.MovementSpeed)
 		hs.unitsTooltip[u.Type.String()].nmovementSpeedDiff.Label = fmt.Sprintf(" (+%.0f)", uu.Next.MovementSpeed-uu.Current.MovementSpeed)
+		at := hs.game.Store.Balance().Unit(u.Type.String()).ArmorType
+		hs.unitsTooltip[u.Type.String()].armor.Label = armor.Name(at)
+		hs.unitsTooltip[u.Type.String()].armorMatchup.Label = armorMatchup(hs.game.Store.Balance().Effectiveness, at)
+		bu := hs.game.Store.Balance().Unit(u.Type.String())
+		r := hs.game.Store.Balance().AbilityRank(uu.Level)
+		for i, a := range bu.Abilities {
+			if i < len(hs.unitsTooltip[u.Type.String()].abilities) {
+				hs.unitsTooltip[u.Type.String()].abilities[i].Label = fmt.Sprintf("%s: %s", abilityTitle(bu.AbilityParams, a, r), abilityDescription(bu.AbilityParams, a, r))
+			}
+		}
 	}
 
 	for _, u := range sortedUnits() {
-		if cp.CanUpdateUnit(u.Type.String()) {
+		if cp.CanUpdateUnit(hs.game.Store.Rules(), u.Type.String()) {
 			ic := (hs.unitAnimationCount / 15) % 4
 			for i, a := range hs.unitsUpdateAnimation[u.Type.String()] {
 				a.GetWidget().Visibility = widget.Visibility_Hide
@@ -508,7 +727,31 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 		}
 	}
 
-	if cp.Lives == 0 {
+	hs.heroSummonBtnW.GetWidget().Disabled = spectating || !cp.CanSummonHero(hs.game.Store.Balance(), hs.game.Store.Rules())
+	hs.heroLevelTxtW.Label = fmt.Sprintf("Hero lvl %d", cp.Hero.Level())
+	hs.heroSummonToolTipTxtW.Label = fmt.Sprintf(heroToolTipTmpl, unit.Units[cp.HeroType()].Name(), cp.HeroCost(hs.game.Store.Balance()), cp.Hero.Level(), cp.Hero.XP)
+	_, heroAlive := hs.game.Store.Game.FindHeroUnit(cp)
+	tt := hs.game.Store.Game.GetTickTime()
+	for a, hab := range hs.heroAbilityBtnsW {
+		cd := cp.HeroCooldown(a, tt)
+		hab.GetWidget().Disabled = spectating || !heroAlive || cd > 0
+		l := fmt.Sprintf(unitToolTipTitleTmpl, hero.Name(a), heroAbilityKeybinds[a])
+		if cd > 0 {
+			l = fmt.Sprintf("%s %.0fs", l, math.Ceil(cd.Seconds()))
+		}
+		hab.Text().Label = l
+	}
+
+	if spectating {
+		hs.winLoseTextW.Label = ""
+		hs.winLoseTextW.GetWidget().Visibility = widget.Visibility_Hide
+		for _, p := range lstate.Players {
+			if p.Winner {
+				hs.winLoseTextW.Label = fmt.Sprintf("%s WON!", p.Name)
+				hs.winLoseTextW.GetWidget().Visibility = widget.Visibility_Show
+			}
+		}
+	} else if cp.Lives == 0 {
 		hs.winLoseTextW.Label = "YOU LOST"
 		hs.winLoseTextW.GetWidget().Visibility = widget.Visibility_Show
 	} else if cp.Winner {
@@ -528,13 +771,15 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 		hs.displayTargetUnitC.GetWidget().Visibility = widget.Visibility_Hide
 		hs.displayDefaultC.GetWidget().Visibility = widget.Visibility_Hide
 
-		ot := tower.Towers[hst.OpenTowerMenu.Type]
+		bl := hs.game.Store.Balance()
+		ot := bl.Tower(hst.OpenTowerMenu.Type)
 		ct := cl.Towers[hst.OpenTowerMenu.ID]
 
 		hs.displayTargetTowerRangeTxtW.Label = fmt.Sprint(ot.Range)
 		hs.displayTargetTowerDamageTxtW.Label = fmt.Sprint(ot.Damage)
 		hs.displayTargetTowerAttackSpeedTxtW.Label = fmt.Sprint(ot.AttackSpeed)
-		hs.displayTargetTowerNameTxtW.Label = ot.Name()
+		hs.displayTargetTowerNameTxtW.Label = ot.Name
+		hs.displayTargetTowerTargetingBtnW.Text().Label = fmt.Sprintf("Target: %s", targeting.Name(ct.Targeting))
 		// TODO: Fix this by being the total amount to reach here and let it be 75%
 		sellTowerGoldReturn := ot.Gold / 2
 
@@ -551,36 +796,36 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 			hs.displayTargetTowerUpdateC2.GetWidget().Visibility = widget.Visibility_Hide
 		} else {
 			if len(tu) >= 1 {
-				tw := tower.Towers[tu[0].String()]
+				tw := bl.Tower(tu[0])
 
 				hs.displayTargetTowerUpdateImage1.Image = cutils.Images.Get(tw.FacesetKey())
 				hs.displayTargetTowerUpdateC1.GetWidget().Visibility = widget.Visibility_Show
-				hs.displayTargetTowerUpdateButton1.GetWidget().Disabled = cp.Gold < tw.Gold
-				hs.displayTargetTowerUpdateToolTip1TitleTxt.Label = fmt.Sprintf(unitToolTipTitleTmpl, tw.Name(), updateTowerKeybind1)
+				hs.displayTargetTowerUpdateButton1.GetWidget().Disabled = !cp.CanUpdateTower(bl, hs.game.Store.Rules(), tw.Type)
+				hs.displayTargetTowerUpdateToolTip1TitleTxt.Label = fmt.Sprintf(unitToolTipTitleTmpl, tw.Name, updateTowerKeybind1)
 				hs.displayTargetTowerUpdateToolTip1GoldTxt.Label = fmt.Sprint(tw.Gold)
 				hs.displayTargetTowerUpdateToolTip1DamageTxt.Label = fmt.Sprint(tw.Damage)
 				hs.displayTargetTowerUpdateToolTip1RangeTxt.Label = fmt.Sprint(tw.Range)
 				hs.displayTargetTowerUpdateToolTip1HealthTxt.Label = fmt.Sprint(tw.Health)
-				hs.displayTargetTowerUpdateToolTip1DescriptionTxt.Label = tw.Description()
+				hs.displayTargetTowerUpdateToolTip1DescriptionTxt.Label = towerDescription(bl.Effectiveness, tw)
 				hs.displayTargetTowerUpdateC2.GetWidget().Visibility = widget.Visibility_Hide
 			}
 			if len(tu) >= 2 {
-				tw := tower.Towers[tu[1].String()]
+				tw := bl.Tower(tu[1])
 				hs.displayTargetTowerUpdateImage2.Image = cutils.Images.Get(tw.FacesetKey())
 				hs.displayTargetTowerUpdateC2.GetWidget().Visibility = widget.Visibility_Show
-				hs.displayTargetTowerUpdateButton2.GetWidget().Disabled = cp.Gold < tw.Gold
-				hs.displayTargetTowerUpdateToolTip2TitleTxt.Label = fmt.Sprintf(unitToolTipTitleTmpl, tw.Name(), updateTowerKeybind2)
+				hs.displayTargetTowerUpdateButton2.GetWidget().Disabled = !cp.CanUpdateTower(bl, hs.game.Store.Rules(), tw.Type)
+				hs.displayTargetTowerUpdateToolTip2TitleTxt.Label = fmt.Sprintf(unitToolTipTitleTmpl, tw.Name, updateTowerKeybind2)
 				hs.displayTargetTowerUpdateToolTip2GoldTxt.Label = fmt.Sprint(tw.Gold)
 				hs.displayTargetTowerUpdateToolTip2DamageTxt.Label = fmt.Sprint(tw.Damage)
 				hs.displayTargetTowerUpdateToolTip2RangeTxt.Label = fmt.Sprint(tw.Range)
 				hs.displayTargetTowerUpdateToolTip2HealthTxt.Label = fmt.Sprint(tw.Health)
-				hs.displayTargetTowerUpdateToolTip2DescriptionTxt.Label = tw.Description()
+				hs.displayTargetTowerUpdateToolTip2DescriptionTxt.Label = towerDescription(bl.Effectiveness, tw)
 			}
 		}
 		hs.displayTargetTowerSellToolTip.Label = fmt.Sprintf(towerRemoveToolTipTmpl, sellTowerGoldReturn)
 
 	} else if hst.OpenUnitMenu != nil {
-		ou := unit.Units[hst.OpenUnitMenu.Type]
+		ou := hs.game.Store.Balance().Unit(hst.OpenUnitMenu.Type)
 		cou := cl.Units[hst.OpenUnitMenu.ID]
 
 		hs.displayTargetUnitC.GetWidget().Visibility = widget.Visibility_Show
@@ -588,13 +833,25 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 
 		hs.displayTargetProfile.Image = cutils.Images.Get(ou.ProfileKey())
 		hs.displayTargetUnitNameTxtW.Label = fmt.Sprintf("%s (lvl %d)", ou.Name(), cou.Level)
+		if cou.Hero {
+			hs.displayTargetUnitNameTxtW.Label = fmt.Sprintf("Hero %s (lvl %d)", ou.Name(), cou.Level)
+		}
 		hs.displayTargetUnitMovementSpeedTxtW.Label = fmt.Sprint(cou.MovementSpeed)
 		hs.displayTargetUnitBountyTxtW.Label = fmt.Sprint(cou.Bounty)
 		ucp := hs.game.Store.Game.FindPlayerByID(cou.PlayerID)
 		hs.displayTargetUnitPlayerTxtW.Label = ucp.Name
 		hs.displayTargetUnitAbilityImage1.Image = cutils.Images.Get(ability.Key(ou.Abilities[0].String()))
-		hs.displayTargetUnitAbilityTitle.Label = ability.Name(ou.Abilities[0])
-		hs.displayTargetUnitAbilityDescription.Label = ability.Description(ou.Abilities[0])
+		r := cou.AbilityRank(hs.game.Store.Balance())
+		hs.displayTargetUnitAbilityTitle.Label = abilityTitle(ou.AbilityParams, ou.Abilities[0], r)
+		hs.displayTargetUnitAbilityDescription.Label = abilityDescription(ou.AbilityParams, ou.Abilities[0], r)
+		// The second ability is usually an Aura
+		hs.displayTargetUnitAbilityC2.GetWidget().Visibility = widget.Visibility_Hide
+		if len(ou.Abilities) > 1 {
+			hs.displayTargetUnitAbilityC2.GetWidget().Visibility = widget.Visibility_Show
+			hs.displayTargetUnitAbilityImage2.Image = cutils.Images.Get(ability.Key(ou.Abilities[1].String()))
+			hs.displayTargetUnitAbilityTitle2.Label = abilityTitle(ou.AbilityParams, ou.Abilities[1], r)
+			hs.displayTargetUnitAbilityDescription2.Label = abilityDescription(ou.AbilityParams, ou.Abilities[1], r)
+		}
 
 		hs.displayTargetHealth.Label = fmt.Sprintf("%0.f/%0.f", cou.Health, cou.MaxHealth)
 		hs.displayTargetHealthBar.Min = 0
@@ -605,10 +862,32 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 		hs.displayDefaultC.GetWidget().Visibility = widget.Visibility_Hide
 	}
 
+	// Spectators can only watch so none of
+	// the build or summon controls are displayed
+	if spectating {
+		hs.displayTargetC.GetWidget().Visibility = widget.Visibility_Hide
+		hs.displayDefaultC.GetWidget().Visibility = widget.Visibility_Hide
+	}
+
 	hs.ui.Draw(screen)
 
+	if hst.SelectedTower != nil && len(hs.previewSteps) != 0 {
+		// The path is drawn as a dot on the middle of each tile
+		for _, st := range hs.previewSteps {
+			x, y := st.X-cs.X, st.Y-cs.Y
+			vector.DrawFilledRect(screen, float32(x+6), float32(y+6), 4, 4, cutils.Green, false)
+		}
+
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(hst.SelectedTower.X, hst.SelectedTower.Y-20)
+		op.ColorScale.ScaleWithColor(cutils.White)
+		text.Draw(screen, fmt.Sprintf("%+d tiles", hs.previewDiff), cutils.SmallFont, op)
+	}
+
 	if hst.SelectedTower != nil {
+		img := cutils.Images.Get(hst.SelectedTower.IdleKey())
 		op := &ebiten.DrawImageOptions{}
+		scaleToFootprint(op, img, hst.SelectedTower.W, hst.SelectedTower.H)
 		op.GeoM.Translate(float64(hst.SelectedTower.X)/cs.Zoom, float64(hst.SelectedTower.Y)/cs.Zoom)
 		op.GeoM.Scale(cs.Zoom, cs.Zoom)
 
@@ -616,7 +895,7 @@ func (hs *HUDStore) Draw(screen *ebiten.Image) {
 			op.ColorM.Scale(2, 0.5, 0.5, 0.9)
 		}
 
-		screen.DrawImage(cutils.Images.Get(hst.SelectedTower.IdleKey()), op)
+		screen.DrawImage(img, op)
 	}
 }
 
@@ -625,15 +904,15 @@ func (hs *HUDStore) Reduce(state HUDState, act *action.Action) HUDState {
 	case action.SelectTower:
 		cp := hs.game.Store.Game.FindCurrentPlayer()
 		cs := hs.game.Camera.GetState()
-		x, y := fixPosition(cs, act.SelectTower.X, act.SelectTower.Y)
+		w, h := hs.game.Store.Balance().Tower(act.SelectTower.Type).Size()
+		x, y := fixPosition(cs, act.SelectTower.X, act.SelectTower.Y, w, h)
 		state.SelectedTower = &SelectedTower{
 			Tower: store.Tower{
 				Object: utils.Object{
-					// The Buttons have 16*2 so we want to place it on the middle so just 16
 					X: x,
 					Y: y,
-					W: 32,
-					H: 32,
+					W: w,
+					H: h,
 				},
 				Type:   act.SelectTower.Type,
 				LineID: cp.LineID,
@@ -650,7 +929,7 @@ func (hs *HUDStore) Reduce(state HUDState, act *action.Action) HUDState {
 		if state.SelectedTower != nil {
 			cs := hs.game.Camera.GetState()
 
-			state.SelectedTower.X, state.SelectedTower.Y = fixPosition(cs, nx, ny)
+			state.SelectedTower.X, state.SelectedTower.Y = fixPosition(cs, nx, ny, state.SelectedTower.W, state.SelectedTower.H)
 		}
 	case action.PlaceTower, action.DeselectTower:
 		state.SelectedTower = nil
@@ -676,6 +955,10 @@ func (hs *HUDStore) Reduce(state HUDState, act *action.Action) HUDState {
 		state.OpenUnitMenu = nil
 	case action.ShowScoreboard:
 		state.ShowScoreboard = act.ShowScoreboard.Display
+	case action.GoToLine:
+		state.SpectateLineID = act.GoToLine.LineID
+	case action.StartGame:
+		state.SpectateLineID = 0
 	case action.AddError:
 		state.Error = act.AddError.Error
 		state.ErrorAt = time.Now()
@@ -703,7 +986,7 @@ func (hs *HUDStore) findUnitByID(uid string) *store.Unit {
 	return nil
 }
 
-func fixPosition(cs CameraState, x, y int) (float64, float64) {
+func fixPosition(cs CameraState, x, y, w, h int) (float64, float64) {
 	absnx := x + int(cs.X)
 	absny := y + int(cs.Y)
 	// We find the closes multiple in case the cursor moves too fast, between FPS reloads,
@@ -711,17 +994,21 @@ func fixPosition(cs CameraState, x, y int) (float64, float64) {
 	// is not updated and the result is the cursor far away from the Drawing of the SelectedTower
 	// as it has stayed on the previous position
 	var multiple int = 16
-	// If it's == 0 means it's exact but as we want to center it we remove 16 (towers are 32)
+	// To center it we remove the half of the footprint rounded
+	// to the tiles, so a 32px tower moves 16 and a 16px one 0
+	cx := (w / multiple / 2) * multiple
+	cy := (h / multiple / 2) * multiple
+	// If it's == 0 means it's exact so we only have to center it
 	// If it's !=0 then we find what's the remaning for
 	if absnx%multiple == 0 {
-		x -= 16
+		x -= cx
 	} else {
-		x = utils.ClosestMultiple(absnx, multiple) - 16 - int(cs.X)
+		x = utils.ClosestMultiple(absnx, multiple) - cx - int(cs.X)
 	}
 	if absny%multiple == 0 {
-		y -= 16
+		y -= cy
 	} else {
-		y = utils.ClosestMultiple(absny, multiple) - 16 - int(cs.Y)
+		y = utils.ClosestMultiple(absny, multiple) - cy - int(cs.Y)
 	}
 
 	return float64(x), float64(y)
@@ -739,6 +1026,63 @@ func sortedTowers() []*tower.Tower {
 	return tower.FirstTowers
 }
 
+// towerDescription returns the Description of the
+// Tower t with the matchup of its DamageType
+func towerDescription(e balance.Effectiveness, t *tower.Tower) string {
+	strong := make([]string, 0)
+	for _, a := range e.Strong(t.DamageType) {
+		strong = append(strong, armor.Name(a))
+	}
+	weak := make([]string, 0)
+	for _, a := range e.Weak(t.DamageType) {
+		weak = append(weak, armor.Name(a))
+	}
+	return fmt.Sprintf("%s\n"+damageMatchupTmpl, t.Description, damage.Name(t.DamageType), joinNames(strong), joinNames(weak))
+}
+
+// armorMatchup returns the damages the armor a is weak to and resists
+func armorMatchup(e balance.Effectiveness, a armor.Armor) string {
+	weak := make([]string, 0)
+	resists := make([]string, 0)
+	for _, d := range damage.DamageValues() {
+		f := e.Factor(d, a)
+		if f > 1 {
+			weak = append(weak, damage.Name(d))
+		} else if f < 1 {
+			resists = append(resists, damage.Name(d))
+		}
+	}
+	return fmt.Sprintf(armorMatchupTmpl, joinNames(weak), joinNames(resists))
+}
+
+// abilityTitle returns the name of the Ability a with the
+// rank r if it has more than one on the Params p
+func abilityTitle(p ability.Params, a ability.Ability, r int) string {
+	n := ability.Ranks(p, a)
+	if n == 1 {
+		return ability.Name(a)
+	}
+	return fmt.Sprintf(abilityRankTmpl, ability.Name(a), min(r, n), n)
+}
+
+// abilityDescription returns the Description of the
+// Ability a with the values of the rank r on the Params p
+func abilityDescription(p ability.Params, a ability.Ability, r int) string {
+	rd := ability.RankDescription(p, a, r)
+	if rd == "" {
+		return ability.Description(a)
+	}
+	return fmt.Sprintf("%s\n%s", ability.Description(a), rd)
+}
+
+// joinNames joins the names ns or returns "-" if there are none
+func joinNames(ns []string) string {
+	if len(ns) == 0 {
+		return "-"
+	}
+	return strings.Join(ns, ", ")
+}
+
 func (hs *HUDStore) buildUI() {
 	rootContainer := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewStackedLayout()),
@@ -755,6 +1099,7 @@ func (hs *HUDStore) buildUI() {
 		hs.errorMessageUI(),
 		hs.menuUI(),
 		hs.winLoseTextUI(),
+		hs.heroUI(),
 	)
 	hs.scoreboardModal()
 	hs.menuModal()
@@ -835,6 +1180,81 @@ func (hs *HUDStore) winLoseTextUI() *widget.Container {
 	return wlC
 }
 
+func (hs *HUDStore) heroUI() *widget.Container {
+	heroC := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
+	)
+
+	heroBtnsC := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Padding(widget.NewInsetsSimple(10)),
+			widget.RowLayoutOpts.Spacing(5),
+		)),
+		widget.ContainerOpts.BackgroundImage(image.NewNineSliceColor(cutils.BlackT)),
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
+				HorizontalPosition: widget.AnchorLayoutPositionStart,
+				VerticalPosition:   widget.AnchorLayoutPositionCenter,
+			}),
+		),
+	)
+
+	heroLevelTxtW := widget.NewText(
+		widget.TextOpts.Text("", cutils.SmallFont, cutils.White),
+	)
+
+	heroSummonToolTip, _, heroSummonToolTipTxtW := hs.simpleTooltip(fmt.Sprintf(unitToolTipTitleTmpl, "Hero", summonHeroKeybind), "")
+	heroSummonBtnW := widget.NewButton(
+		widget.ButtonOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Stretch: true,
+			}),
+			widget.WidgetOpts.ToolTip(heroSummonToolTip),
+		),
+		widget.ButtonOpts.Image(cutils.TextButtonResource()),
+		widget.ButtonOpts.Text(fmt.Sprintf(unitToolTipTitleTmpl, "Hero", summonHeroKeybind), cutils.SmallFont, &cutils.ButtonTextColor),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			cp := hs.game.Store.Game.FindCurrentPlayer()
+			actionDispatcher.SummonHero(cp.ID, cp.LineID, hs.game.Store.Map.GetNextLineID(cp.LineID))
+		}),
+	)
+
+	heroBtnsC.AddChild(
+		heroSummonBtnW,
+		heroLevelTxtW,
+	)
+
+	hs.heroAbilityBtnsW = make(map[hero.Ability]*widget.Button)
+	for _, a := range hero.AbilityValues() {
+		a := a
+		hab := widget.NewButton(
+			widget.ButtonOpts.WidgetOpts(
+				widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+					Stretch: true,
+				}),
+				widget.WidgetOpts.ToolTip(hs.justSimpleTooltip(hero.Name(a), hero.Description(a))),
+			),
+			widget.ButtonOpts.Image(cutils.TextButtonResource()),
+			widget.ButtonOpts.Text(fmt.Sprintf(unitToolTipTitleTmpl, hero.Name(a), heroAbilityKeybinds[a]), cutils.SmallFont, &cutils.ButtonTextColor),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				cp := hs.game.Store.Game.FindCurrentPlayer()
+				actionDispatcher.UseHeroAbility(cp.ID, a)
+			}),
+		)
+		heroBtnsC.AddChild(hab)
+		hs.heroAbilityBtnsW[a] = hab
+	}
+
+	heroC.AddChild(heroBtnsC)
+
+	hs.heroSummonBtnW = heroSummonBtnW
+	hs.heroSummonToolTipTxtW = heroSummonToolTipTxtW
+	hs.heroLevelTxtW = heroLevelTxtW
+
+	return heroC
+}
+
 func (hs *HUDStore) displayDefaultUI() *widget.Container {
 	displayC := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
@@ -1136,6 +1556,16 @@ func (hs *HUDStore) displayDefaultUI() *widget.Container {
 			widget.TextOpts.Text("Y", cutils.SFont20, cutils.White),
 		)
 
+		ttArmorTxt := widget.NewText(
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+			widget.TextOpts.Text(armor.Name(u.ArmorType), cutils.SFont20, cutils.White),
+		)
+
+		ttArmorMatchupTxt := widget.NewText(
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+			widget.TextOpts.Text(armorMatchup(balance.Default.Effectiveness, u.ArmorType), cutils.SFont20, cutils.White),
+		)
+
 		tooltipDetailsRows.AddChild(
 			widget.NewText(
 				widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
@@ -1159,6 +1589,13 @@ func (hs *HUDStore) displayDefaultUI() *widget.Container {
 			movementSpeedIconC,
 			ttMovementSpeedTxtW,
 			nMovementSpeedC,
+
+			widget.NewText(
+				widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+				widget.TextOpts.Text("armor", cutils.SFont20, cutils.White),
+			),
+			ttArmorTxt,
+			ttArmorMatchupTxt,
 		)
 		hs.unitsTooltip[u.Type.String()] = &unitTooltips{
 			title:              ttTitleTxt,
@@ -1176,6 +1613,8 @@ func (hs *HUDStore) displayDefaultUI() *widget.Container {
 			movementSpeed:      ttMovementSpeedTxtW,
 			nmovementSpeed:     ttNextMovementSpeedTxt,
 			nmovementSpeedDiff: ttNextMovementSpeedDiffTxt,
+			armor:              ttArmorTxt,
+			armorMatchup:       ttArmorMatchupTxt,
 		}
 
 		tooltipDetailsC.AddChild(
@@ -1194,10 +1633,12 @@ func (hs *HUDStore) displayDefaultUI() *widget.Container {
 		)
 
 		for _, a := range u.Abilities {
-			tooltipC.AddChild(widget.NewText(
+			ttAbilityTxt := widget.NewText(
 				widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
-				widget.TextOpts.Text(fmt.Sprintf("%s: %s", ability.Name(a), ability.Description(a)), cutils.SFont20, cutils.White),
-			))
+				widget.TextOpts.Text(fmt.Sprintf("%s: %s", abilityTitle(u.AbilityParams, a, 1), abilityDescription(u.AbilityParams, a, 1)), cutils.SFont20, cutils.White),
+			)
+			hs.unitsTooltip[u.Type.String()].abilities = append(hs.unitsTooltip[u.Type.String()].abilities, ttAbilityTxt)
+			tooltipC.AddChild(ttAbilityTxt)
 		}
 
 		ubtn := widget.NewButton(
@@ -1328,10 +1769,7 @@ func (hs *HUDStore) displayDefaultUI() *widget.Container {
 			),
 		)
 
-		kb := rangeTowerKeybind
-		if t.Type == tower.Melee1 {
-			kb = meleeTowerKeybind
-		}
+		kb := towerKeybinds[t.Type]
 
 		ttC, _, _, _, _, _, _ := hs.towerToolTip(t, kb)
 		tbtn := widget.NewButton(
@@ -1353,7 +1791,7 @@ func (hs *HUDStore) displayDefaultUI() *widget.Container {
 			widget.ButtonOpts.ClickedHandler(func(t *tower.Tower) func(args *widget.ButtonClickedEventArgs) {
 				return func(args *widget.ButtonClickedEventArgs) {
 					hst := hs.GetState()
-					actionDispatcher.SelectTower(t.Type.String(), int(hst.LastCursorPosition.X), int(hst.LastCursorPosition.Y))
+					actionDispatcher.SelectTower(t.Type, int(hst.LastCursorPosition.X), int(hst.LastCursorPosition.Y))
 				}
 			}(t)),
 		)
@@ -1679,9 +2117,33 @@ func (hs *HUDStore) displayTargetUI() *widget.Container {
 		towerInfoRangeC,
 		towerInfoAttackSpeedC,
 	)
+	towerTargetingBtnW := widget.NewButton(
+		widget.ButtonOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Position: widget.RowLayoutPositionStart,
+			}),
+			widget.WidgetOpts.ToolTip(hs.justSimpleTooltip(fmt.Sprintf("Targeting (%s)", targetingTowerKeybind.String()), "Which unit on range the tower attacks, click to change it")),
+		),
+		widget.ButtonOpts.Image(cutils.TextButtonResource()),
+		widget.ButtonOpts.Text("", cutils.SmallFont, &cutils.ButtonTextColor),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			hst := hs.GetState()
+			if hst.OpenTowerMenu == nil {
+				return
+			}
+			cp := hs.game.Store.Game.FindCurrentPlayer()
+			ct := hs.game.Store.Game.FindLineByID(cp.LineID).Towers[hst.OpenTowerMenu.ID]
+			if ct == nil {
+				return
+			}
+			actionDispatcher.UpdateTowerTargeting(cp.ID, ct.ID, ct.Targeting.Next())
+		}),
+	)
+
 	towerInfoC.AddChild(
 		towerNameTxtW,
 		towerDetailsInfoC,
+		towerTargetingBtnW,
 	)
 
 	towerButtonsC := widget.NewContainer(
@@ -1800,7 +2262,7 @@ func (hs *HUDStore) displayTargetUI() *widget.Container {
 		),
 	)
 
-	ttu1C, ttu1TitleTxtW, ttu1GoldTxtW, ttu1DamageTxtW, ttu1RangeTxtW, ttu1HealthTxtW, ttu1DescriptionContentTxtW := hs.towerToolTip(tower.Towers[tower.Range1.String()], updateTowerKeybind1)
+	ttu1C, ttu1TitleTxtW, ttu1GoldTxtW, ttu1DamageTxtW, ttu1RangeTxtW, ttu1HealthTxtW, ttu1DescriptionContentTxtW := hs.towerToolTip(tower.FirstTowers[0], updateTowerKeybind1)
 
 	update1BtnW := widget.NewButton(
 		// set general widget options
@@ -1877,7 +2339,7 @@ func (hs *HUDStore) displayTargetUI() *widget.Container {
 		),
 	)
 
-	ttu2C, ttu2TitleTxtW, ttu2GoldTxtW, ttu2DamageTxtW, ttu2RangeTxtW, ttu2HealthTxtW, ttu2DescriptionContentTxtW := hs.towerToolTip(tower.Towers[tower.Range1.String()], updateTowerKeybind2)
+	ttu2C, ttu2TitleTxtW, ttu2GoldTxtW, ttu2DamageTxtW, ttu2RangeTxtW, ttu2HealthTxtW, ttu2DescriptionContentTxtW := hs.towerToolTip(tower.FirstTowers[0], updateTowerKeybind2)
 
 	update2BtnW := widget.NewButton(
 		// set general widget options
@@ -2188,6 +2650,34 @@ func (hs *HUDStore) displayTargetUI() *widget.Container {
 		ab1ImageBtnGraphicW,
 	)
 
+	ab2ImageBtnC := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewStackedLayout()),
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.GridLayoutData{
+				MaxWidth:  46,
+				MaxHeight: 46,
+			}),
+		),
+	)
+
+	ab2ttc, ab2ttTitle, ab2ttDescription := hs.simpleTooltip("", "")
+
+	ab2BtnW := widget.NewButton(
+		widget.ButtonOpts.WidgetOpts(
+			widget.WidgetOpts.ToolTip(ab2ttc),
+		),
+		widget.ButtonOpts.Image(cutils.ButtonBorderResource()),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+		}),
+	)
+
+	ab2ImageBtnGraphicW := widget.NewGraphic(widget.GraphicOpts.Image(cutils.Images.Get(cutils.SellIconKey)))
+
+	ab2ImageBtnC.AddChild(
+		ab2BtnW,
+		ab2ImageBtnGraphicW,
+	)
+
 	unitInfoC.AddChild(
 		unitNameTxtW,
 		unitDetailsInfoC,
@@ -2208,12 +2698,7 @@ func (hs *HUDStore) displayTargetUI() *widget.Container {
 				widget.WidgetOpts.MinSize(46, 46),
 			),
 		),
-		widget.NewContainer(
-			widget.ContainerOpts.Layout(widget.NewStackedLayout()),
-			widget.ContainerOpts.WidgetOpts(
-				widget.WidgetOpts.MinSize(46, 46),
-			),
-		),
+		ab2ImageBtnC,
 	)
 
 	unitDetailsC.AddChild(
@@ -2260,6 +2745,7 @@ func (hs *HUDStore) displayTargetUI() *widget.Container {
 	hs.displayTargetTowerDamageTxtW = towerDamageTxtW
 	hs.displayTargetTowerAttackSpeedTxtW = towerAttackSpeedTxtW
 	hs.displayTargetTowerNameTxtW = towerNameTxtW
+	hs.displayTargetTowerTargetingBtnW = towerTargetingBtnW
 
 	// Units
 	hs.displayTargetUnitC = unitDetailsC
@@ -2272,6 +2758,10 @@ func (hs *HUDStore) displayTargetUI() *widget.Container {
 	hs.displayTargetUnitAbilityImage1 = ab1ImageBtnGraphicW
 	hs.displayTargetUnitAbilityTitle = ab1ttTitle
 	hs.displayTargetUnitAbilityDescription = ab1ttDescription
+	hs.displayTargetUnitAbilityC2 = ab2ImageBtnC
+	hs.displayTargetUnitAbilityImage2 = ab2ImageBtnGraphicW
+	hs.displayTargetUnitAbilityTitle2 = ab2ttTitle
+	hs.displayTargetUnitAbilityDescription2 = ab2ttDescription
 
 	hs.displayTargetC = displayC
 
@@ -2985,7 +3475,11 @@ func (hs *HUDStore) menuModal() {
 		// add a handler that reacts to clicking the button
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
 			u := hs.game.Store.Game.FindCurrentPlayer()
-			actionDispatcher.RemovePlayer(u.ID)
+			if u.ID == "" {
+				actionDispatcher.ExitSpectateGame()
+			} else {
+				actionDispatcher.RemovePlayer(u.ID)
+			}
 			hs.closeModal(hs.menuW)
 		}),
 
@@ -3115,6 +3609,29 @@ func (hs *HUDStore) menuKeybindsModal() {
 		updateKeybindTxt,
 	)
 
+	heroKeybinds := []struct {
+		name string
+		kb   ebiten.Key
+	}{
+		{name: "Hero", kb: summonHeroKeybind},
+		{name: hero.Name(hero.Dash), kb: heroAbilityKeybinds[hero.Dash]},
+		{name: hero.Name(hero.Shield), kb: heroAbilityKeybinds[hero.Shield]},
+	}
+	for _, hk := range heroKeybinds {
+		nameTxt := widget.NewText(
+			widget.TextOpts.Text(hk.name, cutils.SmallFont, cutils.TextColor),
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+		)
+		keybindTxt := widget.NewText(
+			widget.TextOpts.Text(hk.kb.String(), cutils.SmallFont, cutils.TextColor),
+			widget.TextOpts.Position(widget.TextPositionEnd, widget.TextPositionCenter),
+		)
+		unitsKeybindsGC.AddChild(
+			nameTxt,
+			keybindTxt,
+		)
+	}
+
 	towerstitleW := widget.NewText(
 		widget.TextOpts.Text("Towers", cutils.NormalFont, cutils.TextColor),
 		widget.TextOpts.Position(widget.TextPositionCenter, widget.TextPositionCenter),
@@ -3142,11 +3659,11 @@ func (hs *HUDStore) menuKeybindsModal() {
 
 	for _, t := range sortedTowers() {
 		nameTxt := widget.NewText(
-			widget.TextOpts.Text(t.Name(), cutils.SmallFont, cutils.TextColor),
+			widget.TextOpts.Text(t.Name, cutils.SmallFont, cutils.TextColor),
 			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
 		)
 		keybindTxt := widget.NewText(
-			widget.TextOpts.Text(towerKeybinds[t.Type.String()].String(), cutils.SmallFont, cutils.TextColor),
+			widget.TextOpts.Text(towerKeybinds[t.Type].String(), cutils.SmallFont, cutils.TextColor),
 			widget.TextOpts.Position(widget.TextPositionEnd, widget.TextPositionCenter),
 		)
 		towersKeybindsGC.AddChild(
@@ -3194,6 +3711,19 @@ func (hs *HUDStore) menuKeybindsModal() {
 		secondUpdateKeybindTxt,
 	)
 
+	targetingNameTxt := widget.NewText(
+		widget.TextOpts.Text("Target - Change targeting", cutils.SmallFont, cutils.TextColor),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+	)
+	targetingKeybindTxt := widget.NewText(
+		widget.TextOpts.Text(targetingTowerKeybind.String(), cutils.SmallFont, cutils.TextColor),
+		widget.TextOpts.Position(widget.TextPositionEnd, widget.TextPositionCenter),
+	)
+	towersKeybindsGC.AddChild(
+		targetingNameTxt,
+		targetingKeybindTxt,
+	)
+
 	otherstitleW := widget.NewText(
 		widget.TextOpts.Text("Others", cutils.NormalFont, cutils.TextColor),
 		widget.TextOpts.Position(widget.TextPositionCenter, widget.TextPositionCenter),
@@ -3351,7 +3881,7 @@ func (hs *HUDStore) towerToolTip(t *tower.Tower, kb ebiten.Key) (*widget.ToolTip
 
 	ttTitleTxt := widget.NewText(
 		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
-		widget.TextOpts.Text(fmt.Sprintf(unitToolTipTitleTmpl, t.Name(), kb), cutils.SmallFont, cutils.White),
+		widget.TextOpts.Text(fmt.Sprintf(unitToolTipTitleTmpl, t.Name, kb), cutils.SmallFont, cutils.White),
 	)
 
 	goldIconC := widget.NewContainer(
@@ -3455,7 +3985,7 @@ func (hs *HUDStore) towerToolTip(t *tower.Tower, kb ebiten.Key) (*widget.ToolTip
 	)
 	ttDescriptionContentTxt := widget.NewText(
 		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
-		widget.TextOpts.Text(t.Description(), cutils.SFont20, cutils.White),
+		widget.TextOpts.Text(towerDescription(balance.Default.Effectiveness, t), cutils.SFont20, cutils.White),
 	)
 
 	tooltipDetailsRows.AddChild(
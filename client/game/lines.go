@@ -3,6 +3,7 @@ package game
 import (
 	"bytes"
 	"image"
+	"image/color"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -10,7 +11,6 @@ import (
 	"github.com/xescugc/maze-wars/assets"
 	cutils "github.com/xescugc/maze-wars/client/utils"
 	"github.com/xescugc/maze-wars/store"
-	"github.com/xescugc/maze-wars/tower"
 	"github.com/xescugc/maze-wars/unit/ability"
 	"github.com/xescugc/maze-wars/unit/buff"
 	"github.com/xescugc/maze-wars/utils"
@@ -29,6 +29,28 @@ var (
 		utils.Left:  2,
 		utils.Right: 3,
 	}
+
+	// effectColors are the colors of the marks drawn
+	// on top of the Units for each of the Effects
+	effectColors = map[buff.Buff]color.Color{
+		buff.Slowed:        color.RGBA{86, 156, 214, 255},
+		buff.Poisoned:      cutils.Green,
+		buff.Stunned:       color.RGBA{240, 200, 60, 255},
+		buff.ArmorShredded: cutils.Red,
+		buff.Shielded:      color.RGBA{200, 200, 200, 255},
+	}
+
+	// heroColor is the color of the mark drawn around the Heroes
+	heroColor = color.RGBA{255, 215, 0, 255}
+
+	// auraColors are the colors of the rings drawn
+	// around the Units that have an Aura
+	auraColors = map[ability.Ability]color.Color{
+		ability.HealingAura: cutils.Green,
+		ability.ShieldAura:  color.RGBA{86, 156, 214, 255},
+		ability.SpeedAura:   color.RGBA{240, 200, 60, 255},
+		ability.Commander:   color.RGBA{160, 100, 220, 255},
+	}
 )
 
 func NewLines(g *Game) (*Lines, error) {
@@ -56,7 +78,11 @@ func (ls *Lines) Draw(screen *ebiten.Image) {
 	b := time.Now()
 	defer utils.LogTime(ls.game.Logger, b, "lines draw")
 
-	hst := ls.game.HUD.GetState()
+	// When watching a replay there is no HUD
+	var hst HUDState
+	if ls.game.HUD != nil {
+		hst = ls.game.HUD.GetState()
+	}
 	for _, l := range ls.game.Store.Game.ListLines() {
 		for _, t := range l.Towers {
 			ls.DrawTower(screen, ls.game.Camera, t)
@@ -95,10 +121,12 @@ func (ls *Lines) DrawTower(screen *ebiten.Image, c *CameraStore, t *store.Tower)
 	}
 	x := float64(t.X - cs.X)
 	y := float64(t.Y - cs.Y)
+	img := cutils.Images.Get(t.IdleKey())
 	op := &ebiten.DrawImageOptions{}
+	scaleToFootprint(op, img, t.W, t.H)
 	op.GeoM.Translate(x, y)
 	op.GeoM.Scale(cs.Zoom, cs.Zoom)
-	screen.DrawImage(cutils.Images.Get(t.IdleKey()), op)
+	screen.DrawImage(img, op)
 
 	//x := float64(p.X - cs.X)
 	//y := float64(p.Y - cs.Y)
@@ -117,7 +145,7 @@ func (ls *Lines) DrawTowerHelath(screen *ebiten.Image, c *CameraStore, t *store.
 		return
 	}
 
-	ot := tower.Towers[t.Type]
+	ot := ls.game.Store.Balance().Tower(t.Type)
 	// Only draw the Health bar if the Tower has been hit
 	if t.Health != ot.Health {
 		lbui := cutils.Images.Get(cutils.LifeBarBigUnderKey)
@@ -139,10 +167,17 @@ func (ls *Lines) DrawTowerSelected(screen *ebiten.Image, c *CameraStore, t *stor
 	}
 	x := float64(t.X - cs.X)
 	y := float64(t.Y - cs.Y)
-	ot := tower.Towers[t.Type]
+	tc := t.Center()
 
-	vector.StrokeRect(screen, float32(x-1), float32(y-1), 34, 34, 2, cutils.Green, false)
-	vector.StrokeCircle(screen, float32(x+16), float32(y+16), float32(ot.Range*32+16), 2, cutils.Green, false)
+	vector.StrokeRect(screen, float32(x-1), float32(y-1), float32(t.W+2), float32(t.H+2), 2, cutils.Green, false)
+	vector.StrokeCircle(screen, float32(tc.X-cs.X), float32(tc.Y-cs.Y), float32(t.RangeRadius(ls.game.Store.Balance())), 2, cutils.Green, false)
+}
+
+// scaleToFootprint scales the Idle img of a Tower
+// so it fills the w, h of the Tower footprint
+func scaleToFootprint(op *ebiten.DrawImageOptions, img *ebiten.Image, w, h int) {
+	b := img.Bounds()
+	op.GeoM.Scale(float64(w)/float64(b.Dx()), float64(h)/float64(b.Dy()))
 }
 
 func (ls *Lines) DrawUnitSelected(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
@@ -156,6 +191,52 @@ func (ls *Lines) DrawUnitSelected(screen *ebiten.Image, c *CameraStore, u *store
 	vector.StrokeRect(screen, float32(x-1), float32(y-1), 18, 18, 2, cutils.Green, false)
 }
 
+// DrawUnitEffects draws a mark for each Effect the Unit has, one
+// for each stack, on top of the Unit and the Health bar
+func (ls *Lines) DrawUnitEffects(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
+	cs := c.GetState()
+	x := float32(u.X - cs.X)
+	y := float32(u.Y-cs.Y) - 8
+	for _, b := range buff.BuffValues() {
+		be, ok := u.BuffEffect(b)
+		if !ok {
+			continue
+		}
+		for i := 0; i < be.Stacks; i++ {
+			vector.DrawFilledRect(screen, x, y, 3, 3, effectColors[b], false)
+			x += 4
+		}
+	}
+}
+
+// DrawUnitAuras draws a ring of the store.AuraRadius
+// around the Unit u for each of the Auras it has
+func (ls *Lines) DrawUnitAuras(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
+	cs := c.GetState()
+	r := float32(store.AuraRadius)
+	for _, a := range ls.game.Store.Balance().Unit(u.Type).Abilities {
+		if !a.IsAura() {
+			continue
+		}
+		x := float32(u.X-cs.X) + float32(u.W)/2
+		y := float32(u.Y-cs.Y) + float32(u.H)/2
+		vector.StrokeCircle(screen, x, y, r, 1, auraColors[a], false)
+		// Each ring is a bit smaller so all of them can be seen
+		r -= 2
+	}
+}
+
+// DrawUnitHero draws a mark around the Unit u if it's a Hero
+func (ls *Lines) DrawUnitHero(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
+	if !u.Hero {
+		return
+	}
+	cs := c.GetState()
+	x := float32(u.X-cs.X) + float32(u.W)/2
+	y := float32(u.Y-cs.Y) + float32(u.H)/2
+	vector.StrokeCircle(screen, x, y, float32(u.W)/2+2, 1, heroColor, false)
+}
+
 func (ls *Lines) DrawUnit(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
 	cs := c.GetState()
 	// This is to display the full unit calculated path as a line
@@ -166,6 +247,9 @@ func (ls *Lines) DrawUnit(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
 	if !u.IsColliding(cs.Object) {
 		return
 	}
+	ls.DrawUnitAuras(screen, c, u)
+	ls.DrawUnitHero(screen, c, u)
+
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(u.X-cs.X, u.Y-cs.Y)
 	op.GeoM.Scale(cs.Zoom, cs.Zoom)
@@ -173,14 +257,14 @@ func (ls *Lines) DrawUnit(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
 	i := (u.MovingCount / 5) % 4
 	sy := i * u.H
 	if u.HasBuff(buff.Burrowoed) {
-		if u.CanUnburrow(time.Now()) {
+		if u.CanUnburrow(ls.game.Store.Balance(), time.Now()) {
 			screen.DrawImage(cutils.Images.Get(cutils.BuffBurrowedReadyKey), op)
 		} else {
 			screen.DrawImage(cutils.Images.Get(cutils.BuffBurrowedKey), op)
 		}
 	} else if u.HasBuff(buff.Resurrecting) {
 		screen.DrawImage(cutils.Images.Get(cutils.BuffResurrectingKey), op)
-	} else if u.HasAbility(ability.Attack) && len(u.Path) == 0 {
+	} else if u.HasAbility(ls.game.Store.Balance(), ability.Attack) && len(u.Path) == 0 {
 		if (u.AnimationCount/10)%2 == 0 {
 			screen.DrawImage(cutils.Images.Get(u.AttackKey()).SubImage(image.Rect(sx, 0, sx+u.W, u.H)).(*ebiten.Image), op)
 		} else {
@@ -216,6 +300,8 @@ func (ls *Lines) DrawUnit(screen *ebiten.Image, c *CameraStore, u *store.Unit) {
 		screen.DrawImage(sbpi.SubImage(image.Rect(0, 0, int(float64(sbpi.Bounds().Dx())*(u.Shield/u.MaxShield)), sbpi.Bounds().Dy())).(*ebiten.Image), op)
 	}
 
+	ls.DrawUnitEffects(screen, c, u)
+
 	// TODO: Animation logic
 	//if u.HasBuff(buff.Burrowoed) {
 	//i := (u.AnimationCount / 15) % 8
@@ -45,30 +45,35 @@ func (m *Map) Draw(screen *ebiten.Image) {
 
 	cs := m.game.Camera.GetState()
 	cp := m.game.Store.Game.FindCurrentPlayer()
-	x, y := m.game.Store.Map.GetHomeCoordinates(cp.LineID)
+	// When watching a replay there is no current player
+	if cp.ID == "" {
+		return
+	}
+	l := m.game.Store.Map.GetLine(cp.LineID)
+	x, y, w, h := l.X, l.Y, l.W, l.H
 	csX := int(cs.X)
 	csY := int(cs.Y)
 	// Color TOP and Bottom
-	for i := x - 4; i <= x+(18*16)+3; i++ {
+	for i := x - 4; i <= x+w+3; i++ {
 		// We draw 3 lines so it's kind of **bold**
 		// and it's easier to see
 		screen.Set(i-csX, y-csY-4, cutils.Green)
 		screen.Set(i-csX, y-csY-3, cutils.Green)
 		screen.Set(i-csX, y-csY-2, cutils.Green)
 
-		screen.Set(i-csX, (y+86*16)-csY+3, cutils.Green)
-		screen.Set(i-csX, (y+86*16)-csY+2, cutils.Green)
-		screen.Set(i-csX, (y+86*16)-csY+1, cutils.Green)
+		screen.Set(i-csX, (y+h)-csY+3, cutils.Green)
+		screen.Set(i-csX, (y+h)-csY+2, cutils.Green)
+		screen.Set(i-csX, (y+h)-csY+1, cutils.Green)
 	}
 
 	// Color Left and Right
-	for i := y - 1; i <= y+(86*16); i++ {
+	for i := y - 1; i <= y+h; i++ {
 		screen.Set(x-csX-4, i-csY, cutils.Green)
 		screen.Set(x-csX-3, i-csY, cutils.Green)
 		screen.Set(x-csX-2, i-csY, cutils.Green)
 
-		screen.Set((x+18*16)-csX+3, i-csY, cutils.Green)
-		screen.Set((x+18*16)-csX+2, i-csY, cutils.Green)
-		screen.Set((x+18*16)-csX+1, i-csY, cutils.Green)
+		screen.Set((x+w)-csX+3, i-csY, cutils.Green)
+		screen.Set((x+w)-csX+2, i-csY, cutils.Green)
+		screen.Set((x+w)-csX+1, i-csY, cutils.Green)
 	}
 }
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/coder/websocket"
-	"github.com/coder/websocket/wsjson"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/codec"
 )
 
 var (
@@ -18,7 +19,28 @@ var (
 	// all the actions have to be registered to it
 	actionDispatcher *ActionDispatcher
 
-	wsc *websocket.Conn
+	// wsMx guards wsc and resumeToken as the wsHandler
+	// changes them while the actions are being sent
+	wsMx sync.RWMutex
+	wsc  *websocket.Conn
+	// wsCodec is the codec.Codec negotiated
+	// with the server when signing in
+	wsCodec codec.Codec
+
+	// signInAction is the UserSignIn sent to
+	// the server, used to reconnect
+	signInAction *action.Action
+	// resumeToken is the token of the current Game
+	// used to resume it if the connection is lost
+	resumeToken string
+)
+
+const (
+	// reconnectInterval is the time between reconnection attempts
+	reconnectInterval = time.Second * 2
+	// reconnectAttempts is the number of attempts before giving up,
+	// it matches the time the server keeps the Game of the User
+	reconnectAttempts = 30
 )
 
 func init() {
@@ -40,8 +62,8 @@ func New(ctx context.Context, ad *ActionDispatcher, rs *RouterStore, opt Options
 		return fmt.Errorf("failed to RunGame: %w", err)
 	}
 
-	if wsc != nil {
-		wsc.CloseNow()
+	if c := getWSConn(); c != nil {
+		c.CloseNow()
 	}
 
 	return nil
@@ -50,9 +72,26 @@ func New(ctx context.Context, ad *ActionDispatcher, rs *RouterStore, opt Options
 func wsHandler(ctx context.Context) {
 	for {
 		var act *action.Action
-		err := wsjson.Read(ctx, wsc, &act)
+		err := codec.Read(ctx, getWSConn(), &act)
 		if err != nil {
-			log.Fatal(err, act)
+			if getResumeToken() == "" {
+				log.Fatal(err, act)
+			}
+			rerr := reconnect(ctx)
+			if rerr != nil {
+				log.Fatal(err, rerr)
+			}
+			continue
+		}
+
+		if act.Type == action.StartGame {
+			setResumeToken(act.StartGame.ResumeToken)
+			// The balance has to be the one of the
+			// server before the Game starts
+			err = actionDispatcher.loadBalance(act.StartGame.BalanceHash)
+			if err != nil {
+				log.Fatal(err)
+			}
 		}
 
 		actionDispatcher.Dispatch(act)
@@ -61,12 +100,91 @@ func wsHandler(ctx context.Context) {
 		if act.Type == action.StartGame {
 			actionDispatcher.GoHome()
 		}
+		// The server only sends the changes from
+		// the last state we acknowledged
+		if act.Type == action.SyncState {
+			actionDispatcher.AckSyncState()
+			// Once the Game has ended there
+			// is nothing left to resume
+			if hasWinner() {
+				setResumeToken("")
+			}
+		}
+	}
+}
+
+// reconnect dials again the server and signs in with the
+// resumeToken so the Game in progress is resumed
+func reconnect(ctx context.Context) error {
+	usia := *signInAction
+	usi := *signInAction.UserSignIn
+	usi.ResumeToken = getResumeToken()
+	usia.UserSignIn = &usi
+
+	var err error
+	for i := 0; i < reconnectAttempts; i++ {
+		time.Sleep(reconnectInterval)
+
+		var c *websocket.Conn
+		c, err = actionDispatcher.dialWS(ctx, &usia)
+		if err == nil {
+			setWSConn(c)
+			return nil
+		}
 	}
+	return fmt.Errorf("failed to reconnect: %w", err)
 }
 
 func wsSend(a *action.Action) {
-	err := wsjson.Write(context.Background(), wsc, a)
+	// Leaving the Game means it can not be resumed
+	if a.Type == action.RemovePlayer || a.Type == action.ExitSpectateGame {
+		setResumeToken("")
+	}
+	err := codec.Write(context.Background(), getWSConn(), wsCodec, a)
 	if err != nil {
+		// While reconnecting the actions are lost
+		if getResumeToken() != "" {
+			log.Println(err)
+			return
+		}
 		log.Fatal(err)
 	}
 }
+
+// hasWinner checks if any of the Players of the Game has won
+func hasWinner() bool {
+	for _, p := range actionDispatcher.store.Game.ListPlayers() {
+		if p.Winner {
+			return true
+		}
+	}
+	return false
+}
+
+func getWSConn() *websocket.Conn {
+	wsMx.RLock()
+	defer wsMx.RUnlock()
+
+	return wsc
+}
+
+func setWSConn(c *websocket.Conn) {
+	wsMx.Lock()
+	defer wsMx.Unlock()
+
+	wsc = c
+}
+
+func getResumeToken() string {
+	wsMx.RLock()
+	defer wsMx.RUnlock()
+
+	return resumeToken
+}
+
+func setResumeToken(t string) {
+	wsMx.Lock()
+	defer wsMx.Unlock()
+
+	resumeToken = t
+}
@@ -11,4 +11,8 @@ type Options struct {
 	ScreenW int
 	ScreenH int
 	Version string
+
+	// Codec is the name of the codec.Codec
+	// used to communicate with the server
+	Codec string
 }
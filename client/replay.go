@@ -0,0 +1,183 @@
+package client
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/xescugc/go-flux/v2"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/client/game"
+	cutils "github.com/xescugc/maze-wars/client/utils"
+	"github.com/xescugc/maze-wars/replay"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/utils"
+)
+
+const (
+	// replaySeekTicks is the amount of ticks
+	// moved when seeking (10s)
+	replaySeekTicks = 10 * 60
+
+	replayMinSpeed = 0.25
+	replayMaxSpeed = 8
+)
+
+// Replay plays a replay.Replay using the game drawing code.
+// It can be paused (Space), seek (Left/Right) and
+// change the speed (Up/Down)
+type Replay struct {
+	game   *game.Game
+	player *replay.Player
+
+	dispatcher *flux.Dispatcher[*action.Action]
+	ad         *game.ActionDispatcher
+
+	logger *slog.Logger
+
+	paused bool
+	speed  float64
+	// ticks is the amount of ticks pending to be
+	// played, needed for the speeds lower than 1
+	ticks float64
+}
+
+// NewReplay initializes a Replay of rp with the screen size w and h
+func NewReplay(rp replay.Replay, l *slog.Logger, w, h int) (*Replay, error) {
+	var err error
+
+	d := flux.NewDispatcher[*action.Action]()
+	p := replay.NewPlayer(rp, l)
+	// The Camera is the only one that listens to the Dispatcher
+	// as the Player has it's own one for the Game
+	ad := game.NewActionDispatcher(d, p.Store, func(a *action.Action) {}, l)
+
+	g := game.New(p.Store, ad, l)
+	g.Camera = game.NewCameraStore(d, p.Store, l, w, h)
+	g.Lines, err = game.NewLines(g)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Lines: %w", err)
+	}
+	g.Map = game.NewMap(g)
+
+	r := &Replay{
+		game:       g,
+		player:     p,
+		dispatcher: d,
+		ad:         ad,
+		logger:     l,
+		speed:      1,
+	}
+
+	// Applies the setup of the game
+	r.seek(0)
+	ad.GoHome()
+
+	return r, nil
+}
+
+// PlayReplay opens the window and plays the Replay r
+func PlayReplay(r *Replay, opt Options) error {
+	ebiten.SetWindowTitle("Maze Wars - Replay")
+	ebiten.SetWindowSize(opt.ScreenW*2, opt.ScreenH*2)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+
+	err := ebiten.RunGame(r)
+	if err != nil {
+		return fmt.Errorf("failed to RunGame: %w", err)
+	}
+
+	return nil
+}
+
+// seek moves the player to the tick and updates the
+// references to the Store as it may be a new one
+func (r *Replay) seek(tick int) {
+	if tick < 0 {
+		tick = 0
+	} else if tick > r.player.EndTick() {
+		tick = r.player.EndTick()
+	}
+	r.player.Seek(tick)
+	r.game.Store = r.player.Store
+	r.game.Camera.Store = r.player.Store
+}
+
+func (r *Replay) Update() error {
+	b := time.Now()
+	defer utils.LogTime(r.logger, b, "replay update")
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		r.paused = !r.paused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) && r.speed < replayMaxSpeed {
+		r.speed *= 2
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) && r.speed > replayMinSpeed {
+		r.speed /= 2
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		r.seek(r.player.Tick() + replaySeekTicks)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		r.seek(r.player.Tick() - replaySeekTicks)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF1) {
+		r.ad.GoHome()
+	}
+
+	if !r.paused && !r.player.Ended() {
+		r.ticks += r.speed
+		if r.ticks >= 1 {
+			r.seek(r.player.Tick() + int(r.ticks))
+			r.ticks -= float64(int(r.ticks))
+		}
+	}
+
+	x, y := ebiten.CursorPosition()
+	cs := r.game.Camera.GetState()
+	if int(cs.LastCursorPosition.X) != x || int(cs.LastCursorPosition.Y) != y || cs.MouseButtonMiddlePressed {
+		r.ad.CursorMove(x, y, ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle))
+	}
+
+	// The TPS is only for the Camera, the Game
+	// is moved by the Player
+	r.ad.TPS()
+
+	return nil
+}
+
+func (r *Replay) Draw(screen *ebiten.Image) {
+	b := time.Now()
+	defer utils.LogTime(r.logger, b, "replay draw")
+
+	r.game.Map.Draw(screen)
+	r.game.Camera.Draw(screen)
+	r.game.Lines.Draw(screen)
+
+	status := fmt.Sprintf("%s / %s  x%v",
+		cutils.FmtDuration(time.Duration(r.player.Tick())*store.TickDuration),
+		cutils.FmtDuration(time.Duration(r.player.EndTick())*store.TickDuration),
+		r.speed,
+	)
+	if r.paused {
+		status += "  (paused)"
+	}
+	status += "\nSpace: pause, Left/Right: seek, Up/Down: speed, F1: home"
+
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(10, 10)
+	op.LineSpacing = 20
+	text.Draw(screen, status, cutils.SmallFont, op)
+}
+
+func (r *Replay) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	cs := r.game.Camera.GetState()
+	if cs.W != outsideWidth || cs.H != outsideHeight {
+		r.ad.Dispatch(action.NewWindowResizing(outsideWidth, outsideHeight))
+	}
+	return outsideWidth, outsideHeight
+}
@@ -2,13 +2,17 @@ package client
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	stdimage "image"
 	"image/color"
 	"image/gif"
 	"log/slog"
 	"math"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ebitenui/ebitenui"
@@ -19,8 +23,10 @@ import (
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
 	"github.com/xescugc/maze-wars/assets"
+	"github.com/xescugc/maze-wars/balance"
 	cutils "github.com/xescugc/maze-wars/client/utils"
 	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/tower"
 	"github.com/xescugc/maze-wars/unit"
 	"github.com/xescugc/maze-wars/unit/ability"
 	"github.com/xescugc/maze-wars/utils"
@@ -49,6 +55,7 @@ type RootStore struct {
 	ui *ebitenui.UI
 
 	usernameTextW   *widget.Text
+	ratingTextW     *widget.Text
 	userImageGW     *widget.Graphic
 	playBtnW        *widget.Button
 	backToLobbyBtnW *widget.Button
@@ -67,6 +74,7 @@ type RootStore struct {
 	lobbiesC      *widget.Container
 	lobbiesTableW *widget.Container
 	lobbiesSC     *widget.ScrollContainer
+	lobbiesErrorW *widget.Text
 
 	showLobbyC              *widget.Container
 	showLobbyNameW          *widget.Text
@@ -78,9 +86,10 @@ type RootStore struct {
 	showLobbyStartBtnW      *widget.Button
 	showLobbyBotsC          *widget.Container
 
-	learnC           *widget.Container
-	newLobbyW        *widget.Window
-	modalBackgroundC *widget.Container
+	learnC            *widget.Container
+	newLobbyW         *widget.Window
+	newLobbyBalancesL *widget.List
+	modalBackgroundC  *widget.Container
 }
 
 type RootState struct {
@@ -91,6 +100,17 @@ type RootState struct {
 	FindGame *findGame
 
 	WaitingRoom *waitingRoom
+
+	// Games are the current Games that can be spectated
+	Games []*action.GamePayload
+
+	// Balances are the balance packs of
+	// the server that a lobby can use
+	Balances []string
+
+	// LobbyError is the error of the server
+	// when the lobby could not be created
+	LobbyError string
 }
 
 type findGame struct {
@@ -165,6 +185,7 @@ func (rs *RootStore) Draw(screen *ebiten.Image) {
 
 	rss := rs.GetState()
 	rs.usernameTextW.Label = rs.Store.Users.Username()
+	rs.ratingTextW.Label = ratingLabel(rs.Store.Users.GetState())
 	rs.userImageGW.Image = cutils.Images.Get(unit.Units[rs.Store.Users.ImageKey()].FacesetKey())
 	if rss.SetupGame {
 		rs.loadModal(rs.setupGameW)
@@ -195,12 +216,23 @@ func (rs *RootStore) Draw(screen *ebiten.Image) {
 			return lbs[i].ID > lbs[j].ID
 		})
 		if !rs.Store.Lobbies.Seen() {
-			rs.addLobbiesToTable(lbs)
+			rs.addLobbiesToTable(lbs, rss.Games)
 			actionDispatcher.SeenLobbies()
 		}
 
+		rs.lobbiesErrorW.Label = rss.LobbyError
+
 		switch rss.Route {
 		case utils.NewLobbyRoute:
+			// The first one is the default balance of the server
+			entries := []any{cutils.ListEntry{Text: "Default"}}
+			for _, b := range rss.Balances {
+				entries = append(entries, cutils.ListEntry{ID: b, Text: b})
+			}
+			if !cutils.EqualListEntries(entries, rs.newLobbyBalancesL.Entries()) {
+				rs.newLobbyBalancesL.SetEntries(entries)
+				rs.newLobbyBalancesL.SetSelectedEntry(entries[0])
+			}
 			rs.loadModal(rs.newLobbyW)
 		default:
 			rs.closeModal(rs.newLobbyW)
@@ -350,6 +382,14 @@ func (rs *RootStore) Reduce(state RootState, act *action.Action) RootState {
 		}
 	case action.ExitSearchingGame:
 		state.FindGame = nil
+	case action.AddGames:
+		state.Games = act.AddGames.Games
+	case action.AddBalances:
+		state.Balances = act.AddBalances.Balances
+	case action.AddError:
+		state.LobbyError = act.AddError.Error
+	case action.CreateLobby:
+		state.LobbyError = ""
 	case action.SyncWaitingRoom:
 		state.FindGame = nil
 		state.WaitingRoom = &waitingRoom{
@@ -362,6 +402,7 @@ func (rs *RootStore) Reduce(state RootState, act *action.Action) RootState {
 		state.WaitingRoom = nil
 		state.SetupGame = false
 	case action.NavigateTo:
+		state.LobbyError = ""
 		switch act.NavigateTo.Route {
 		case utils.HomeRoute, utils.LobbiesRoute, utils.LearnRoute, utils.NewLobbyRoute, utils.ShowLobbyRoute:
 			state.Route = act.NavigateTo.Route
@@ -736,16 +777,47 @@ func (rs *RootStore) profileUI() *widget.Container {
 		),
 	)
 
+	ratingTextW := widget.NewText(
+		widget.TextOpts.Text("", cutils.SmallFont, cutils.TextColor),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+	)
+
+	userInfoC := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(5),
+		)),
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Position: widget.RowLayoutPositionCenter,
+			}),
+		),
+	)
+	userInfoC.AddChild(
+		usernameTextW,
+		ratingTextW,
+	)
+
 	profileRC.AddChild(imageBtnC)
-	profileRC.AddChild(usernameTextW)
+	profileRC.AddChild(userInfoC)
 	profileC.AddChild(profileRC)
 
 	rs.usernameTextW = usernameTextW
+	rs.ratingTextW = ratingTextW
 	rs.userImageGW = imageBtnGraphicW
 
 	return profileC
 }
 
+// ratingLabel returns the Rating of the user with
+// the change of the last ranked Game if any
+func ratingLabel(us UserState) string {
+	if us.RatingChange == 0 {
+		return fmt.Sprintf("Rating: %d", us.Rating)
+	}
+	return fmt.Sprintf("Rating: %d (%+d)", us.Rating, us.RatingChange)
+}
+
 func (rs *RootStore) setupGameModal() {
 	btnPadding := widget.Insets{
 		Left:   30,
@@ -1473,6 +1545,18 @@ func (rs *RootStore) lobbiesUI() *widget.Container {
 	buttonsC.AddChild(refreshBtnW)
 	buttonsC.AddChild(newBtnW)
 
+	lobbiesErrorW := widget.NewText(
+		widget.TextOpts.Text("", cutils.NormalFont, cutils.Red),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+		widget.TextOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
+				HorizontalPosition: widget.AnchorLayoutPositionStart,
+				VerticalPosition:   widget.AnchorLayoutPositionCenter,
+			}),
+		),
+	)
+	rs.lobbiesErrorW = lobbiesErrorW
+
 	tableWrapperC := widget.NewContainer(
 		// the container will use an anchor layout to layout its single child widget
 		widget.ContainerOpts.Layout(widget.NewGridLayout(
@@ -1641,6 +1725,7 @@ func (rs *RootStore) lobbiesUI() *widget.Container {
 
 	tableWrapperC.AddChild(tableHeaderC)
 	tableWrapperC.AddChild(lobbiesTableC)
+	buttonsWrapperC.AddChild(lobbiesErrorW)
 	buttonsWrapperC.AddChild(buttonsC)
 	lobbiesC.AddChild(buttonsWrapperC)
 	lobbiesC.AddChild(tableWrapperC)
@@ -2396,77 +2481,95 @@ func (rs *RootStore) showLobbiesNotlink() *widget.Container {
 	return lobbyC
 }
 
-func (rs *RootStore) addLobbiesToTable(ls []*store.Lobby) {
+func (rs *RootStore) addLobbiesToTable(ls []*store.Lobby, gms []*action.GamePayload) {
 	rs.lobbiesTableW.RemoveChildren()
 	for _, l := range ls {
-		nameText := widget.NewText(
-			widget.TextOpts.Text(l.Name, cutils.NormalFont, cutils.TextColor),
-			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionStart),
-			widget.TextOpts.WidgetOpts(
-				widget.WidgetOpts.LayoutData(widget.GridLayoutData{
-					HorizontalPosition: widget.GridLayoutPositionStart,
-					VerticalPosition:   widget.GridLayoutPositionStart,
-				}),
-			),
-		)
-
-		playersText := widget.NewText(
-			widget.TextOpts.Text(fmt.Sprintf("%d/%d", len(l.Players), l.MaxPlayers), cutils.NormalFont, cutils.TextColor),
-			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionStart),
-			widget.TextOpts.WidgetOpts(
-				widget.WidgetOpts.LayoutData(widget.GridLayoutData{
-					HorizontalPosition: widget.GridLayoutPositionStart,
-					VerticalPosition:   widget.GridLayoutPositionStart,
-				}),
-			),
-		)
-		var lobbiesTableRowC *widget.Container
-		lobbiesTableRowC = widget.NewContainer(
-			// the container will use an anchor layout to layout its single child widget
-			widget.ContainerOpts.Layout(widget.NewGridLayout(
-				//Define number of columns in the grid
-				widget.GridLayoutOpts.Columns(2),
-				widget.GridLayoutOpts.Stretch([]bool{true, true}, []bool{false}),
-				widget.GridLayoutOpts.Padding(widget.Insets{
-					Left: 14,
-					Top:  5,
-				}),
-			)),
-			widget.ContainerOpts.WidgetOpts(
-				widget.WidgetOpts.LayoutData(widget.GridLayoutData{
-					HorizontalPosition: widget.GridLayoutPositionStart,
-					VerticalPosition:   widget.GridLayoutPositionStart,
-				}),
-				widget.WidgetOpts.MouseButtonPressedHandler(func(args *widget.WidgetMouseButtonPressedEventArgs) {
-					cl := rs.Store.Lobbies.FindCurrent()
-					if cl != nil {
-						// TODO: Return an error saying that "You already belong to a Lobby"
-						return
-					}
-					un := rs.Store.Users.Username()
-					actionDispatcher.JoinLobby(l.ID, un, !isBot)
-					actionDispatcher.SelectLobby(l.ID)
-					actionDispatcher.NavigateTo(utils.ShowLobbyRoute)
-				}),
-				widget.WidgetOpts.CursorMoveHandler(func(args *widget.WidgetCursorMoveEventArgs) {
-					// This is the HOVER button main color just with a half Alpha
-					lobbiesTableRowC.BackgroundImage = image.NewNineSliceColor(color.NRGBA{R: 254, G: 173, B: 84, A: 126})
-					nameText.Color = cutils.ButtonTextHoverColor
-					playersText.Color = cutils.ButtonTextHoverColor
-				}),
-				widget.WidgetOpts.CursorExitHandler(func(args *widget.WidgetCursorExitEventArgs) {
-					lobbiesTableRowC.BackgroundImage = nil
-					nameText.Color = cutils.ButtonTextIdleColor
-					playersText.Color = cutils.ButtonTextIdleColor
-				}),
-			),
-		)
-		lobbiesTableRowC.AddChild(nameText)
-		lobbiesTableRowC.AddChild(playersText)
-		rs.lobbiesTableW.AddChild(lobbiesTableRowC)
+		rs.addLobbiesTableRow(l.Name, fmt.Sprintf("%d/%d", len(l.Players), l.MaxPlayers), func() {
+			cl := rs.Store.Lobbies.FindCurrent()
+			if cl != nil {
+				// TODO: Return an error saying that "You already belong to a Lobby"
+				return
+			}
+			un := rs.Store.Users.Username()
+			actionDispatcher.JoinLobby(l.ID, un, !isBot)
+			actionDispatcher.SelectLobby(l.ID)
+			actionDispatcher.NavigateTo(utils.ShowLobbyRoute)
+		})
+	}
+	// The Games already started can only be spectated
+	for _, g := range gms {
+		rs.addLobbiesTableRow(strings.Join(g.Players, " vs "), "LIVE", func() {
+			cl := rs.Store.Lobbies.FindCurrent()
+			if cl != nil {
+				return
+			}
+			actionDispatcher.SpectateGame(rs.Store.Users.Username(), g.ID)
+		})
 	}
 }
 
+// addLobbiesTableRow adds a new row to the lobbies table with the name and info
+// columns that calls onClick when clicked
+func (rs *RootStore) addLobbiesTableRow(name, info string, onClick func()) {
+	nameText := widget.NewText(
+		widget.TextOpts.Text(name, cutils.NormalFont, cutils.TextColor),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionStart),
+		widget.TextOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.GridLayoutData{
+				HorizontalPosition: widget.GridLayoutPositionStart,
+				VerticalPosition:   widget.GridLayoutPositionStart,
+			}),
+		),
+	)
+
+	infoText := widget.NewText(
+		widget.TextOpts.Text(info, cutils.NormalFont, cutils.TextColor),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionStart),
+		widget.TextOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.GridLayoutData{
+				HorizontalPosition: widget.GridLayoutPositionStart,
+				VerticalPosition:   widget.GridLayoutPositionStart,
+			}),
+		),
+	)
+	var lobbiesTableRowC *widget.Container
+	lobbiesTableRowC = widget.NewContainer(
+		// the container will use an anchor layout to layout its single child widget
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			//Define number of columns in the grid
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{true, true}, []bool{false}),
+			widget.GridLayoutOpts.Padding(widget.Insets{
+				Left: 14,
+				Top:  5,
+			}),
+		)),
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.GridLayoutData{
+				HorizontalPosition: widget.GridLayoutPositionStart,
+				VerticalPosition:   widget.GridLayoutPositionStart,
+			}),
+			widget.WidgetOpts.MouseButtonPressedHandler(func(args *widget.WidgetMouseButtonPressedEventArgs) {
+				onClick()
+			}),
+			widget.WidgetOpts.CursorMoveHandler(func(args *widget.WidgetCursorMoveEventArgs) {
+				// This is the HOVER button main color just with a half Alpha
+				lobbiesTableRowC.BackgroundImage = image.NewNineSliceColor(color.NRGBA{R: 254, G: 173, B: 84, A: 126})
+				nameText.Color = cutils.ButtonTextHoverColor
+				infoText.Color = cutils.ButtonTextHoverColor
+			}),
+			widget.WidgetOpts.CursorExitHandler(func(args *widget.WidgetCursorExitEventArgs) {
+				lobbiesTableRowC.BackgroundImage = nil
+				nameText.Color = cutils.ButtonTextIdleColor
+				infoText.Color = cutils.ButtonTextIdleColor
+			}),
+		),
+	)
+	lobbiesTableRowC.AddChild(nameText)
+	lobbiesTableRowC.AddChild(infoText)
+	rs.lobbiesTableW.AddChild(lobbiesTableRowC)
+}
+
 func (rs *RootStore) newLobbyModal() {
 	frameC := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewRowLayout(
@@ -2499,7 +2602,7 @@ func (rs *RootStore) newLobbyModal() {
 			}),
 			//Define how to stretch the rows and columns. Note it is required to
 			//specify the Stretch for each row and column.
-			widget.GridLayoutOpts.Stretch([]bool{true}, []bool{false, false}),
+			widget.GridLayoutOpts.Stretch([]bool{true}, []bool{false, false, false}),
 		)),
 		widget.ContainerOpts.WidgetOpts(
 			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
@@ -2582,6 +2685,62 @@ func (rs *RootStore) newLobbyModal() {
 		}),
 	)
 
+	balanceLabelW := widget.NewText(
+		widget.TextOpts.Text("Balance", cutils.NormalFont, cutils.TextColor),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+		widget.TextOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Position: widget.RowLayoutPositionStart,
+				Stretch:  true,
+			}),
+		),
+	)
+
+	// balancesListW are the balance packs of the server, the
+	// entries are set from the /balances when it's opened and
+	// the ID of the selected one is the name of the pack
+	balancesListW := widget.NewList(
+		widget.ListOpts.ContainerOpts(widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Position: widget.RowLayoutPositionStart,
+				Stretch:  true,
+			}),
+			widget.WidgetOpts.MinSize(0, 100),
+		)),
+		widget.ListOpts.Entries([]any{cutils.ListEntry{Text: "Default"}}),
+		widget.ListOpts.ScrollContainerOpts(
+			widget.ScrollContainerOpts.Image(&widget.ScrollContainerImage{
+				Idle: cutils.ImageToNineSlice(cutils.GrayInputBGKey),
+				Mask: image.NewNineSliceColor(cutils.Black),
+			}),
+		),
+		widget.ListOpts.SliderOpts(
+			widget.SliderOpts.Images(
+				&widget.SliderTrackImage{
+					Idle:  image.NewNineSliceColor(cutils.Transparent),
+					Hover: image.NewNineSliceColor(cutils.Transparent),
+				},
+				&widget.ButtonImage{
+					Idle:    image.NewNineSliceColor(cutils.TableBlack),
+					Pressed: image.NewNineSliceColor(cutils.TableBlack),
+				},
+			),
+		),
+		widget.ListOpts.HideHorizontalSlider(),
+		widget.ListOpts.EntryFontFace(cutils.SmallFont),
+		widget.ListOpts.EntryColor(&widget.ListEntryColor{
+			Selected:   cutils.ButtonTextPressedColor,
+			Unselected: cutils.TextColor,
+		}),
+		widget.ListOpts.EntryLabelFunc(func(e interface{}) string {
+			return e.(cutils.ListEntry).Text
+		}),
+		widget.ListOpts.EntryTextPadding(widget.NewInsetsSimple(5)),
+		widget.ListOpts.EntryTextPosition(widget.TextPositionStart, widget.TextPositionCenter),
+	)
+	balancesListW.SetSelectedEntry(balancesListW.Entries()[0])
+	rs.newLobbyBalancesL = balancesListW
+
 	playersLabelW := widget.NewText(
 		widget.TextOpts.Text("Players", cutils.NormalFont, cutils.TextColor),
 		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
@@ -2634,6 +2793,77 @@ func (rs *RootStore) newLobbyModal() {
 		}),
 	)
 
+	rulesRC := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(4),
+			widget.GridLayoutOpts.Spacing(10, 5),
+			widget.GridLayoutOpts.Stretch([]bool{false, true, false, true}, nil),
+		)),
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.GridLayoutData{
+				HorizontalPosition: widget.GridLayoutPositionStart,
+				VerticalPosition:   widget.GridLayoutPositionStart,
+			}),
+		),
+	)
+
+	// The rules left empty are the
+	// ones of the Balance of the Game
+	goldInputW := newRuleInputW("Default")
+	incomeInputW := newRuleInputW("Default")
+	livesInputW := newRuleInputW("Default")
+	incomeTimerInputW := newRuleInputW("Default")
+	capacityInputW := newRuleInputW("Default")
+	suddenDeathInputW := newRuleInputW("Disabled")
+
+	for _, r := range []struct {
+		label string
+		input *widget.TextInput
+	}{
+		{"Gold", goldInputW},
+		{"Income", incomeInputW},
+		{"Lives", livesInputW},
+		{"Income timer (s)", incomeTimerInputW},
+		{"Capacity", capacityInputW},
+		{"Sudden death (s)", suddenDeathInputW},
+	} {
+		rulesRC.AddChild(widget.NewText(
+			widget.TextOpts.Text(r.label, cutils.SmallFont, cutils.TextColor),
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+		))
+		rulesRC.AddChild(r.input)
+	}
+
+	// The Units and Towers that can be used on the Game,
+	// all of them are allowed by default
+	unitTypes := unit.TypeStrings()
+	unitNames := make([]string, 0, len(unitTypes))
+	for _, ut := range unitTypes {
+		unitNames = append(unitNames, unit.Units[ut].Name())
+	}
+	unitsC, unitsCBs := newRuleCheckboxesW("Units", unitTypes, unitNames)
+
+	towerTypes := make([]string, 0, len(tower.Towers))
+	for tt := range tower.Towers {
+		towerTypes = append(towerTypes, tt)
+	}
+	sort.Strings(towerTypes)
+	towerNames := make([]string, 0, len(towerTypes))
+	for _, tt := range towerTypes {
+		towerNames = append(towerNames, tower.Towers[tt].Name)
+	}
+	towersC, towersCBs := newRuleCheckboxesW("Towers", towerTypes, towerNames)
+
+	newLobbyErrorW := widget.NewText(
+		widget.TextOpts.Text("", cutils.NormalFont, cutils.Red),
+		widget.TextOpts.Position(widget.TextPositionCenter, widget.TextPositionCenter),
+		widget.TextOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Position: widget.RowLayoutPositionCenter,
+			}),
+		),
+	)
+
 	createLobbyBtnW = widget.NewButton(
 		// set general widget options
 		widget.ButtonOpts.WidgetOpts(
@@ -2660,7 +2890,29 @@ func (rs *RootStore) newLobbyModal() {
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
 			lid := uuid.Must(uuid.NewV4()).String()
 
-			actionDispatcher.CreateLobby(lid, rs.Store.Users.Username(), nameInputW.GetText(), playersSliderW.Current)
+			gr := action.GameRules{
+				Gold:        atoi(goldInputW.GetText()),
+				Income:      atoi(incomeInputW.GetText()),
+				Lives:       atoi(livesInputW.GetText()),
+				IncomeTimer: atoi(incomeTimerInputW.GetText()),
+				MaxCapacity: atoi(capacityInputW.GetText()),
+				SuddenDeath: atoi(suddenDeathInputW.GetText()),
+				Units:       checkedList(unitTypes, unitsCBs),
+				Towers:      checkedList(towerTypes, towersCBs),
+			}
+			err := validateRules(gr)
+			if err != nil {
+				newLobbyErrorW.Label = err.Error()
+				return
+			}
+			newLobbyErrorW.Label = ""
+
+			var lb string
+			if e, ok := balancesListW.SelectedEntry().(cutils.ListEntry); ok {
+				lb = e.ID
+			}
+
+			actionDispatcher.CreateLobby(lid, rs.Store.Users.Username(), nameInputW.GetText(), playersSliderW.Current, lb, gr)
 			actionDispatcher.SelectLobby(lid)
 			rs.newLobbyW.Close()
 		}),
@@ -2675,15 +2927,21 @@ func (rs *RootStore) newLobbyModal() {
 
 	nameRC.AddChild(nameLabelW)
 	nameRC.AddChild(nameInputW)
+	nameRC.AddChild(balanceLabelW)
+	nameRC.AddChild(balancesListW)
 
 	playersRC.AddChild(playersLabelW)
 	playersRC.AddChild(playersSliderW)
 
 	formC.AddChild(nameRC)
 	formC.AddChild(playersRC)
+	formC.AddChild(rulesRC)
+	formC.AddChild(unitsC)
+	formC.AddChild(towersC)
 
 	frameC.AddChild(titleW)
 	frameC.AddChild(formC)
+	frameC.AddChild(newLobbyErrorW)
 	frameC.AddChild(createLobbyBtnW)
 
 	window := widget.NewWindow(
@@ -2704,6 +2962,131 @@ func (rs *RootStore) newLobbyModal() {
 	rs.newLobbyW = window
 }
 
+// newRuleInputW returns the input of a numeric rule
+// of the new lobby, only digits can be written on it
+func newRuleInputW(placeholder string) *widget.TextInput {
+	return widget.NewTextInput(
+		widget.TextInputOpts.Image(cutils.TextInputResource()),
+		widget.TextInputOpts.Face(cutils.SmallFont),
+		widget.TextInputOpts.Color(cutils.TextInputColor()),
+		widget.TextInputOpts.Padding(widget.NewInsetsSimple(5)),
+		widget.TextInputOpts.CaretOpts(
+			widget.CaretOpts.Size(cutils.SmallFont, 2),
+		),
+		widget.TextInputOpts.Placeholder(placeholder),
+		widget.TextInputOpts.Validation(func(t string) (bool, *string) {
+			for _, r := range t {
+				if r < '0' || r > '9' {
+					return false, nil
+				}
+			}
+			return true, nil
+		}),
+	)
+}
+
+// newRuleCheckboxesW returns the container with the label and a checked
+// checkbox with the name of each of the types, in the same order
+func newRuleCheckboxesW(label string, types, names []string) (*widget.Container, []*widget.Button) {
+	ruleC := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(5),
+		)),
+		widget.ContainerOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.GridLayoutData{
+				HorizontalPosition: widget.GridLayoutPositionStart,
+				VerticalPosition:   widget.GridLayoutPositionStart,
+			}),
+		),
+	)
+	ruleC.AddChild(widget.NewText(
+		widget.TextOpts.Text(label, cutils.NormalFont, cutils.TextColor),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+	))
+
+	checkboxesC := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(6),
+			widget.GridLayoutOpts.Spacing(10, 5),
+		)),
+	)
+	cbs := make([]*widget.Button, 0, len(types))
+	for i := range types {
+		cbC := widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout(
+				widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+				widget.RowLayoutOpts.Spacing(5),
+			)),
+		)
+		cbW := widget.NewButton(
+			widget.ButtonOpts.Image(cutils.CheckboxButtonResource()),
+			widget.ButtonOpts.ToggleMode(),
+			widget.ButtonOpts.WidgetOpts(
+				widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+					Position: widget.RowLayoutPositionCenter,
+				}),
+			),
+		)
+		cbW.SetState(widget.WidgetChecked)
+		cbC.AddChild(cbW)
+		cbC.AddChild(widget.NewText(
+			widget.TextOpts.Text(names[i], cutils.SmallFont, cutils.TextColor),
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+			widget.TextOpts.WidgetOpts(
+				widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+					Position: widget.RowLayoutPositionCenter,
+				}),
+			),
+		))
+		checkboxesC.AddChild(cbC)
+		cbs = append(cbs, cbW)
+	}
+	ruleC.AddChild(checkboxesC)
+
+	return ruleC, cbs
+}
+
+// checkedList returns the types of the checked cbs, which are in the
+// same order, or nil if all of them are checked as it allows all
+func checkedList(types []string, cbs []*widget.Button) []string {
+	res := make([]string, 0, len(cbs))
+	for i, cb := range cbs {
+		if cb.State() == widget.WidgetChecked {
+			res = append(res, types[i])
+		}
+	}
+	if len(res) == len(types) {
+		return nil
+	}
+	return res
+}
+
+// validateRules checks the GameRules gr from the checkedList like the
+// server does, so the error is shown before creating the lobby
+func validateRules(gr action.GameRules) error {
+	// The nil list allows all of them but
+	// the empty one has none checked
+	if gr.Units != nil && len(gr.Units) == 0 {
+		return errors.New("At least one unit has to be allowed")
+	}
+	if gr.Towers == nil {
+		return nil
+	}
+	for _, tt := range gr.Towers {
+		if slices.Contains(tower.FirstTowers, tower.Towers[tt]) {
+			return nil
+		}
+	}
+	return errors.New("At least one of the first towers has to be allowed")
+}
+
+// atoi converts s to int, if it's not a number it's 0
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
 func (rs *RootStore) learnUI() *widget.Container {
 	learnC := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewGridLayout(
@@ -2943,7 +3326,7 @@ the players on the current game.
 	for _, t := range unit.TypeStrings() {
 		aux := t
 		u := unit.Units[aux]
-		uu := store.CalculateUnitUpdate(aux, store.UnitUpdate{}, 1)
+		uu := store.CalculateUnitUpdate(balance.Default, aux, store.UnitUpdate{}, 1)
 		imageBtnC := widget.NewContainer(
 			widget.ContainerOpts.Layout(widget.NewStackedLayout()),
 			widget.ContainerOpts.WidgetOpts(
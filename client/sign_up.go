@@ -26,6 +26,7 @@ type SignUpStore struct {
 	ui         *ebitenui.UI
 	textErrorW *widget.Text
 	inputW     *widget.TextInput
+	passwordW  *widget.TextInput
 	buttonW    *widget.Button
 	imageG     *widget.Graphic
 }
@@ -37,9 +38,13 @@ type SignUpState struct {
 
 	ImageKey string
 	Username string
+
+	// Token is the Session token of the
+	// Username from the last sign in
+	Token string
 }
 
-func NewSignUpStore(d *flux.Dispatcher[*action.Action], s *store.Store, un, ik string, l *slog.Logger) (*SignUpStore, error) {
+func NewSignUpStore(d *flux.Dispatcher[*action.Action], s *store.Store, un, ik, tk string, l *slog.Logger) (*SignUpStore, error) {
 	su := &SignUpStore{
 		Store:  s,
 		Logger: l,
@@ -50,6 +55,7 @@ func NewSignUpStore(d *flux.Dispatcher[*action.Action], s *store.Store, un, ik s
 	su.ReduceStore = flux.NewReduceStore(d, su.Reduce, SignUpState{
 		ImageKey: ik,
 		Username: un,
+		Token:    tk,
 	})
 
 	su.buildUI()
@@ -79,6 +85,7 @@ func (su *SignUpStore) Draw(screen *ebiten.Image) {
 		su.textErrorW.GetWidget().Visibility = widget.Visibility_Show
 		su.textErrorW.Label = sutate.VersionError
 		su.inputW.GetWidget().Disabled = true
+		su.passwordW.GetWidget().Disabled = true
 		su.buttonW.GetWidget().Disabled = true
 	}
 	su.imageG.Image = cutils.Images.Get(unit.Units[sutate.ImageKey].ProfileKey())
@@ -365,6 +372,29 @@ func (su *SignUpStore) signUpFormUI() *widget.Container {
 	)
 	nameInputW.SetText(ss.Username)
 
+	passwordInputW := widget.NewTextInput(
+		widget.TextInputOpts.WidgetOpts(
+			widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+				Position: widget.RowLayoutPositionCenter,
+				Stretch:  true,
+			}),
+		),
+		widget.TextInputOpts.Image(cutils.TextInputResource()),
+		widget.TextInputOpts.Face(cutils.SmallFont),
+		widget.TextInputOpts.Color(cutils.TextInputColor()),
+		widget.TextInputOpts.Padding(widget.NewInsetsSimple(5)),
+		widget.TextInputOpts.CaretOpts(
+			widget.CaretOpts.Size(cutils.SmallFont, 2),
+		),
+		widget.TextInputOpts.Secure(true),
+		// Without password the Account can only be
+		// used from this computer with the Token
+		widget.TextInputOpts.Placeholder("Password (optional)"),
+		widget.TextInputOpts.SubmitHandler(func(args *widget.TextInputChangedEventArgs) {
+			enterBtnW.Click()
+		}),
+	)
+
 	enterBtnW = widget.NewButton(
 		// set general widget options
 		widget.ButtonOpts.WidgetOpts(
@@ -390,7 +420,14 @@ func (su *SignUpStore) signUpFormUI() *widget.Container {
 		// add a handler that reacts to clicking the button
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
 			ss := su.GetState()
-			actionDispatcher.SignUpSubmit(nameInputW.GetText(), ss.ImageKey)
+			un := nameInputW.GetText()
+			// The Token is only valid for the
+			// User that was signed in before
+			var tk string
+			if un == ss.Username {
+				tk = ss.Token
+			}
+			actionDispatcher.SignUpSubmit(un, passwordInputW.GetText(), ss.ImageKey, tk)
 		}),
 
 		cutils.BigButtonOptsPressedText,
@@ -404,12 +441,14 @@ func (su *SignUpStore) signUpFormUI() *widget.Container {
 	frameC.AddChild(
 		imageProfileC,
 		nameInputW,
+		passwordInputW,
 		enterBtnW,
 	)
 
 	signUpFormC.AddChild(frameC)
 
 	su.inputW = nameInputW
+	su.passwordW = passwordInputW
 	su.buttonW = enterBtnW
 	su.imageG = imageGraphicW
 
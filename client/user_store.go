@@ -12,6 +12,11 @@ type UserStore struct {
 type UserState struct {
 	Username string
 	ImageKey string
+
+	Rating int
+	// RatingChange is the change of the
+	// Rating on the last ranked Game
+	RatingChange int
 }
 
 func NewUserStore(d *flux.Dispatcher[*action.Action]) *UserStore {
@@ -23,12 +28,16 @@ func NewUserStore(d *flux.Dispatcher[*action.Action]) *UserStore {
 
 func (us *UserStore) Username() string { return us.GetState().Username }
 func (us *UserStore) ImageKey() string { return us.GetState().ImageKey }
+func (us *UserStore) Rating() int      { return us.GetState().Rating }
 
 func (u *UserStore) Reduce(state UserState, act *action.Action) UserState {
 	switch act.Type {
 	case action.UserSignIn:
 		state.Username = act.UserSignIn.Username
 		state.ImageKey = act.UserSignIn.ImageKey
+	case action.SyncUser:
+		state.Rating = act.SyncUser.Rating
+		state.RatingChange = act.SyncUser.RatingChange
 	}
 
 	return state
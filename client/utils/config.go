@@ -4,4 +4,8 @@ package utils
 type Config struct {
 	Username string `json:"username"`
 	ImageKey string `json:"image_key"`
+
+	// Token is the Session token used to
+	// sign in without password
+	Token string `json:"token"`
 }
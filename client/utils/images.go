@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"image"
+	"io/fs"
 	"log"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/xescugc/maze-wars/assets"
 	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/tiled"
 	"github.com/xescugc/maze-wars/tower"
 	"github.com/xescugc/maze-wars/unit"
 	"github.com/xescugc/maze-wars/unit/ability"
@@ -165,8 +168,12 @@ func init() {
 		Images.images[t.IdleKey()] = ebiten.NewImageFromImage(t.Idle)
 		Images.images[t.ProfileKey()] = ebiten.NewImageFromImage(t.Profile)
 	}
-	for i, m := range store.MapImages {
-		Images.images[fmt.Sprintf(store.MapImageKeyFmt, i)] = ebiten.NewImageFromImage(m)
+	for i, m := range tiled.Maps {
+		mi, err := m.Image(openTileset)
+		if err != nil {
+			panic(err)
+		}
+		Images.images[fmt.Sprintf(store.MapImageKeyFmt, i)] = ebiten.NewImageFromImage(mi)
 	}
 
 	tli, _, err := image.Decode(bytes.NewReader(assets.TilesetLogic_png))
@@ -316,3 +323,14 @@ func init() {
 	}
 
 }
+
+// openTileset opens the image of the tiled.Tileset from the
+// assets.Tilesets, the p is relative to the tiled/ directory
+func openTileset(p string) (image.Image, error) {
+	b, err := fs.ReadFile(assets.Tilesets, strings.TrimPrefix(p, "../assets/"))
+	if err != nil {
+		return nil, err
+	}
+	i, _, err := image.Decode(bytes.NewReader(b))
+	return i, err
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/xescugc/maze-wars/action"
 	"github.com/xescugc/maze-wars/client"
 	"github.com/xescugc/maze-wars/client/game"
+	"github.com/xescugc/maze-wars/codec"
 	"github.com/xescugc/maze-wars/store"
 )
 
@@ -36,6 +37,9 @@ func NewClient() js.Func {
 
 				HostURL: client.Host,
 				Version: client.Version,
+
+				// The browser is easier to debug with JSON
+				Codec: codec.JSON,
 			}
 		)
 
@@ -70,7 +74,7 @@ func NewClient() js.Func {
 			return fmt.Errorf("failed to initialize RootStore: %w", err)
 		}
 
-		u, err := client.NewSignUpStore(d, s, "", "", l)
+		u, err := client.NewSignUpStore(d, s, "", "", "", l)
 		if err != nil {
 			return fmt.Errorf("failed to initial SignUpStore: %w", err)
 		}
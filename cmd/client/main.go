@@ -18,6 +18,7 @@ import (
 	"github.com/xescugc/maze-wars/client"
 	"github.com/xescugc/maze-wars/client/game"
 	cutils "github.com/xescugc/maze-wars/client/utils"
+	"github.com/xescugc/maze-wars/codec"
 	"github.com/xescugc/maze-wars/store"
 )
 
@@ -35,6 +36,7 @@ var (
 	screenW int
 	screenH int
 	verbose bool
+	wsCodec string
 
 	clientCmd = &cobra.Command{
 		Use: "client",
@@ -45,6 +47,7 @@ var (
 				ScreenH: screenH,
 				Version: client.Version,
 				HostURL: client.Host,
+				Codec:   wsCodec,
 			}
 
 			configFilePath, err := xdg.ConfigFile("maze-wars/user.json")
@@ -107,7 +110,7 @@ var (
 				return fmt.Errorf("failed to initialize RootStore: %w", err)
 			}
 
-			su, err := client.NewSignUpStore(d, s, cfg.Username, cfg.ImageKey, l)
+			su, err := client.NewSignUpStore(d, s, cfg.Username, cfg.ImageKey, cfg.Token, l)
 			if err != nil {
 				return fmt.Errorf("failed to initial SignUpStore: %w", err)
 			}
@@ -126,9 +129,11 @@ func init() {
 	clientCmd.Flags().StringVar(&hostURL, "host", client.Host, "The URL of the server")
 	clientCmd.Flags().IntVar(&screenW, "screenw", 550, "The default width of the screen when not full screen")
 	clientCmd.Flags().IntVar(&screenH, "screenh", 500, "The default height of the screen when not full screen")
+	clientCmd.Flags().StringVar(&wsCodec, "codec", codec.CBOR, fmt.Sprintf("The codec used to communicate with the server, %q or %q", codec.CBOR, codec.JSON))
 	clientCmd.Flags().BoolVar(&verbose, "verbose", false, fmt.Sprintf("If all the logs are gonna be printed to %s", logFile))
 
 	clientCmd.AddCommand(versionCmd)
+	clientCmd.AddCommand(replayCmd)
 }
 
 func main() {
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/xescugc/maze-wars/client"
+	"github.com/xescugc/maze-wars/replay"
+)
+
+var (
+	replayCmd = &cobra.Command{
+		Use:   "replay FILE",
+		Short: "Plays a replay of a game",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open replay: %w", err)
+			}
+			defer f.Close()
+
+			rp, err := replay.Read(f)
+			if err != nil {
+				return err
+			}
+
+			l := slog.New(slog.NewTextHandler(io.Discard, nil))
+			r, err := client.NewReplay(rp, l, screenW, screenH)
+			if err != nil {
+				return fmt.Errorf("failed to initialize Replay: %w", err)
+			}
+
+			return client.PlayReplay(r, client.Options{
+				ScreenW: screenW,
+				ScreenH: screenH,
+				Version: client.Version,
+			})
+		},
+	}
+)
@@ -17,6 +17,9 @@ import (
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
 	"github.com/xescugc/maze-wars/server"
+	"github.com/xescugc/maze-wars/server/storage"
+	"github.com/xescugc/maze-wars/server/storage/memory"
+	"github.com/xescugc/maze-wars/server/storage/sqlite"
 )
 
 var (
@@ -31,6 +34,8 @@ var (
 				Version:          server.Version,
 				DiscordBotToken:  viper.GetString("discord-bot-token"),
 				DiscordChannelID: viper.GetString("discord-channel-id"),
+				ReplaysDir:       viper.GetString("replays-dir"),
+				DBPath:           viper.GetString("db-path"),
 			}
 			d := flux.NewDispatcher[*action.Action]()
 
@@ -71,8 +76,22 @@ var (
 				defer dgo.Close()
 			}
 
+			var st storage.Storage = memory.New()
+			if opt.DBPath != "" {
+				st, err = sqlite.New(opt.DBPath)
+				if err != nil {
+					return err
+				}
+			}
+			defer st.Close()
+
+			opt.Balances, err = server.LoadBalances(viper.GetString("balance"))
+			if err != nil {
+				return err
+			}
+
 			ws := server.NewWS()
-			ss := server.NewStore(d, ws, dgo, opt, l)
+			ss := server.NewStore(d, ws, st, dgo, opt, l)
 			ad := server.NewActionDispatcher(d, l, ss, ws)
 
 			err = server.New(ad, ss, opt)
@@ -98,6 +117,15 @@ func init() {
 	serverCmd.Flags().String("discord-channel-id", server.DiscordChannelID, "The ID for the Channel to send messages")
 	viper.BindPFlag("discord-channel-id", serverCmd.Flags().Lookup("discord-channel-id"))
 
+	serverCmd.Flags().String("replays-dir", "", "The directory in which the replays of the games are stored, if empty no replays are stored")
+	viper.BindPFlag("replays-dir", serverCmd.Flags().Lookup("replays-dir"))
+
+	serverCmd.Flags().String("db-path", "", "The path to the SQLite database in which the accounts are stored, if empty they are only kept in memory")
+	viper.BindPFlag("db-path", serverCmd.Flags().Lookup("db-path"))
+
+	serverCmd.Flags().String("balance", "", "The balance pack file used on all the games or a directory of packs that the lobbies can pick, if empty the default balance is used")
+	viper.BindPFlag("balance", serverCmd.Flags().Lookup("balance"))
+
 	serverCmd.Flags().Bool("verbose", false, fmt.Sprintf("If all the logs are gonna be printed to %s", logFile))
 	viper.BindPFlag("verbose", serverCmd.Flags().Lookup("verbose"))
 
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/server/bot"
+	"github.com/xescugc/maze-wars/simulate"
+)
+
+var (
+	simulateCmd = &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulates Games between Bots and prints the stats of each one",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var strategies []bot.Strategy
+			for _, s := range strings.Split(viper.GetString("strategies"), ",") {
+				st := bot.Strategy(strings.TrimSpace(s))
+				if !isStrategy(st) {
+					return fmt.Errorf("unknown strategy %q, the available ones are %v", st, bot.Strategies)
+				}
+				strategies = append(strategies, st)
+			}
+
+			out := io.Writer(os.Stdout)
+			if o := viper.GetString("output"); o != "" {
+				f, err := os.Create(o)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+
+			w, err := simulate.NewWriter(out, viper.GetString("format"))
+			if err != nil {
+				return err
+			}
+
+			var lout io.Writer = io.Discard
+			if viper.GetBool("verbose") {
+				lout = os.Stderr
+			}
+			l := slog.New(slog.NewTextHandler(lout, nil))
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			var bl *balance.Balance
+			if p := viper.GetString("balance"); p != "" {
+				bls, err := balance.Load(p)
+				if err != nil {
+					return err
+				}
+				if len(bls) != 1 {
+					return fmt.Errorf("the balance %q has to be a file with one pack", p)
+				}
+				bl = bls[0]
+			}
+
+			seed := viper.GetInt64("seed")
+			for i := 0; i < viper.GetInt("games"); i++ {
+				res, err := simulate.Run(ctx, simulate.Options{
+					Strategies:  strategies,
+					Seed:        seed + int64(i),
+					MaxDuration: viper.GetDuration("max-duration"),
+					Balance:     bl,
+				}, l)
+				if err != nil {
+					return fmt.Errorf("failed to simulate game %d: %w", i+1, err)
+				}
+				res.Game = i + 1
+
+				err = w.Write(res)
+				if err != nil {
+					return fmt.Errorf("failed to write game %d: %w", i+1, err)
+				}
+			}
+
+			return w.Flush()
+		},
+	}
+)
+
+func isStrategy(st bot.Strategy) bool {
+	for _, s := range bot.Strategies {
+		if s == st {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	viper.AutomaticEnv()
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+
+	simulateCmd.Flags().Int("games", 10, "The number of games to simulate")
+	viper.BindPFlag("games", simulateCmd.Flags().Lookup("games"))
+
+	simulateCmd.Flags().String("strategies", "random,random", fmt.Sprintf("The strategy of each bot separated by comma, one per player. Available: %v", bot.Strategies))
+	viper.BindPFlag("strategies", simulateCmd.Flags().Lookup("strategies"))
+
+	simulateCmd.Flags().Int64("seed", time.Now().UnixNano(), "The seed of the first game, each next game uses the next one")
+	viper.BindPFlag("seed", simulateCmd.Flags().Lookup("seed"))
+
+	simulateCmd.Flags().Duration("max-duration", time.Hour, "The maximum game time of each game, after it the game ends without winner")
+	viper.BindPFlag("max-duration", simulateCmd.Flags().Lookup("max-duration"))
+
+	simulateCmd.Flags().String("balance", "", "The balance pack file the games are simulated with, if empty the default balance is used")
+	viper.BindPFlag("balance", simulateCmd.Flags().Lookup("balance"))
+
+	simulateCmd.Flags().String("format", simulate.JSON, fmt.Sprintf("The format of the stats, %q or %q", simulate.JSON, simulate.CSV))
+	viper.BindPFlag("format", simulateCmd.Flags().Lookup("format"))
+
+	simulateCmd.Flags().String("output", "", "The file in which the stats are written, if empty they are printed")
+	viper.BindPFlag("output", simulateCmd.Flags().Lookup("output"))
+
+	simulateCmd.Flags().Bool("verbose", false, "If the logs of the games are printed to the stderr")
+	viper.BindPFlag("verbose", simulateCmd.Flags().Lookup("verbose"))
+}
+
+func main() {
+	if err := simulateCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
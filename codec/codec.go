@@ -0,0 +1,112 @@
+package codec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/coder/websocket"
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	// JSON is the default codec, it's easy to debug
+	// and the one used by the wasm client
+	JSON = "json"
+
+	// CBOR is a binary codec, it's more compact
+	// and faster than JSON
+	CBOR = "cbor"
+)
+
+var (
+	ErrUnknownCodec = errors.New("unknown codec")
+
+	cborEnc cbor.EncMode
+	cborDec cbor.DecMode
+)
+
+func init() {
+	var err error
+	// The times are encoded as strings and the maps of interface{} decoded as
+	// map[string]interface{} so it's the same as JSON for the dynamic values
+	// like the Unit.Abilities
+	cborEnc, err = cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	cborDec, err = cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}(nil))}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Codec encodes and decodes the messages sent through the websocket
+type Codec interface {
+	// Name is the name used to negotiate the Codec
+	Name() string
+
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+
+	// MessageType is the websocket message type used to send the data
+	MessageType() websocket.MessageType
+}
+
+// New returns the Codec with the name n, if n is empty it returns the JSON one
+func New(n string) (Codec, error) {
+	switch n {
+	case JSON, "":
+		return jsonCodec{}, nil
+	case CBOR:
+		return cborCodec{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, n)
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                               { return JSON }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) MessageType() websocket.MessageType         { return websocket.MessageText }
+
+type cborCodec struct{}
+
+func (cborCodec) Name() string                               { return CBOR }
+func (cborCodec) Marshal(v interface{}) ([]byte, error)      { return cborEnc.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v interface{}) error { return cborDec.Unmarshal(data, v) }
+func (cborCodec) MessageType() websocket.MessageType         { return websocket.MessageBinary }
+
+// Write writes v to the conn encoded with c
+func Write(ctx context.Context, conn *websocket.Conn, c Codec, v interface{}) error {
+	b, err := c.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", c.Name(), err)
+	}
+	return conn.Write(ctx, c.MessageType(), b)
+}
+
+// Read reads the next message from the conn into v, the codec
+// is the one of the message type so the text ones are JSON
+// and the binary ones CBOR
+func Read(ctx context.Context, conn *websocket.Conn, v interface{}) error {
+	typ, b, err := conn.Read(ctx)
+	if err != nil {
+		return err
+	}
+
+	var c Codec = jsonCodec{}
+	if typ == websocket.MessageBinary {
+		c = cborCodec{}
+	}
+
+	err = c.Unmarshal(b, v)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", c.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package codec_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/codec"
+	"github.com/xescugc/maze-wars/utils"
+)
+
+func newSyncState() *action.Action {
+	return action.NewSyncState(action.SyncStatePayload{
+		Version:    action.SyncStateVersion,
+		SnapshotID: 2,
+		Keyframe:   true,
+		Players: &action.SyncStatePlayersPayload{
+			Players: map[string]*action.SyncStatePlayerPayload{
+				"p1": {ID: "p1", Name: "player 1", Gold: 40, Lives: 20},
+			},
+			IncomeTimer: 10,
+		},
+		Lines: &action.SyncStateLinesPayload{
+			Lines: map[int]*action.SyncStateLinePayload{
+				0: {
+					Towers: map[string]*action.SyncStateTowerPayload{
+						"t1": {ID: "t1", Object: utils.Object{X: 16, Y: 32, W: 32, H: 32}, Type: "soldier"},
+					},
+					Units: map[string]*action.SyncStateUnitPayload{
+						"u1": {ID: "u1", MovingObject: utils.MovingObject{Object: utils.Object{X: 1.5, Y: 2.5}}, Health: 10},
+					},
+					Projectiles: map[string]*action.SyncStateProjectilePayload{},
+				},
+			},
+		},
+		StartedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Tick:      42,
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, n := range []string{codec.JSON, codec.CBOR} {
+		c, err := codec.New(n)
+		require.NoError(t, err)
+		assert.Equal(t, n, c.Name())
+	}
+
+	c, err := codec.New("")
+	require.NoError(t, err)
+	assert.Equal(t, codec.JSON, c.Name())
+
+	_, err = codec.New("xml")
+	assert.ErrorIs(t, err, codec.ErrUnknownCodec)
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	a := newSyncState()
+	sizes := make(map[string]int)
+	for _, n := range []string{codec.JSON, codec.CBOR} {
+		t.Run(n, func(t *testing.T) {
+			c, _ := codec.New(n)
+			b, err := c.Marshal(a)
+			require.NoError(t, err)
+			sizes[n] = len(b)
+
+			var na *action.Action
+			err = c.Unmarshal(b, &na)
+			require.NoError(t, err)
+			assert.Equal(t, a, na)
+		})
+	}
+
+	assert.Less(t, sizes[codec.CBOR], sizes[codec.JSON])
+}
+
+func TestWriteRead(t *testing.T) {
+	for _, n := range []string{codec.JSON, codec.CBOR} {
+		t.Run(n, func(t *testing.T) {
+			c, _ := codec.New(n)
+			a := newSyncState()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ws, err := websocket.Accept(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer ws.CloseNow()
+				codec.Write(r.Context(), ws, c, a)
+				ws.Close(websocket.StatusNormalClosure, "")
+			}))
+			defer srv.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+
+			ws, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+			require.NoError(t, err)
+			defer ws.CloseNow()
+
+			// The reader does not know the codec, it's
+			// deduced from the message type
+			var na *action.Action
+			err = codec.Read(ctx, ws, &na)
+			require.NoError(t, err)
+			assert.Equal(t, a, na)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package replay
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/xescugc/go-flux/v2"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/store"
+)
+
+const isOnServer = true
+
+// Player plays a Replay on a new store.Store
+type Player struct {
+	Store *store.Store
+
+	replay  Replay
+	balance *balance.Balance
+	logger  *slog.Logger
+
+	// next is the index of the next
+	// action of the replay to dispatch
+	next int
+}
+
+// NewPlayer returns a new Player for the Replay r
+func NewPlayer(r Replay, l *slog.Logger) *Player {
+	p := &Player{
+		replay:  r,
+		balance: balance.Default,
+		logger:  l,
+	}
+	if len(r.Balance) != 0 {
+		b, err := balance.Parse(r.Balance)
+		if err != nil {
+			// The Replay is validated when Read so
+			// it can only fail if it was not read
+			l.Error("failed to parse the replay balance", "error", err.Error())
+		} else {
+			p.balance = b
+		}
+	}
+	p.reset()
+
+	return p
+}
+
+// reset starts the replay again from the beginning with a new Store
+func (p *Player) reset() {
+	d := flux.NewDispatcher[*action.Action]()
+	p.Store = store.NewStore(d, p.logger, isOnServer)
+	p.Store.SetBalance(p.balance)
+	p.next = 0
+}
+
+// Tick returns the current Tick of the Game
+func (p *Player) Tick() int { return p.Store.Game.GetTick() }
+
+// EndTick returns the last Tick of the Replay
+func (p *Player) EndTick() int { return p.replay.EndTick() }
+
+// Ended returns if all the actions of the Replay have been played
+func (p *Player) Ended() bool { return p.next >= len(p.replay.Actions) }
+
+// Seek moves the Game to the tick. If the tick is before the current
+// one it'll start the Game again as the simulation only goes forward.
+// The recorded TPS are ignored as it moves tick by tick, which is
+// deterministic, and the rest of actions are applied on the same
+// tick they were recorded.
+func (p *Player) Seek(tick int) {
+	if tick < p.Tick() {
+		p.reset()
+	}
+	for {
+		for !p.Ended() && p.replay.Actions[p.next].Tick <= p.Tick() {
+			if a := p.replay.Actions[p.next].Action; a.Type != action.TPS {
+				p.Store.Dispatch(a)
+			}
+			p.next++
+		}
+		if p.Tick() >= tick || p.Ended() {
+			break
+		}
+		p.Store.Dispatch(action.NewTPS(time.Time{}))
+	}
+}
@@ -0,0 +1,163 @@
+package replay
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/xescugc/go-flux/v2"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/store"
+)
+
+// Version is the current version of the replay format. It has
+// to be increased every time the format or the simulation
+// changes in a way that old replays would not play the same
+const Version = 1
+
+var (
+	ErrInvalidVersion = errors.New("invalid replay version")
+
+	// recordedTypes are the actions that change the Game
+	// state, which are the ones needed to play it again
+	recordedTypes = map[action.Type]struct{}{
+		action.AddPlayer:            {},
+		action.StartGame:            {},
+		action.PlaceTower:           {},
+		action.SummonUnit:           {},
+		action.UpdateUnit:           {},
+		action.SummonHero:           {},
+		action.UseHeroAbility:       {},
+		action.UpdateTower:          {},
+		action.UpdateTowerTargeting: {},
+		action.RemoveTower:          {},
+		action.IncomeTick:           {},
+		action.TPS:                  {},
+		action.RemovePlayer:         {},
+	}
+)
+
+// Replay is the list of actions that were dispatched
+// on a Game with the Tick in which they were applied
+type Replay struct {
+	Version int      `json:"version"`
+	Actions []Action `json:"actions"`
+
+	// Balance is the balance pack the Game was
+	// played with, empty if it was the default one
+	Balance json.RawMessage `json:"balance,omitempty"`
+}
+
+// Action is an action.Action that was applied
+// on the Tick of the Game
+type Action struct {
+	Tick   int            `json:"tick"`
+	Action *action.Action `json:"action"`
+}
+
+// EndTick returns the last Tick of the Replay
+func (r Replay) EndTick() int {
+	if len(r.Actions) == 0 {
+		return 0
+	}
+	return r.Actions[len(r.Actions)-1].Tick
+}
+
+// Write writes the Replay r into w
+func Write(w io.Writer, r Replay) error {
+	gw := gzip.NewWriter(w)
+	err := json.NewEncoder(gw).Encode(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode replay: %w", err)
+	}
+	return gw.Close()
+}
+
+// Read reads a Replay from r and validates that it has the current Version
+func Read(r io.Reader) (Replay, error) {
+	var rp Replay
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return rp, fmt.Errorf("failed to read replay: %w", err)
+	}
+	defer gr.Close()
+
+	err = json.NewDecoder(gr).Decode(&rp)
+	if err != nil {
+		return rp, fmt.Errorf("failed to decode replay: %w", err)
+	}
+
+	if rp.Version != Version {
+		return rp, fmt.Errorf("%w: expected %d and got %d", ErrInvalidVersion, Version, rp.Version)
+	}
+
+	if len(rp.Balance) != 0 {
+		_, err = balance.Parse(rp.Balance)
+		if err != nil {
+			return rp, fmt.Errorf("failed to parse replay balance: %w", err)
+		}
+	}
+
+	return rp, nil
+}
+
+// Recorder records all the actions dispatched to the Game of a Store
+type Recorder struct {
+	dispatcher *flux.Dispatcher[*action.Action]
+	store      *store.Store
+	token      string
+
+	mxReplay sync.Mutex
+	replay   Replay
+}
+
+// NewRecorder starts recording the actions dispatched on d that change the state of s
+func NewRecorder(d *flux.Dispatcher[*action.Action], s *store.Store) *Recorder {
+	r := &Recorder{
+		dispatcher: d,
+		store:      s,
+		replay: Replay{
+			Version: Version,
+		},
+	}
+	r.token = d.Register(r.record)
+
+	return r
+}
+
+func (r *Recorder) record(act *action.Action) {
+	if _, ok := recordedTypes[act.Type]; !ok {
+		return
+	}
+
+	// We need the Tick after the action was applied
+	r.dispatcher.WaitFor(r.store.Game.GetDispatcherToken())
+
+	r.mxReplay.Lock()
+	defer r.mxReplay.Unlock()
+
+	r.replay.Actions = append(r.replay.Actions, Action{
+		Tick:   r.store.Game.GetTick(),
+		Action: act,
+	})
+}
+
+// Stop stops recording
+func (r *Recorder) Stop() {
+	r.dispatcher.Unregister(r.token)
+}
+
+// Replay returns the current recorded Replay
+func (r *Recorder) Replay() Replay {
+	r.mxReplay.Lock()
+	defer r.mxReplay.Unlock()
+
+	rp := r.replay
+	rp.Actions = append([]Action(nil), r.replay.Actions...)
+	rp.Balance = r.store.Balance().Raw()
+	return rp
+}
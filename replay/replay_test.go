@@ -0,0 +1,125 @@
+package replay_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/go-flux/v2"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/replay"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/unit"
+)
+
+const isOnServer = true
+
+func newEmptyLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// play plays a small game recording it
+func play(t *testing.T) (*store.Store, replay.Replay) {
+	t.Helper()
+
+	sa := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	d := flux.NewDispatcher[*action.Action]()
+	s := store.NewStore(d, newEmptyLogger(), isOnServer)
+	rec := replay.NewRecorder(d, s)
+
+	s.Dispatch(action.NewAddPlayer("p1", "p1", unit.Ninja.String(), 0, false))
+	s.Dispatch(action.NewAddPlayer("p2", "p2", unit.Ninja.String(), 1, false))
+
+	players := make(map[string]*action.SyncStatePlayerPayload)
+	for _, p := range s.Game.ListPlayers() {
+		sp := action.SyncStatePlayerPayload{
+			ID:          p.ID,
+			Name:        p.Name,
+			Lives:       p.Lives,
+			LineID:      p.LineID,
+			Income:      p.Income,
+			Gold:        p.Gold,
+			UnitUpdates: make(map[string]action.SyncStatePlayerUnitUpdatePayload),
+		}
+		for t, uu := range p.UnitUpdates {
+			sp.UnitUpdates[t] = action.SyncStatePlayerUnitUpdatePayload(uu)
+		}
+		players[p.ID] = &sp
+	}
+	s.Dispatch(action.NewStartGame(action.SyncStatePayload{
+		Players:   &action.SyncStatePlayersPayload{Players: players, IncomeTimer: 15},
+		Lines:     &action.SyncStateLinesPayload{},
+		StartedAt: sa,
+	}, 42))
+
+	g := s.Game.FindLineByID(1).Graph
+	for i := 1; i <= 40; i++ {
+		switch i {
+		case 2:
+			s.Dispatch(action.NewPlaceTower("range1", "p2", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		case 3, 5, 7:
+			s.Dispatch(action.NewSummonUnit(unit.Ninja.String(), "p1", 0, 1))
+		}
+		if i%4 == 0 {
+			s.Dispatch(action.NewIncomeTick())
+		}
+		s.Dispatch(action.NewTPS(sa.Add(time.Duration(i) * time.Second / 4)))
+	}
+	rec.Stop()
+
+	return s, rec.Replay()
+}
+
+func TestReadWrite(t *testing.T) {
+	_, rp := play(t)
+
+	var b bytes.Buffer
+	err := replay.Write(&b, rp)
+	require.NoError(t, err)
+
+	nrp, err := replay.Read(&b)
+	require.NoError(t, err)
+
+	assert.Equal(t, replay.Version, nrp.Version)
+	assert.Len(t, nrp.Actions, len(rp.Actions))
+	assert.Equal(t, rp.EndTick(), nrp.EndTick())
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		rp.Version = replay.Version + 1
+		err := replay.Write(&b, rp)
+		require.NoError(t, err)
+
+		_, err = replay.Read(&b)
+		assert.ErrorIs(t, err, replay.ErrInvalidVersion)
+	})
+}
+
+func TestPlayer(t *testing.T) {
+	s, rp := play(t)
+
+	var b bytes.Buffer
+	err := replay.Write(&b, rp)
+	require.NoError(t, err)
+	rp, err = replay.Read(&b)
+	require.NoError(t, err)
+
+	p := replay.NewPlayer(rp, newEmptyLogger())
+	p.Seek(rp.EndTick())
+
+	assert.True(t, p.Ended())
+	assert.Equal(t, 10*60, p.Tick())
+	assert.Equal(t, s.Game.GetState(), p.Store.Game.GetState())
+
+	t.Run("SeekBackwards", func(t *testing.T) {
+		p.Seek(60)
+		assert.Equal(t, 60, p.Tick())
+		assert.False(t, p.Ended())
+
+		p.Seek(rp.EndTick())
+		assert.Equal(t, s.Game.GetState(), p.Store.Game.GetState())
+	})
+}
@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
+	"maps"
 	"sort"
 	"time"
 
@@ -11,6 +13,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/codec"
+	"github.com/xescugc/maze-wars/server/rating"
+	"github.com/xescugc/maze-wars/server/storage"
 	"github.com/xescugc/maze-wars/unit"
 	"github.com/xescugc/maze-wars/utils"
 )
@@ -87,6 +92,53 @@ func (ac *ActionDispatcher) Dispatch(a *action.Action) {
 	numberOfActions.With(prometheus.Labels{"type": a.Type.String()}).Observe(float64(time.Now().Sub(b)))
 }
 
+// rejectAction records that the action a sent by the
+// User un was not dispatched because of the err
+func (ac *ActionDispatcher) rejectAction(un string, a *action.Action, err error) {
+	ac.logger.Warn("action rejected", "username", un, "type", a.Type.String(), "reason", err.Error())
+	numberOfRejectedActions.With(prometheus.Labels{"type": a.Type.String(), "reason": err.Error()}).Inc()
+
+	if a.Type == action.CreateLobby {
+		ac.notifyLobbyNotCreated(un, err)
+	}
+}
+
+// notifyLobbyNotCreated sends the User un back to the lobbies without the
+// one it tried to create, as the client already added it, with the err
+func (ac *ActionDispatcher) notifyLobbyNotCreated(un string, err error) {
+	u, ok := ac.store.Rooms.FindUserByUsername(un)
+	if !ok {
+		return
+	}
+
+	lbs := ac.store.Lobbies.List()
+	albs := make([]*action.LobbyPayload, 0, len(lbs))
+	for _, l := range lbs {
+		albs = append(albs, &action.LobbyPayload{
+			ID:         l.ID,
+			Name:       l.Name,
+			MaxPlayers: l.MaxPlayers,
+			Balance:    l.Balance,
+			Players:    l.Players,
+			Owner:      l.Owner,
+		})
+	}
+
+	// It navigates first so the client does not try to
+	// show the lobby once it's removed from the list
+	for _, a := range []*action.Action{
+		action.NewNavigateTo(utils.LobbiesRoute),
+		action.NewAddLobbies(&action.AddLobbiesPayload{Lobbies: albs}),
+		action.NewAddError(fmt.Sprintf("Failed to create the lobby: %s", err)),
+	} {
+		werr := ac.ws.Write(context.Background(), u.Conn, a)
+		if werr != nil {
+			ac.logger.Error(fmt.Errorf("failed to notify the lobby error: %w", werr).Error())
+			return
+		}
+	}
+}
+
 func (ac *ActionDispatcher) notifyPlayersLobbyDeleted(uns map[string]bool) {
 	lbs := ac.store.Lobbies.List()
 	albs := make([]*action.LobbyPayload, 0, len(lbs))
@@ -95,6 +147,7 @@ func (ac *ActionDispatcher) notifyPlayersLobbyDeleted(uns map[string]bool) {
 			ID:         l.ID,
 			Name:       l.Name,
 			MaxPlayers: l.MaxPlayers,
+			Balance:    l.Balance,
 			Players:    l.Players,
 			Owner:      l.Owner,
 		})
@@ -146,8 +199,7 @@ func (ac *ActionDispatcher) startGame(vs, roid string) {
 			state.Rooms[rid].Bots[pid].Start()
 			continue
 		}
-		sga := action.NewStartGame(ac.store.Rooms.SyncState(r, pid))
-		err := ac.ws.Write(context.Background(), p.User.Conn, sga)
+		err := ac.ws.Write(context.Background(), p.User.Conn, ac.store.Rooms.newStartGame(r, p.User))
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -159,11 +211,20 @@ func (ac *ActionDispatcher) IncomeTick() {
 	ac.Dispatch(ita)
 }
 
-func (ac *ActionDispatcher) UserSignUp(un, ik string) {
-	ac.Dispatch(action.NewUserSignUp(un, ik))
+func (ac *ActionDispatcher) UserSignUp(id, un, ik string, rt int) {
+	a := action.NewUserSignUp(id, un, ik)
+	a.UserSignUp.Rating = rt
+	ac.Dispatch(a)
 }
 
-func (ac *ActionDispatcher) UserSignIn(un, ra string, ws *websocket.Conn) {
+func (ac *ActionDispatcher) UserSignIn(un, ra, cn string, ws *websocket.Conn) {
+	// If the codec is not known we fallback to the default one
+	c, err := codec.New(cn)
+	if err != nil {
+		ac.logger.Error("unknown codec", "username", un, "codec", cn)
+	}
+	ac.ws.SetCodec(ws, c)
+
 	a := action.NewUserSignIn(un)
 	a.UserSignIn.RemoteAddr = ra
 	a.UserSignIn.Websocket = ws
@@ -175,97 +236,187 @@ func (ac *ActionDispatcher) UserSignOut(un string) {
 	ac.Dispatch(action.NewUserSignOut(un))
 }
 
+// UserDisconnect is called when the connection ws of the User un is lost, if
+// it's on a Game it has the reconnectGracePeriod to resume it, if not it's
+// signed out
+func (ac *ActionDispatcher) UserDisconnect(un string, ws *websocket.Conn) {
+	u, ok := ac.store.Rooms.FindUserByUsername(un)
+	// If it's not the current connection it was
+	// already replaced by a new one
+	if !ok || u.Conn != ws {
+		return
+	}
+	if r := ac.store.Rooms.FindRoomByID(u.CurrentRoomID); r != nil && r.Game != nil {
+		ac.Dispatch(action.NewUserDisconnect(un))
+		return
+	}
+	ac.UserSignOut(un)
+}
+
+// signOutDisconnectedUsers signs out the Users that
+// did not reconnect on the reconnectGracePeriod
+func (ac *ActionDispatcher) signOutDisconnectedUsers() {
+	for _, un := range ac.store.Rooms.ListDisconnectedUsers(time.Now().Add(-reconnectGracePeriod)) {
+		ac.UserSignOut(un)
+	}
+}
+
+func (ac *ActionDispatcher) SyncUser(un string, r, rc int) {
+	ac.Dispatch(action.NewSyncUser(un, r, rc))
+}
+
+// rate updates the Rating of the players with the final places of a ranked Game
+func (ac *ActionDispatcher) rate(places map[string]int) {
+	ctx := context.Background()
+	accs := make(map[string]*storage.Account, len(places))
+	ps := make([]rating.Player, 0, len(places))
+	for id, pl := range places {
+		a, err := ac.store.Accounts.FindAccountByID(ctx, id)
+		if err != nil {
+			// If one is missing the Game cannot be rated
+			ac.logger.Error("failed to find the account to rate", "id", id, "error", err.Error())
+			return
+		}
+		accs[id] = a
+		ps = append(ps, rating.Player{ID: id, Rating: a.Rating, Place: pl})
+	}
+
+	for id, nr := range rating.Compute(ps) {
+		a := accs[id]
+		rc := nr - a.Rating
+		a.Rating = nr
+		a.RankedGames++
+		if places[id] == 1 {
+			a.RankedWins++
+		}
+		err := ac.store.Accounts.UpdateAccount(ctx, a)
+		if err != nil {
+			ac.logger.Error("failed to update the account rating", "id", id, "error", err.Error())
+			continue
+		}
+		ac.SyncUser(a.Username, a.Rating, rc)
+	}
+}
+
 func (ac *ActionDispatcher) syncState() {
 	ac.Dispatch(action.NewTPS(time.Now()))
 	rms := ac.store.Rooms.ListRooms()
 	for _, r := range rms {
+		if r.Standings != nil {
+			if places, ok := r.Standings.Update(r.Game.Store.Game.ListPlayers()); ok {
+				ac.rate(places)
+			}
+		}
+
+		// Each user receives only the changes from
+		// the last snapshot it has acknowledged
+		ss := r.Snapshots.Add(ac.newSyncState(r))
 		for id, pc := range r.Players {
-			// We do not want to communicate state to a bot
-			if pc.IsBot {
+			// We do not want to communicate state to a
+			// bot or to a User that is disconnected
+			if pc.IsBot || pc.User.Conn == nil {
 				continue
 			}
-			// Players
-			players := make(map[string]*action.SyncStatePlayerPayload)
-			lplayers := r.Game.Store.Game.ListPlayers()
-			for _, p := range lplayers {
-				ap := p
-				uspp := action.SyncStatePlayerPayload{
-					ID:          ap.ID,
-					Name:        ap.Name,
-					ImageKey:    ap.ImageKey,
-					Lives:       ap.Lives,
-					LineID:      ap.LineID,
-					Income:      ap.Income,
-					Gold:        ap.Gold,
-					IsBot:       ap.IsBot,
-					Current:     ap.Current,
-					Winner:      ap.Winner,
-					Capacity:    ap.Capacity,
-					UnitUpdates: make(map[string]action.SyncStatePlayerUnitUpdatePayload),
-				}
-				// TODO: Make it concurrently safe
-				for t, uu := range ap.UnitUpdates {
-					uspp.UnitUpdates[t] = action.SyncStatePlayerUnitUpdatePayload(uu)
-				}
-				if id == ap.ID {
-					uspp.Current = true
-				}
-				players[ap.ID] = &uspp
+			p := r.Snapshots.For(id, ss)
+			err := ac.ws.Write(context.Background(), pc.User.Conn, action.NewSyncState(p))
+			if err != nil {
+				log.Fatal(err)
 			}
+		}
 
-			// Lines
-			lines := make(map[int]*action.SyncStateLinePayload)
-			llines := r.Game.Store.Game.ListLines()
-			for _, l := range llines {
-				al := l
-				// Towers
-				towers := make(map[string]*action.SyncStateTowerPayload)
-				for _, t := range al.Towers {
-					at := t
-					payload := action.SyncStateTowerPayload(*at)
-					towers[at.ID] = &payload
-				}
+		for id, u := range r.Spectators {
+			p := r.Snapshots.For(id, ss)
+			err := ac.ws.Write(context.Background(), u.Conn, action.NewSyncState(p))
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
 
-				// Units
-				units := make(map[string]*action.SyncStateUnitPayload)
-				for _, u := range al.Units {
-					au := u
-					payload := action.SyncStateUnitPayload(*au)
-					units[au.ID] = &payload
-				}
+// newSyncState returns the full SyncState of the Room r
+// without any Current player
+func (ac *ActionDispatcher) newSyncState(r *Room) action.SyncStatePayload {
+	// Players
+	players := make(map[string]*action.SyncStatePlayerPayload)
+	lplayers := r.Game.Store.Game.ListPlayers()
+	for _, p := range lplayers {
+		ap := p
+		uspp := action.SyncStatePlayerPayload{
+			ID:          ap.ID,
+			Name:        ap.Name,
+			ImageKey:    ap.ImageKey,
+			Lives:       ap.Lives,
+			LineID:      ap.LineID,
+			Income:      ap.Income,
+			Gold:        ap.Gold,
+			IsBot:       ap.IsBot,
+			Current:     ap.Current,
+			Winner:      ap.Winner,
+			Capacity:    ap.Capacity,
+			UnitUpdates: make(map[string]action.SyncStatePlayerUnitUpdatePayload),
+		}
+		// TODO: Make it concurrently safe
+		for t, uu := range ap.UnitUpdates {
+			uspp.UnitUpdates[t] = action.SyncStatePlayerUnitUpdatePayload(uu)
+		}
+		uspp.Hero = action.SyncStatePlayerHeroPayload(ap.Hero)
+		uspp.Hero.UsedAt = maps.Clone(ap.Hero.UsedAt)
+		players[ap.ID] = &uspp
+	}
 
-				// Projectiles
-				projectiles := make(map[string]*action.SyncStateProjectilePayload)
-				for _, p := range al.Projectiles {
-					ap := p
-					payload := action.SyncStateProjectilePayload(*ap)
-					projectiles[ap.ID] = &payload
-				}
+	// Lines
+	lines := make(map[int]*action.SyncStateLinePayload)
+	llines := r.Game.Store.Game.ListLines()
+	for _, l := range llines {
+		al := l
+		// Towers
+		towers := make(map[string]*action.SyncStateTowerPayload)
+		for _, t := range al.Towers {
+			at := t
+			payload := action.SyncStateTowerPayload(*at)
+			towers[at.ID] = &payload
+		}
 
-				lines[al.ID] = &action.SyncStateLinePayload{
-					ID:          al.ID,
-					Towers:      towers,
-					Units:       units,
-					Projectiles: projectiles,
-				}
-			}
+		// Units
+		units := make(map[string]*action.SyncStateUnitPayload)
+		for _, u := range al.Units {
+			au := u
+			payload := action.SyncStateUnitPayload(*au)
+			// The snapshots are kept to compute the deltas so
+			// they cannot share the maps with the Game
+			payload.Abilities = maps.Clone(au.Abilities)
+			payload.Buffs = maps.Clone(au.Buffs)
+			units[au.ID] = &payload
+		}
 
-			aus := action.NewSyncState(
-				&action.SyncStatePlayersPayload{
-					Players:     players,
-					IncomeTimer: r.Game.Store.Game.GetIncomeTimer(),
-				},
-				&action.SyncStateLinesPayload{
-					Lines: lines,
-				},
-				r.StartedAt,
-			)
-			err := ac.ws.Write(context.Background(), pc.User.Conn, aus)
-			if err != nil {
-				log.Fatal(err)
-			}
+		// Projectiles
+		projectiles := make(map[string]*action.SyncStateProjectilePayload)
+		for _, p := range al.Projectiles {
+			ap := p
+			payload := action.SyncStateProjectilePayload(*ap)
+			projectiles[ap.ID] = &payload
+		}
+
+		lines[al.ID] = &action.SyncStateLinePayload{
+			ID:          al.ID,
+			Towers:      towers,
+			Units:       units,
+			Projectiles: projectiles,
 		}
 	}
+
+	return action.SyncStatePayload{
+		Players: &action.SyncStatePlayersPayload{
+			Players:     players,
+			IncomeTimer: r.Game.Store.Game.GetIncomeTimer(),
+		},
+		Lines: &action.SyncStateLinesPayload{
+			Lines: lines,
+		},
+		StartedAt: r.StartedAt,
+		Tick:      r.Game.Store.Game.GetTick(),
+	}
 }
 
 // syncLobbies will just sync the info of each lobby to the players on it
@@ -276,6 +427,7 @@ func (ac *ActionDispatcher) syncLobbies() {
 			ID:         l.ID,
 			Name:       l.Name,
 			MaxPlayers: l.MaxPlayers,
+			Balance:    l.Balance,
 			Players:    l.Players,
 			Owner:      l.Owner,
 		}
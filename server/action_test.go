@@ -1,11 +1,16 @@
 package server_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/coder/websocket"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/codec"
 	"github.com/xescugc/maze-wars/server"
 	"github.com/xescugc/maze-wars/server/mock"
 )
@@ -39,12 +44,13 @@ func TestUserSignUp(t *testing.T) {
 		ad, s := initStore(mwsc)
 		un := "user name"
 
-		ad.UserSignUp(un, "ImageKey")
+		ad.UserSignUp("user-id", un, "ImageKey", 1500)
 
 		u := &server.User{
 			ID:       s.Rooms.ListUsers()[0].ID,
 			Username: un,
 			ImageKey: "ImageKey",
+			Rating:   1500,
 		}
 		rs := roomsInitialState()
 		rs.Users[un] = u
@@ -64,8 +70,11 @@ func TestUserSignIn(t *testing.T) {
 		ra := "remote-address"
 		ws := &websocket.Conn{}
 
-		ad.UserSignUp(un, "ImageKey")
-		ad.UserSignIn(un, ra, ws)
+		c, _ := codec.New(codec.CBOR)
+		mwsc.EXPECT().SetCodec(ws, c)
+
+		ad.UserSignUp("user-id", un, "ImageKey", 1500)
+		ad.UserSignIn(un, ra, codec.CBOR, ws)
 
 		u := &server.User{
 			ID:         s.Rooms.ListUsers()[0].ID,
@@ -73,6 +82,7 @@ func TestUserSignIn(t *testing.T) {
 			Conn:       ws,
 			RemoteAddr: ra,
 			ImageKey:   "ImageKey",
+			Rating:     1500,
 		}
 		rs := roomsInitialState()
 		rs.Users[un] = u
@@ -89,14 +99,17 @@ func TestUserSignIn(t *testing.T) {
 		ra := "remote-address"
 		ws := &websocket.Conn{}
 
-		ad.UserSignUp(un, "ImageKey")
-		ad.UserSignIn("not-found", ra, ws)
+		mwsc.EXPECT().SetCodec(ws, gomock.Any())
+
+		ad.UserSignUp("user-id", un, "ImageKey", 1500)
+		ad.UserSignIn("not-found", ra, codec.JSON, ws)
 
 		rs := roomsInitialState()
 		u := &server.User{
 			ID:       s.Rooms.ListUsers()[0].ID,
 			Username: un,
 			ImageKey: "ImageKey",
+			Rating:   1500,
 		}
 		rs.Users[un] = u
 
@@ -113,7 +126,7 @@ func TestUserSignOut(t *testing.T) {
 		ad, s := initStore(mwsc)
 		un := "user name"
 
-		ad.UserSignUp(un, "ImageKey")
+		ad.UserSignUp("user-id", un, "ImageKey", 1500)
 		ad.UserSignOut(un)
 
 		assert.Equal(t, roomsInitialState(), s.Rooms.GetState())
@@ -130,7 +143,7 @@ func TestUserSignOut(t *testing.T) {
 		ad, s := initStore(mwsc)
 		un := "user name"
 
-		ad.UserSignUp(un, "ImageKey")
+		ad.UserSignUp("user-id", un, "ImageKey", 1500)
 		ad.UserSignOut("not-found")
 
 		rs := roomsInitialState()
@@ -138,6 +151,7 @@ func TestUserSignOut(t *testing.T) {
 			ID:       s.Rooms.ListUsers()[0].ID,
 			Username: un,
 			ImageKey: "ImageKey",
+			Rating:   1500,
 		}
 		rs.Users[un] = u
 
@@ -156,8 +170,8 @@ func TestFindGame(t *testing.T) {
 	//ra := "remote-address"
 	//ws := &websocket.Conn{}
 
-	//ad.UserSignUp(un, "ImageKey")
-	//ad.UserSignIn(un, ra, ws)
+	//ad.UserSignUp("user-id", un, "ImageKey", 1500)
+	//ad.UserSignIn(un, ra, codec.JSON, ws)
 
 	//u := &server.User{
 	//ID:         s.Rooms.ListUsers()[0].ID,
@@ -181,4 +195,192 @@ func TestFindGame(t *testing.T) {
 	//})
 }
 
+func TestFindGame_Ranked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mwsc := mock.NewMockWSConnector(ctrl)
+
+	ad, s := initStore(mwsc)
+	mwsc.EXPECT().SetCodec(gomock.Any(), gomock.Any()).AnyTimes()
+
+	for un, r := range map[string]int{"low": 1500, "close": 1550, "high": 1900} {
+		ad.UserSignUp(un+"-id", un, "ImageKey", r)
+		ad.UserSignIn(un, un+"-address", codec.JSON, &websocket.Conn{})
+	}
+
+	ad.Dispatch(action.NewFindGame("low", true, true, false))
+	ad.Dispatch(action.NewFindGame("high", true, true, false))
+
+	// The Rating difference is too big to be matched
+	require.Len(t, s.Rooms.ListSearchingRooms(), 2)
+
+	ad.Dispatch(action.NewFindGame("close", true, true, false))
+
+	require.Len(t, s.Rooms.ListSearchingRooms(), 1)
+	assert.Contains(t, s.Rooms.ListSearchingRooms()[0].Players, "high-id")
+
+	wrs := s.Rooms.ListWaitingRooms()
+	require.Len(t, wrs, 1)
+	assert.Contains(t, wrs[0].Players, "low-id")
+	assert.Contains(t, wrs[0].Players, "close-id")
+}
+
+func TestSyncUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mwsc := mock.NewMockWSConnector(ctrl)
+
+	ad, s := initStore(mwsc)
+	un := "user name"
+	ws := &websocket.Conn{}
+
+	mwsc.EXPECT().SetCodec(ws, gomock.Any())
+	ad.UserSignUp("user-id", un, "ImageKey", 1500)
+	ad.UserSignIn(un, "remote-address", codec.JSON, ws)
+
+	mwsc.EXPECT().Write(gomock.Any(), ws, action.NewSyncUser(un, 1516, 16))
+	ad.SyncUser(un, 1516, 16)
+
+	u, _ := s.Rooms.FindUserByUsername(un)
+	assert.Equal(t, 1516, u.Rating)
+}
+
+func TestSpectateGame(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mwsc := mock.NewMockWSConnector(ctrl)
+
+	ad, s := initStore(mwsc)
+	var (
+		owner  = "owner"
+		player = "player"
+		spec   = "spectator"
+		lid    = "lobby-id"
+		ows    = &websocket.Conn{}
+		pws    = &websocket.Conn{}
+		sws    = &websocket.Conn{}
+	)
+
+	mwsc.EXPECT().SetCodec(gomock.Any(), gomock.Any()).Times(3)
+
+	ad.UserSignUp(owner+"-id", owner, "ImageKey", 1500)
+	ad.UserSignIn(owner, "owner-address", codec.JSON, ows)
+	ad.UserSignUp(player+"-id", player, "ImageKey", 1500)
+	ad.UserSignIn(player, "player-address", codec.JSON, pws)
+	ad.UserSignUp(spec+"-id", spec, "ImageKey", 1500)
+	ad.UserSignIn(spec, "spectator-address", codec.JSON, sws)
+
+	// The gomock matcher compares the Conns by value and
+	// they are all equal so we check the pointer directly
+	var sga *action.Action
+	mwsc.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ws *websocket.Conn, a interface{}) error {
+		if ws == sws {
+			sga = a.(*action.Action)
+		}
+		return nil
+	}).AnyTimes()
+
+	ad.Dispatch(action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{}))
+	ad.Dispatch(action.NewJoinLobby(lid, player, false))
+	ad.Dispatch(action.NewStartLobby(lid))
+
+	require.Nil(t, sga)
+
+	ad.Dispatch(action.NewSpectateGame(spec, lid))
+
+	r := s.Rooms.FindRoomByID(lid)
+	require.NotNil(t, r)
+	u, _ := s.Rooms.FindUserByUsername(spec)
+	assert.Equal(t, lid, u.SpectatingRoomID)
+	assert.Contains(t, r.Spectators, u.ID)
+
+	require.NotNil(t, sga)
+	assert.Equal(t, action.StartGame, sga.Type)
+	assert.Len(t, sga.StartGame.State.Players.Players, 2)
+	for _, p := range sga.StartGame.State.Players.Players {
+		assert.False(t, p.Current)
+	}
+
+	t.Run("Exit", func(t *testing.T) {
+		esga := action.NewExitSpectateGame()
+		esga.ExitSpectateGame.Username = spec
+		ad.Dispatch(esga)
+
+		u, _ := s.Rooms.FindUserByUsername(spec)
+		assert.Equal(t, "", u.SpectatingRoomID)
+		assert.NotContains(t, r.Spectators, u.ID)
+	})
+}
+
+func TestUserDisconnect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mwsc := mock.NewMockWSConnector(ctrl)
+
+	ad, s := initStore(mwsc)
+	var (
+		owner  = "owner"
+		player = "player"
+		lid    = "lobby-id"
+		ows    = &websocket.Conn{}
+		pws    = &websocket.Conn{}
+		nws    = &websocket.Conn{}
+	)
+
+	mwsc.EXPECT().SetCodec(gomock.Any(), gomock.Any()).Times(3)
+
+	ad.UserSignUp(owner+"-id", owner, "ImageKey", 1500)
+	ad.UserSignIn(owner, "owner-address", codec.JSON, ows)
+	ad.UserSignUp(player+"-id", player, "ImageKey", 1500)
+	ad.UserSignIn(player, "player-address", codec.JSON, pws)
+
+	// The gomock matcher compares the Conns by value and
+	// they are all equal so we check the pointer directly
+	var sga *action.Action
+	mwsc.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, ws *websocket.Conn, a interface{}) error {
+		if ws == nws {
+			sga = a.(*action.Action)
+		}
+		return nil
+	}).AnyTimes()
+
+	ad.Dispatch(action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{}))
+	ad.Dispatch(action.NewJoinLobby(lid, player, false))
+	ad.Dispatch(action.NewStartLobby(lid))
+
+	u, _ := s.Rooms.FindUserByUsername(player)
+	require.NotEmpty(t, u.ResumeToken)
+	rtk := u.ResumeToken
+
+	// A Conn that is not the current one is ignored
+	ad.UserDisconnect(player, nws)
+	u, _ = s.Rooms.FindUserByUsername(player)
+	assert.True(t, u.DisconnectedAt.IsZero())
+
+	ad.UserDisconnect(player, pws)
+
+	r := s.Rooms.FindRoomByID(lid)
+	require.NotNil(t, r)
+	u, ok := s.Rooms.FindUserByUsername(player)
+	require.True(t, ok)
+	assert.Nil(t, u.Conn)
+	assert.False(t, u.DisconnectedAt.IsZero())
+	assert.Contains(t, r.Bots, u.ID)
+	assert.Empty(t, s.Rooms.ListDisconnectedUsers(u.DisconnectedAt.Add(-time.Second)))
+	assert.Equal(t, []string{player}, s.Rooms.ListDisconnectedUsers(u.DisconnectedAt.Add(time.Second)))
+
+	t.Run("Resume", func(t *testing.T) {
+		ad.UserSignIn(player, "player-address", codec.JSON, nws)
+
+		u, _ := s.Rooms.FindUserByUsername(player)
+		assert.True(t, u.Conn == nws)
+		assert.True(t, u.DisconnectedAt.IsZero())
+		assert.NotContains(t, r.Bots, u.ID)
+
+		require.NotNil(t, sga)
+		assert.Equal(t, action.StartGame, sga.Type)
+		assert.Equal(t, rtk, sga.StartGame.ResumeToken)
+	})
+}
+
 // TODO: Lobbies
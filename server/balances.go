@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/xescugc/maze-wars/balance"
+)
+
+// Balances are the balance packs the server has loaded
+type Balances struct {
+	// Default is the Balance of the Games
+	// that do not pick any pack
+	Default *balance.Balance
+
+	// packs are the ones that can be
+	// picked on the lobbies by name
+	packs map[string]*balance.Balance
+}
+
+// NewBalances returns the Balances with only the balance.Default
+func NewBalances() *Balances {
+	return &Balances{
+		Default: balance.Default,
+		packs:   make(map[string]*balance.Balance),
+	}
+}
+
+// LoadBalances loads the packs from p. If p is a file the pack is
+// the Default of all the Games and if it's a directory all the
+// packs on it can be picked on the lobbies
+func LoadBalances(p string) (*Balances, error) {
+	bs := NewBalances()
+	if p == "" {
+		return bs, nil
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balances: %w", err)
+	}
+
+	bls, err := balance.Load(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bls {
+		bs.packs[b.Name] = b
+	}
+
+	if !fi.IsDir() {
+		bs.Default = bls[0]
+	}
+
+	return bs, nil
+}
+
+// Find returns the pack with the name n, the
+// empty name is the one of the Default
+func (bs *Balances) Find(n string) (*balance.Balance, bool) {
+	if n == "" {
+		return bs.Default, true
+	}
+	b, ok := bs.packs[n]
+	return b, ok
+}
+
+// FindByHash returns the pack with the hash h
+func (bs *Balances) FindByHash(h string) (*balance.Balance, bool) {
+	if h != "" && bs.Default.Hash() == h {
+		return bs.Default, true
+	}
+	for _, b := range bs.packs {
+		if b.Hash() == h {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the sorted names of the packs
+func (bs *Balances) Names() []string {
+	res := make([]string, 0, len(bs.packs))
+	for n := range bs.packs {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res
+}
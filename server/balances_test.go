@@ -0,0 +1,112 @@
+package server_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/go-flux/v2"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/codec"
+	"github.com/xescugc/maze-wars/server"
+	"github.com/xescugc/maze-wars/server/mock"
+	"github.com/xescugc/maze-wars/server/storage/memory"
+)
+
+func TestLoadBalances(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fast.json"), []byte(`{"game": {"income_timer": 10}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rich.json"), []byte(`{"game": {"gold": 100}}`), 0644))
+
+	t.Run("Empty", func(t *testing.T) {
+		bs, err := server.LoadBalances("")
+		require.NoError(t, err)
+		assert.Equal(t, balance.Default, bs.Default)
+		assert.Empty(t, bs.Names())
+	})
+	t.Run("Directory", func(t *testing.T) {
+		bs, err := server.LoadBalances(dir)
+		require.NoError(t, err)
+		assert.Equal(t, balance.Default, bs.Default)
+		assert.Equal(t, []string{"fast", "rich"}, bs.Names())
+
+		b, ok := bs.Find("rich")
+		require.True(t, ok)
+		assert.Equal(t, 100, b.Gold)
+
+		hb, ok := bs.FindByHash(b.Hash())
+		require.True(t, ok)
+		assert.Equal(t, b, hb)
+
+		db, ok := bs.Find("")
+		require.True(t, ok)
+		assert.Equal(t, balance.Default, db)
+
+		_, ok = bs.Find("unknown")
+		assert.False(t, ok)
+		_, ok = bs.FindByHash("")
+		assert.False(t, ok)
+	})
+	t.Run("File", func(t *testing.T) {
+		bs, err := server.LoadBalances(filepath.Join(dir, "fast.json"))
+		require.NoError(t, err)
+		assert.Equal(t, 10, bs.Default.IncomeTimer)
+		assert.Equal(t, []string{"fast"}, bs.Names())
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := server.LoadBalances(filepath.Join(dir, "none.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestStartLobbyWithBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mwsc := mock.NewMockWSConnector(ctrl)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rich.json"), []byte(`{"game": {"gold": 100}}`), 0644))
+	bs, err := server.LoadBalances(dir)
+	require.NoError(t, err)
+	rich, _ := bs.Find("rich")
+
+	d := flux.NewDispatcher[*action.Action]()
+	s := server.NewStore(d, mwsc, memory.New(), nil, server.Options{Balances: bs}, newEmptyLogger())
+	ad := server.NewActionDispatcher(d, newEmptyLogger(), s, mwsc)
+
+	var (
+		owner = "owner"
+		lid   = "lobby-id"
+	)
+
+	mwsc.EXPECT().SetCodec(gomock.Any(), gomock.Any()).Times(1)
+	var startGames int
+	mwsc.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ *websocket.Conn, d interface{}) error {
+		if a, ok := d.(*action.Action); ok && a.Type == action.StartGame {
+			startGames++
+			assert.Equal(t, rich.Hash(), a.StartGame.BalanceHash)
+		}
+		return nil
+	}).AnyTimes()
+
+	ad.UserSignUp(owner+"-id", owner, "ImageKey", 1500)
+	ad.UserSignIn(owner, "owner-address", codec.JSON, &websocket.Conn{})
+
+	ad.Dispatch(action.NewCreateLobby(lid, owner, "name", 2, "rich", action.GameRules{}))
+	ad.Dispatch(action.NewJoinLobby(lid, "bot", true))
+	ad.Dispatch(action.NewStartLobby(lid))
+
+	r := s.Rooms.FindRoomByID(lid)
+	require.NotNil(t, r)
+	assert.Equal(t, rich, r.Game.Store.Balance())
+
+	u, _ := s.Rooms.FindUserByUsername(owner)
+	assert.Equal(t, 100, r.Game.Game.FindPlayerByID(u.ID).Gold)
+	assert.NotZero(t, startGames)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/rand"
 	"sort"
+	"sync"
 	"time"
 
 	bht "github.com/joeycumines/go-behaviortree"
@@ -14,36 +15,82 @@ import (
 	"github.com/xescugc/maze-wars/unit"
 )
 
+// Strategy is how the Bot chooses between
+// spending the gold on Units or on Towers
+type Strategy string
+
+const (
+	// Random chooses randomly each time
+	Random Strategy = "random"
+	// Attacker prefers the Units over the Towers
+	Attacker Strategy = "attacker"
+	// Defender prefers the Towers over the Units
+	Defender Strategy = "defender"
+)
+
+// Strategies are all the available Strategy
+var Strategies = []Strategy{Random, Attacker, Defender}
+
+// Options are the optional configurations of the Bot
+type Options struct {
+	Strategy Strategy
+
+	// VsBots makes the Bot keep playing when there are no
+	// humans on the Game, used to simulate Games of Bots
+	VsBots bool
+
+	// Rand is the source of the random choices of the Bot so
+	// the Games can be reproduced, if nil it's seeded randomly
+	Rand *rand.Rand
+}
+
 type Bot struct {
 	Ticker bht.Ticker
 
 	dispatcher *flux.Dispatcher[*action.Action]
 	store      *store.Store
 	playerID   string
+	opt        Options
 
 	context     context.Context
 	ctxCancelFn context.CancelFunc
 
+	// startOnce makes the Bot tick only once as
+	// the Rand can not be used concurrently
+	startOnce sync.Once
+
 	towerIDToUpdate   string
 	towerTypeToUpdate string
 }
 
 func New(ctx context.Context, d *flux.Dispatcher[*action.Action], s *store.Store, pid string) *Bot {
+	return NewWithOptions(ctx, d, s, pid, Options{Strategy: Random})
+}
+
+// NewWithOptions returns a new Bot for the player pid configured with opt
+func NewWithOptions(ctx context.Context, d *flux.Dispatcher[*action.Action], s *store.Store, pid string, opt Options) *Bot {
+	if opt.Rand == nil {
+		opt.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
 	b := &Bot{
 		dispatcher: d,
 		store:      s,
 		playerID:   pid,
+		opt:        opt,
 	}
 	b.context, b.ctxCancelFn = context.WithCancel(ctx)
 
 	return b
 }
 
+// Start starts ticking the Bot, calling it again has no effect
 func (b *Bot) Start() {
-	go func() {
-		ticker := bht.NewTicker(b.context, time.Second/4, b.Node())
-		<-ticker.Done()
-	}()
+	b.startOnce.Do(func() {
+		go func() {
+			ticker := bht.NewTicker(b.context, time.Second/4, b.Node())
+			<-ticker.Done()
+		}()
+	})
 }
 
 func (b *Bot) Stop() {
@@ -51,55 +98,75 @@ func (b *Bot) Stop() {
 }
 
 func (b *Bot) Node() bht.Node {
+	//Units
+	units := bht.New(
+		bht.Shuffle(bht.Selector, b.opt.Rand),
+		// Update
+		bht.New(
+			bht.Selector,
+			b.updateUnits()...,
+		),
+		// Summon
+		bht.New(
+			bht.Shuffle(bht.Selector, b.opt.Rand),
+			b.summonUnits()...,
+		),
+	)
+	// Towers
+	towers := bht.New(
+		bht.Shuffle(bht.Selector, b.opt.Rand),
+		// Update
+		bht.New(
+			bht.Sequence,
+			bht.New(b.findTowerToUpdate()),
+			bht.New(b.updateTower()),
+		),
+		// Place
+		bht.New(
+			bht.Shuffle(bht.Selector, b.opt.Rand),
+			b.placeTowers()...,
+		),
+	)
+
+	// The Random one shuffles between Units and Towers
+	// and the others try first the preferred one
+	var spend bht.Node
+	switch b.opt.Strategy {
+	case Attacker:
+		spend = bht.New(bht.Selector, units, towers)
+	case Defender:
+		spend = bht.New(bht.Selector, towers, units)
+	default:
+		spend = bht.New(bht.Shuffle(bht.Selector, b.opt.Rand), units, towers)
+	}
+
 	return bht.New(
 		bht.Sequence,
 		// Will check if it can stop execution
 		bht.New(b.checkWinLoseCondition()),
-		bht.New(
-			bht.Shuffle(bht.Selector, nil),
-			//Units
-			bht.New(
-				bht.Shuffle(bht.Selector, nil),
-				// Update
-				bht.New(
-					bht.Selector,
-					b.updateUnits()...,
-				),
-				// Summon
-				bht.New(
-					bht.Shuffle(bht.Selector, nil),
-					b.summonUnits()...,
-				),
-			),
-			// Towers
-			bht.New(
-				bht.Shuffle(bht.Selector, nil),
-				// Update
-				bht.New(
-					bht.Sequence,
-					bht.New(b.findTowerToUpdate()),
-					bht.New(b.updateTower()),
-				),
-				// Place
-				bht.New(
-					bht.Shuffle(bht.Selector, nil),
-					b.placeTowers()...,
-				),
-			),
-		),
+		spend,
 	)
 }
 
 func (b *Bot) checkWinLoseCondition() func(children []bht.Node) (bht.Status, error) {
 	return func(children []bht.Node) (bht.Status, error) {
-		var thereAreHumans bool
+		var keepPlaying bool
 		for _, p := range b.store.Game.ListPlayers() {
 			if !p.IsBot && p.Lives > 0 && !p.Winner {
-				thereAreHumans = true
+				keepPlaying = true
+			}
+		}
+		// Against Bots it plays until it's eliminated or there is a Winner
+		if b.opt.VsBots {
+			keepPlaying = true
+			for _, p := range b.store.Game.ListPlayers() {
+				if p.Winner || (p.ID == b.playerID && p.Lives == 0) {
+					keepPlaying = false
+				}
 			}
 		}
 		bhts := bht.Failure
-		if thereAreHumans {
+		if keepPlaying {
 			bhts = bht.Success
 		}
 		return bhts, nil
@@ -108,11 +175,17 @@ func (b *Bot) checkWinLoseCondition() func(children []bht.Node) (bht.Status, err
 
 func (b *Bot) updateUnits() []bht.Node {
 	res := make([]bht.Node, 0, 0)
-	units := make([]*unit.Unit, 0, len(unit.Units))
-	for _, u := range unit.Units {
+	bl := b.store.Balance()
+	units := make([]*unit.Unit, 0, len(bl.Units))
+	for _, u := range bl.Units {
 		units = append(units, u)
 	}
-	sort.Slice(units, func(i, j int) bool { return units[i].Gold < units[j].Gold })
+	sort.Slice(units, func(i, j int) bool {
+		if units[i].Gold == units[j].Gold {
+			return units[i].Type < units[j].Type
+		}
+		return units[i].Gold < units[j].Gold
+	})
 	for _, u := range units {
 		res = append(res, bht.New(
 			bht.Sequence,
@@ -126,7 +199,7 @@ func (b *Bot) updateUnits() []bht.Node {
 func (b *Bot) canUpdateUnit(ut string) func(children []bht.Node) (bht.Status, error) {
 	return func(children []bht.Node) (bht.Status, error) {
 		cp := b.store.Game.FindPlayerByID(b.playerID)
-		if cp.CanUpdateUnit(ut) {
+		if cp.CanUpdateUnit(b.store.Rules(), ut) {
 			return bht.Success, nil
 		} else {
 			return bht.Failure, nil
@@ -143,7 +216,14 @@ func (b *Bot) updateUnit(ut string) func(children []bht.Node) (bht.Status, error
 
 func (b *Bot) summonUnits() []bht.Node {
 	res := make([]bht.Node, 0, 0)
+	// The order has to be always the same so the
+	// shuffle only depends on the Rand
+	units := make([]*unit.Unit, 0, len(unit.Units))
 	for _, u := range unit.Units {
+		units = append(units, u)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Type < units[j].Type })
+	for _, u := range units {
 		res = append(res, bht.New(
 			bht.Sequence,
 			bht.New(b.canSummonUnit(u.Type.String())),
@@ -156,7 +236,7 @@ func (b *Bot) summonUnits() []bht.Node {
 func (b *Bot) canSummonUnit(ut string) func(children []bht.Node) (bht.Status, error) {
 	return func(children []bht.Node) (bht.Status, error) {
 		cp := b.store.Game.FindPlayerByID(b.playerID)
-		if cp.CanSummonUnit(ut) {
+		if cp.CanSummonUnit(b.store.Rules(), ut) {
 			return bht.Success, nil
 		} else {
 			return bht.Failure, nil
@@ -178,16 +258,16 @@ func (b *Bot) summonUnit(ut string) func(children []bht.Node) (bht.Status, error
 func (b *Bot) findTowerToUpdate() func(children []bht.Node) (bht.Status, error) {
 	return func(children []bht.Node) (bht.Status, error) {
 		cp := b.store.Game.FindPlayerByID(b.playerID)
-		for _, t := range b.store.Game.FindLineByID(cp.LineID).Towers {
+		for _, t := range b.store.Game.FindLineByID(cp.LineID).ListSortedTowers() {
 			tus := tower.Towers[t.Type].Updates
 			if len(tus) == 0 {
 				continue
 			}
-			i := rand.Intn(len(tus))
-			tu := tower.Towers[tus[i].String()]
-			if cp.Gold-tu.Gold > 0 {
+			i := b.opt.Rand.Intn(len(tus))
+			tu := b.store.Balance().Tower(tus[i])
+			if cp.Gold-tu.Gold > 0 && b.store.Rules().AllowsTower(tu.Type) {
 				b.towerIDToUpdate = t.ID
-				b.towerTypeToUpdate = tu.Type.String()
+				b.towerTypeToUpdate = tu.Type
 				return bht.Success, nil
 			}
 		}
@@ -209,8 +289,8 @@ func (b *Bot) placeTowers() []bht.Node {
 	for _, t := range tower.FirstTowers {
 		res = append(res, bht.New(
 			bht.Sequence,
-			bht.New(b.canPlaceTower(t.Type.String())),
-			bht.New(b.placeTower(t.Type.String())),
+			bht.New(b.canPlaceTower(t.Type)),
+			bht.New(b.placeTower(t.Type)),
 		))
 	}
 	return res
@@ -219,7 +299,7 @@ func (b *Bot) placeTowers() []bht.Node {
 func (b *Bot) canPlaceTower(tt string) func(children []bht.Node) (bht.Status, error) {
 	return func(children []bht.Node) (bht.Status, error) {
 		cp := b.store.Game.FindPlayerByID(b.playerID)
-		if cp.CanPlaceTower(tt) {
+		if cp.CanPlaceTower(b.store.Balance(), b.store.Rules(), tt) {
 			return bht.Success, nil
 		} else {
 			return bht.Failure, nil
@@ -236,6 +316,8 @@ func (b *Bot) placeTower(tt string) func(children []bht.Node) (bht.Status, error
 		x += 16                                                          // Move one tile away so we are inside and not in the border
 		y += 16 + (cl.Graph.SpawnZoneH * cl.Graph.Scale) + 16 + (5 * 16) // Top border + H zone + Go to the next one + (10 tiles of the top)
 
+		w, h := b.store.Balance().Tower(tt).Size()
+
 		tl := len(cl.Towers) / 7
 		ts := len(cl.Towers) % 7
 
@@ -244,7 +326,7 @@ func (b *Bot) placeTower(tt string) func(children []bht.Node) (bht.Status, error
 			return bht.Failure, nil
 		}
 		// For each line of tower we add the difference
-		y += tl * (32 + 16)
+		y += tl * (h + 16)
 
 		// If it's odd we start from the left
 		// If it's even we start from the right
@@ -252,10 +334,10 @@ func (b *Bot) placeTower(tt string) func(children []bht.Node) (bht.Status, error
 		// odd-even play
 		if (tl+1)%2 == 0 {
 			// We take it to the end
-			x += 32 * 7
-			x -= 32 * ts
+			x += w * 7
+			x -= w * ts
 		} else {
-			x += 32 * ts
+			x += w * ts
 		}
 
 		// There are max of 7 towers in one line
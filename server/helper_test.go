@@ -7,6 +7,7 @@ import (
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
 	"github.com/xescugc/maze-wars/server"
+	"github.com/xescugc/maze-wars/server/storage/memory"
 )
 
 func newEmptyLogger() *slog.Logger {
@@ -16,6 +17,6 @@ func newEmptyLogger() *slog.Logger {
 func initStore(ws server.WSConnector) (*server.ActionDispatcher, *server.Store) {
 	d := flux.NewDispatcher[*action.Action]()
 	l := slog.New(slog.NewTextHandler(io.Discard, nil))
-	s := server.NewStore(d, ws, nil, server.Options{}, newEmptyLogger())
+	s := server.NewStore(d, ws, memory.New(), nil, server.Options{}, newEmptyLogger())
 	return server.NewActionDispatcher(d, l, s, ws), s
 }
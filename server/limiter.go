@@ -0,0 +1,51 @@
+package server
+
+import (
+	"time"
+)
+
+const (
+	// actionsRate is the number of actions per second
+	// a connection can send on a sustained way
+	actionsRate = 20
+	// actionsBurst is the number of actions a
+	// connection can send at once
+	actionsBurst = 40
+)
+
+// Limiter is a token bucket used to limit the
+// actions that each connection can send
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a new Limiter that allows r
+// actions per second with bursts of up to b
+func NewLimiter(r, b int) *Limiter {
+	return &Limiter{
+		rate:   float64(r),
+		burst:  float64(b),
+		tokens: float64(b),
+	}
+}
+
+// Allow checks if one more action can be done on the time now
+func (l *Limiter) Allow(now time.Time) bool {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,33 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xescugc/maze-wars/server"
+)
+
+func TestLimiter(t *testing.T) {
+	l := server.NewLimiter(2, 3)
+	now := time.Now()
+
+	// The burst is allowed at once
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow(now))
+	}
+	assert.False(t, l.Allow(now))
+
+	// Each second the rate is recovered
+	now = now.Add(time.Second)
+	assert.True(t, l.Allow(now))
+	assert.True(t, l.Allow(now))
+	assert.False(t, l.Allow(now))
+
+	// But never more than the burst
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow(now))
+	}
+	assert.False(t, l.Allow(now))
+}
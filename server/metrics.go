@@ -30,6 +30,17 @@ var (
 		},
 	)
 
+	numberOfRejectedActions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "maze_wars",
+			Name:      "number_of_rejected_actions_count",
+		},
+		[]string{
+			"type",
+			"reason",
+		},
+	)
+
 	numberOfActions = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "maze_wars",
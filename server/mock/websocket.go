@@ -10,6 +10,7 @@ import (
 
 	websocket "github.com/coder/websocket"
 	gomock "github.com/golang/mock/gomock"
+	codec "github.com/xescugc/maze-wars/codec"
 )
 
 // MockWSConnector is a mock of WSConnector interface.
@@ -49,6 +50,18 @@ func (mr *MockWSConnectorMockRecorder) Read(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockWSConnector)(nil).Read), arg0, arg1, arg2)
 }
 
+// SetCodec mocks base method.
+func (m *MockWSConnector) SetCodec(arg0 *websocket.Conn, arg1 codec.Codec) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCodec", arg0, arg1)
+}
+
+// SetCodec indicates an expected call of SetCodec.
+func (mr *MockWSConnectorMockRecorder) SetCodec(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCodec", reflect.TypeOf((*MockWSConnector)(nil).SetCodec), arg0, arg1)
+}
+
 // Write mocks base method.
 func (m *MockWSConnector) Write(arg0 context.Context, arg1 *websocket.Conn, arg2 interface{}) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,7 @@
+package models
+
+type BalancesResponse struct {
+	// Balances are the names of the balance
+	// packs that can be picked on a lobby
+	Balances []string `json:"balances"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type GamesResponse struct {
+	Games []GameResponse `json:"games"`
+}
+
+type GameResponse struct {
+	ID string `json:"id"`
+
+	// Players holds the names of
+	// the players of the Game
+	Players []string `json:"players"`
+
+	StartedAt time.Time `json:"started_at"`
+}
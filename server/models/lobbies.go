@@ -8,6 +8,7 @@ type LobbyResponse struct {
 	ID         string `json:"id"`
 	Name       string `json:"name"`
 	MaxPlayers int    `json:"max_players"`
+	Balance    string `json:"balance"`
 
 	// Players holds the usernames
 	// including the owner one
@@ -0,0 +1,9 @@
+package models
+
+// SessionResponse is returned when signing up or in, the
+// Token is the one to use on the websocket UserSignIn
+type SessionResponse struct {
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	ImageKey string `json:"image_key"`
+}
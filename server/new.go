@@ -3,14 +3,16 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/coder/websocket"
-	"github.com/coder/websocket/wsjson"
+	"github.com/gofrs/uuid"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -19,6 +21,8 @@ import (
 	"github.com/xescugc/maze-wars/action"
 	"github.com/xescugc/maze-wars/server/assets"
 	"github.com/xescugc/maze-wars/server/models"
+	"github.com/xescugc/maze-wars/server/rating"
+	"github.com/xescugc/maze-wars/server/storage"
 	"github.com/xescugc/maze-wars/server/templates"
 	"github.com/xescugc/maze-wars/unit"
 
@@ -54,9 +58,13 @@ func New(ad *ActionDispatcher, s *Store, opt Options) error {
 
 	// Game Endpoints
 	r.HandleFunc("/users", usersCreateHandler(s)).Methods(http.MethodPost).Headers("Content-Type", "application/json")
+	r.HandleFunc("/sessions", sessionsCreateHandler(s)).Methods(http.MethodPost).Headers("Content-Type", "application/json")
 	r.HandleFunc("/version", versionHandler(opt.Version)).Methods(http.MethodPost).Headers("Content-Type", "application/json")
 
 	r.HandleFunc("/lobbies", listLobbiesHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/games", listGamesHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/balances", listBalancesHandler(s)).Methods(http.MethodGet)
+	r.HandleFunc("/balances/{hash}", getBalanceHandler(s)).Methods(http.MethodGet)
 
 	hmux := http.NewServeMux()
 	hmux.Handle("/", r)
@@ -130,6 +138,10 @@ func docsHandler(v string) func(w http.ResponseWriter, r *http.Request) {
 type usersCreateRequest struct {
 	Username string `json:"username"`
 	ImageKey string `json:"image_key"`
+
+	// Password is optional, without it the Account
+	// can only be used with the Session token
+	Password string `json:"password"`
 }
 
 type errorResponse struct {
@@ -156,23 +168,129 @@ func usersCreateHandler(s *Store) func(http.ResponseWriter, *http.Request) {
 			ucr.ImageKey = unit.TypeStrings()[0]
 		}
 
-		if _, ok := s.Rooms.FindUserByUsername(ucr.Username); ok {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(errorResponse{Error: "User already exists"})
+		now := time.Now()
+		a := &storage.Account{
+			ID:           uuid.Must(uuid.NewV4()).String(),
+			Username:     ucr.Username,
+			ImageKey:     ucr.ImageKey,
+			Rating:       rating.Default,
+			CreatedAt:    now,
+			LastSignInAt: now,
+		}
+		if ucr.Password != "" {
+			err = a.SetPassword(ucr.Password)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+				return
+			}
+		}
+
+		err = s.Accounts.CreateAccount(r.Context(), a)
+		if err != nil {
+			if errors.Is(err, storage.ErrAlreadyExists) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(errorResponse{Error: "User already exists"})
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
 			return
 		}
 
-		if _, ok := s.Rooms.FindUserByRemoteAddress(r.RemoteAddr); ok {
+		writeSession(w, r, s, a, now)
+	}
+}
+
+type sessionsCreateRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Token is a previous Session token, if present it's used
+	// instead of the Username and Password and it's replaced
+	// by a new one
+	Token string `json:"token"`
+
+	// ImageKey if present updates the one of the Account
+	ImageKey string `json:"image_key"`
+}
+
+func sessionsCreateHandler(s *Store) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var scr sessionsCreateRequest
+
+		err := json.NewDecoder(r.Body).Decode(&scr)
+		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(errorResponse{Error: "A session already exists from this computer"})
+			json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
 			return
 		}
 
-		actionDispatcher.UserSignUp(ucr.Username, ucr.ImageKey)
+		now := time.Now()
+		var a *storage.Account
+		if scr.Token != "" {
+			a, err = storage.FindAccountByToken(r.Context(), s.Accounts, scr.Token, now)
+		} else {
+			a, err = s.Accounts.FindAccountByUsername(r.Context(), scr.Username)
+			if err == nil && !a.CheckPassword(scr.Password) {
+				err = storage.ErrNotFound
+			}
+		}
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(errorResponse{Error: "Invalid username or password"})
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+			return
+		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
+		if scr.ImageKey != "" {
+			a.ImageKey = scr.ImageKey
+		}
+		a.LastSignInAt = now
+		err = s.Accounts.UpdateAccount(r.Context(), a)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+			return
+		}
+
+		if scr.Token != "" {
+			err = s.Accounts.DeleteSession(r.Context(), storage.HashToken(scr.Token))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+				return
+			}
+		}
+
+		writeSession(w, r, s, a, now)
+	}
+}
+
+// writeSession creates a new Session for the Account a
+// and writes the token with the profile of it
+func writeSession(w http.ResponseWriter, r *http.Request, s *Store, a *storage.Account, now time.Time) {
+	ss, tk, err := storage.NewSession(a.ID, now)
+	if err == nil {
+		err = s.Accounts.CreateSession(r.Context(), ss)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.SessionResponse{
+		Token:    tk,
+		Username: a.Username,
+		ImageKey: a.ImageKey,
+	})
 }
 
 func listLobbiesHandler(s *Store) func(http.ResponseWriter, *http.Request) {
@@ -187,6 +305,7 @@ func listLobbiesHandler(s *Store) func(http.ResponseWriter, *http.Request) {
 				ID:         l.ID,
 				Name:       l.Name,
 				MaxPlayers: l.MaxPlayers,
+				Balance:    l.Balance,
 				Owner:      l.Owner,
 				Players:    l.Players,
 			}
@@ -199,6 +318,65 @@ func listLobbiesHandler(s *Store) func(http.ResponseWriter, *http.Request) {
 	}
 }
 
+func listGamesHandler(s *Store) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rooms := s.Rooms.ListRooms()
+		respGames := models.GamesResponse{
+			Games: make([]models.GameResponse, 0, len(rooms)),
+		}
+
+		for _, rm := range rooms {
+			// The Game is not started yet
+			if rm.Game == nil {
+				continue
+			}
+			gr := models.GameResponse{
+				ID:        rm.Name,
+				StartedAt: rm.StartedAt,
+			}
+			for _, p := range rm.Game.Store.Game.ListPlayers() {
+				gr.Players = append(gr.Players, p.Name)
+			}
+			sort.Strings(gr.Players)
+			respGames.Games = append(respGames.Games, gr)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(respGames)
+	}
+}
+
+func listBalancesHandler(s *Store) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respBalances := models.BalancesResponse{
+			Balances: s.Rooms.options.Balances.Names(),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(respBalances)
+	}
+}
+
+// getBalanceHandler returns the raw balance pack so the
+// clients can simulate the Game with the same numbers
+func getBalanceHandler(s *Store) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, ok := s.Rooms.options.Balances.FindByHash(mux.Vars(r)["hash"])
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(errorResponse{Error: "balance not found"})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(b.Raw())
+	}
+}
+
 type versionRequest struct {
 	Version string `json:"version"`
 }
@@ -238,31 +416,100 @@ func wsHandler(s *Store) func(http.ResponseWriter, *http.Request) {
 		}
 		defer ws.CloseNow()
 
+		// un is the Username of the User of this
+		// connection, it's set on the UserSignIn
+		var un string
+		lim := NewLimiter(actionsRate, actionsBurst)
 		for {
 			var msg action.Action
 			// If there is an error parsing the msg
 			// we kick the user
-			err := wsjson.Read(hr.Context(), ws, &msg)
+			err := actionDispatcher.ws.Read(hr.Context(), ws, &msg)
 			if err != nil {
-				// We cannot move this 'u' call outside as the Read
-				// block until a new message is received so it may have
-				// a wrong value stored inside
-				u, _ := s.Rooms.FindUserByRemoteAddress(hr.RemoteAddr)
 				fmt.Printf("Error when reading the WS message: %s\n", err)
 
-				actionDispatcher.UserSignOut(u.Username)
+				if un != "" {
+					actionDispatcher.UserDisconnect(un, ws)
+				}
+				actionDispatcher.ws.SetCodec(ws, nil)
 				break
 			}
 
-			u, _ := s.Rooms.FindUserByRemoteAddress(hr.RemoteAddr)
+			if !lim.Allow(time.Now()) {
+				actionDispatcher.rejectAction(un, &msg, ErrRateLimited)
+				continue
+			}
+
+			// Until the User is signed in with a valid
+			// Session any other action is ignored
+			if un == "" {
+				if msg.Type != action.UserSignIn {
+					continue
+				}
+				a, err := storage.FindAccountByToken(hr.Context(), s.Accounts, msg.UserSignIn.Token, time.Now())
+				if err != nil {
+					ws.Close(websocket.StatusPolicyViolation, "Invalid session")
+					break
+				}
+				// A User can only have one connection, with the ResumeToken
+				// it replaces the previous one and resumes the Game
+				if u, ok := s.Rooms.FindUserByUsername(a.Username); ok {
+					resume := msg.UserSignIn.ResumeToken != "" && msg.UserSignIn.ResumeToken == u.ResumeToken
+					if !resume && u.DisconnectedAt.IsZero() {
+						ws.Close(websocket.StatusPolicyViolation, "The user is already connected")
+						break
+					}
+					if !resume {
+						actionDispatcher.UserSignOut(a.Username)
+					} else if u.DisconnectedAt.IsZero() {
+						actionDispatcher.UserDisconnect(a.Username, u.Conn)
+					}
+				}
+				if _, ok := s.Rooms.FindUserByUsername(a.Username); !ok {
+					actionDispatcher.UserSignUp(a.ID, a.Username, a.ImageKey, a.Rating)
+				}
+				un = a.Username
+				actionDispatcher.UserSignIn(a.Username, hr.RemoteAddr, msg.UserSignIn.Codec, ws)
+				actionDispatcher.SyncUser(a.Username, a.Rating, 0)
+				continue
+			}
+
+			u, _ := s.Rooms.FindUserByUsername(un)
 
 			// If the User is in a Room we set it directly on the
 			// action from the handler
 			msg.Room = u.CurrentRoomID
 
+			// Spectators can only watch the Game so any
+			// other action is ignored until they exit
+			if u.SpectatingRoomID != "" && msg.Type != action.ExitSpectateGame && msg.Type != action.AckSyncState && msg.Type != action.UserSignOut {
+				continue
+			}
+
+			// The client can only act for its own Player
+			err = s.Rooms.ValidateAction(un, &msg)
+			if err != nil {
+				actionDispatcher.rejectAction(un, &msg, err)
+				continue
+			}
+
 			switch msg.Type {
-			case action.UserSignIn:
-				actionDispatcher.UserSignIn(*&msg.UserSignIn.Username, hr.RemoteAddr, ws)
+			case action.UserSignIn, action.SyncUser, action.UserDisconnect:
+				// The User is already signed in and
+				// only the server can sync it
+			case action.UserSignOut:
+				msg.UserSignOut.Username = u.Username
+				actionDispatcher.Dispatch(&msg)
+				un = ""
+			case action.SpectateGame:
+				msg.SpectateGame.Username = u.Username
+				actionDispatcher.Dispatch(&msg)
+			case action.ExitSpectateGame:
+				msg.ExitSpectateGame.Username = u.Username
+				actionDispatcher.Dispatch(&msg)
+			case action.AckSyncState:
+				msg.AckSyncState.Username = u.Username
+				actionDispatcher.Dispatch(&msg)
 			case action.RemovePlayer:
 				actionDispatcher.Dispatch(&msg)
 			default:
@@ -296,6 +543,7 @@ func startLoop(ctx context.Context, s *Store) {
 			actionDispatcher.IncomeTick()
 			actionDispatcher.Dispatch(&action.Action{Type: action.SyncLobbies})
 			actionDispatcher.Dispatch(&action.Action{Type: action.SyncWaitingRooms})
+			actionDispatcher.signOutDisconnectedUsers()
 		case <-ctx.Done():
 			stateTicker.Stop()
 			secondTicker.Stop()
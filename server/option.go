@@ -13,4 +13,17 @@ type Options struct {
 	Version          string
 	DiscordBotToken  string
 	DiscordChannelID string
+
+	// ReplaysDir is the directory in which the replays of the
+	// games are stored when they end, if empty they are not stored
+	ReplaysDir string
+
+	// DBPath is the path to the SQLite database in which
+	// the accounts are stored, if empty they are only
+	// kept in memory
+	DBPath string
+
+	// Balances are the balance packs the Games can use,
+	// if nil only the default balance is used
+	Balances *Balances
 }
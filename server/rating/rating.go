@@ -0,0 +1,69 @@
+package rating
+
+import (
+	"math"
+)
+
+const (
+	// Default is the rating of a new player
+	Default = 1500
+
+	// K is the maximum rating change of a Game
+	K = 32
+)
+
+// Player is a participant of a rated Game
+type Player struct {
+	ID     string
+	Rating int
+
+	// Place is the final position on the Game
+	// starting from 1, players with the same
+	// Place are considered a draw
+	Place int
+}
+
+// Compute returns the new rating of each player using a multiplayer Elo.
+// Each player is compared against each other one as if it was a 1v1
+// with the result given by the Place, and the changes are averaged
+// so the maximum change is always K no matter the number of players
+func Compute(ps []Player) map[string]int {
+	res := make(map[string]int, len(ps))
+	if len(ps) < 2 {
+		for _, p := range ps {
+			res[p.ID] = p.Rating
+		}
+		return res
+	}
+
+	for _, p := range ps {
+		var d float64
+		for _, op := range ps {
+			if op.ID == p.ID {
+				continue
+			}
+			d += score(p.Place, op.Place) - expected(p.Rating, op.Rating)
+		}
+		res[p.ID] = p.Rating + int(math.Round(K*d/float64(len(ps)-1)))
+	}
+
+	return res
+}
+
+// expected is the expected score of a player with
+// rating r against an opponent with rating or
+func expected(r, or int) float64 {
+	return 1 / (1 + math.Pow(10, float64(or-r)/400))
+}
+
+// score is the result of the place p against the opponent one op
+func score(p, op int) float64 {
+	switch {
+	case p < op:
+		return 1
+	case p > op:
+		return 0
+	default:
+		return 0.5
+	}
+}
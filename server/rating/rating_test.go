@@ -0,0 +1,53 @@
+package rating_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xescugc/maze-wars/server/rating"
+)
+
+func TestCompute(t *testing.T) {
+	t.Run("1v1", func(t *testing.T) {
+		res := rating.Compute([]rating.Player{
+			{ID: "p1", Rating: rating.Default, Place: 1},
+			{ID: "p2", Rating: rating.Default, Place: 2},
+		})
+		assert.Equal(t, map[string]int{
+			"p1": rating.Default + rating.K/2,
+			"p2": rating.Default - rating.K/2,
+		}, res)
+	})
+	t.Run("Upset", func(t *testing.T) {
+		res := rating.Compute([]rating.Player{
+			{ID: "p1", Rating: 1400, Place: 1},
+			{ID: "p2", Rating: 1800, Place: 2},
+		})
+		// The lower rated player wins more than the
+		// higher rated one would have won
+		assert.Equal(t, map[string]int{
+			"p1": 1429,
+			"p2": 1771,
+		}, res)
+	})
+	t.Run("Multiplayer", func(t *testing.T) {
+		res := rating.Compute([]rating.Player{
+			{ID: "p1", Rating: rating.Default, Place: 1},
+			{ID: "p2", Rating: rating.Default, Place: 2},
+			{ID: "p3", Rating: rating.Default, Place: 3},
+			{ID: "p4", Rating: rating.Default, Place: 3},
+		})
+		assert.Equal(t, map[string]int{
+			"p1": rating.Default + 16,
+			"p2": rating.Default + 5,
+			"p3": rating.Default - 11,
+			"p4": rating.Default - 11,
+		}, res)
+	})
+	t.Run("Alone", func(t *testing.T) {
+		res := rating.Compute([]rating.Player{
+			{ID: "p1", Rating: rating.Default, Place: 1},
+		})
+		assert.Equal(t, map[string]int{"p1": rating.Default}, res)
+	})
+}
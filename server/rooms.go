@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -15,8 +18,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/replay"
 	"github.com/xescugc/maze-wars/server/bot"
 	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/utils"
 )
 
 type RoomsStore struct {
@@ -55,6 +61,18 @@ const (
 	RoomTypePlayers = "players"
 )
 
+const (
+	// reconnectGracePeriod is the time a User has to
+	// reconnect to a Game before it's removed from it
+	reconnectGracePeriod = time.Minute
+)
+
+const (
+	ratingWindowBase     = 100
+	ratingWindowGrowth   = 50
+	ratingWindowInterval = 10 * time.Second
+)
+
 type Room struct {
 	Name string
 
@@ -62,6 +80,10 @@ type Room struct {
 
 	Players map[string]PlayerConn
 
+	// Spectators are the users watching the Game
+	// the key is the User.ID
+	Spectators map[string]*User
+
 	Connections map[string]string
 
 	// This is used so when the Room ends we
@@ -75,8 +97,31 @@ type Room struct {
 
 	Ranked bool
 
+	// Balance is the balance pack of the Game,
+	// if nil it's the default of the server
+	Balance *balance.Balance
+
+	// Rules are the GameRules picked on the lobby
+	Rules action.GameRules
+
 	Game *Game
 
+	// Recorder records the Game so it can
+	// be stored as a replay when it ends
+	Recorder *replay.Recorder
+
+	// Snapshots are the SyncStates sent to the
+	// users, used to only send the changes
+	Snapshots *Snapshots
+
+	// Standings are the places of the players
+	// used to rate them, only on Ranked Rooms
+	Standings *Standings
+
+	// SearchID is the kind of game the
+	// Room is searching players for
+	SearchID string
+
 	SearchingSince time.Time
 	WaitingSince   time.Time
 
@@ -86,10 +131,32 @@ type Room struct {
 	// StartedAt is the time in which the Game started
 	StartedAt time.Time
 
+	// Seed is the seed used for the Game simulation
+	Seed int64
+
+	// Dispatcher is the one of the Game
+	Dispatcher *flux.Dispatcher[*action.Action]
+
 	// LFGMessageID is the ID of the message, used so we can delete it if the game starts
 	LFGMessageID string
 }
 
+// Rating is the average Rating of the players
+func (r Room) Rating() int {
+	var sum, n int
+	for _, pc := range r.Players {
+		if pc.IsBot {
+			continue
+		}
+		sum += pc.User.Rating
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
+
 func (r Room) LFGMessage() string {
 	rk := "Unranked"
 	if r.Ranked {
@@ -103,11 +170,24 @@ type User struct {
 	Username string
 	ImageKey string
 
+	// Rating is the ranked Rating
+	Rating int
+
 	Conn       *websocket.Conn
 	RemoteAddr string
 
+	// ResumeToken is the token needed to resume
+	// the current Game if the connection is lost
+	ResumeToken string
+	// DisconnectedAt is when the connection was lost
+	// while on a Game, it's zero if it's connected
+	DisconnectedAt time.Time
+
 	CurrentRoomID  string
 	CurrentLobbyID string
+
+	// SpectatingRoomID is the Room the user is watching
+	SpectatingRoomID string
 }
 
 type PlayerConn struct {
@@ -210,24 +290,6 @@ func (rs *RoomsStore) FindUserByID(uid string) (User, bool) {
 	return User{}, false
 }
 
-func (rs *RoomsStore) FindUserByRemoteAddress(ra string) (User, bool) {
-	rs.mxRooms.RLock()
-	defer rs.mxRooms.RUnlock()
-
-	state := rs.GetState()
-
-	return rs.findUserByRemoteAddress(state, ra)
-}
-
-func (rs *RoomsStore) findUserByRemoteAddress(state RoomsState, ra string) (User, bool) {
-	for _, u := range state.Users {
-		if u.RemoteAddr == ra {
-			return *u, true
-		}
-	}
-	return User{}, false
-}
-
 func (rs *RoomsStore) ListUsers() []*User {
 	rs.mxRooms.RLock()
 	defer rs.mxRooms.RUnlock()
@@ -246,11 +308,11 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 		rs.mxRooms.Lock()
 		defer rs.mxRooms.Unlock()
 
-		id := uuid.Must(uuid.NewV4())
 		state.Users[act.UserSignUp.Username] = &User{
-			ID:       id.String(),
+			ID:       act.UserSignUp.ID,
 			Username: act.UserSignUp.Username,
 			ImageKey: act.UserSignUp.ImageKey,
+			Rating:   act.UserSignUp.Rating,
 		}
 
 		currentNumberOfPlayers.Inc()
@@ -261,7 +323,30 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 		if u, ok := state.Users[act.UserSignIn.Username]; ok {
 			u.Conn = act.UserSignIn.Websocket
 			u.RemoteAddr = act.UserSignIn.RemoteAddr
+			if !u.DisconnectedAt.IsZero() {
+				rs.resumeUser(&state, u)
+			}
 		}
+	case action.UserDisconnect:
+		rs.mxRooms.Lock()
+		defer rs.mxRooms.Unlock()
+
+		u, ok := state.Users[act.UserDisconnect.Username]
+		if !ok {
+			break
+		}
+		r, ok := state.Rooms[u.CurrentRoomID]
+		if !ok || r.Game == nil {
+			break
+		}
+
+		u.Conn = nil
+		u.DisconnectedAt = time.Now()
+
+		// Meanwhile a Bot plays for the User
+		b := bot.New(r.Context, r.Dispatcher, r.Game.Store, u.ID)
+		r.Bots[u.ID] = b
+		b.Start()
 	case action.UserSignOut:
 		rs.mxRooms.Lock()
 		defer rs.mxRooms.Unlock()
@@ -270,6 +355,9 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 		if ok && u.CurrentRoomID != "" {
 			rs.removePlayer(&state, u.ID, u.CurrentRoomID)
 		}
+		if ok && u.SpectatingRoomID != "" {
+			rs.removeSpectator(&state, u)
+		}
 
 		delete(state.Users, act.UserSignOut.Username)
 		currentNumberOfPlayers.Dec()
@@ -314,12 +402,15 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 			Name:        l.ID,
 			Type:        RoomTypeLobbies,
 			Players:     make(map[string]PlayerConn),
+			Spectators:  make(map[string]*User),
 			Connections: make(map[string]string),
 			Bots:        make(map[string]*bot.Bot),
 
 			Size:      l.MaxPlayers,
 			Countdown: 10,
 		}
+		r.Balance, _ = rs.options.Balances.Find(l.Balance)
+		r.Rules = l.Rules
 
 		for p, ib := range l.Players {
 			if ib {
@@ -356,13 +447,16 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 		if act.FindGame.VsBots {
 			ty = RoomTypeBots
 		}
-		sr, ok := state.Searching[sID]
-		if !ok {
+		us, _ := state.Users[act.FindGame.Username]
+		sr := rs.findSearchingRoom(state, sID, us, act.FindGame.Ranked, time.Now())
+		if sr == nil {
 			rid := uuid.Must(uuid.NewV4())
 			sr = &Room{
 				Name:        rid.String(),
 				Type:        ty,
+				SearchID:    sID,
 				Players:     make(map[string]PlayerConn),
+				Spectators:  make(map[string]*User),
 				Connections: make(map[string]string),
 				Bots:        make(map[string]*bot.Bot),
 
@@ -385,7 +479,6 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 			}
 		}
 
-		us, _ := state.Users[act.FindGame.Username]
 		sr.Players[us.ID] = PlayerConn{
 			User: us,
 		}
@@ -398,7 +491,7 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 			sr.WaitingSince = time.Now()
 			state.Waiting[sr.Name] = sr
 
-			delete(state.Searching, sID)
+			delete(state.Searching, sr.Name)
 		} else {
 			if act.FindGame.VsBots {
 				// If we play against bots we'll add the Bots, and make them all Accept
@@ -414,7 +507,7 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 				}
 				state.Waiting[sr.Name] = sr
 			} else {
-				state.Searching[sID] = sr
+				state.Searching[sr.Name] = sr
 			}
 		}
 	case action.ExitSearchingGame:
@@ -489,6 +582,72 @@ func (rs *RoomsStore) Reduce(state RoomsState, act *action.Action) RoomsState {
 			}
 		}
 
+	case action.SpectateGame:
+		rs.mxRooms.Lock()
+		defer rs.mxRooms.Unlock()
+
+		u, ok := state.Users[act.SpectateGame.Username]
+		if !ok || u.CurrentRoomID != "" || u.SpectatingRoomID != "" {
+			break
+		}
+		r, ok := state.Rooms[act.SpectateGame.RoomID]
+		if !ok || r.Game == nil {
+			break
+		}
+
+		r.Spectators[u.ID] = u
+		u.SpectatingRoomID = r.Name
+
+		// Spectators have no current player so the
+		// state is synced without one
+		sga := action.NewStartGame(rs.SyncState(r, ""), r.Seed)
+		sga.StartGame.BalanceHash = r.Game.Store.Balance().Hash()
+		sga.StartGame.Rules = r.Rules
+		err := rs.ws.Write(context.Background(), u.Conn, sga)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case action.ExitSpectateGame:
+		rs.mxRooms.Lock()
+		defer rs.mxRooms.Unlock()
+
+		u, ok := state.Users[act.ExitSpectateGame.Username]
+		if !ok || u.SpectatingRoomID == "" {
+			break
+		}
+		rs.removeSpectator(&state, u)
+	case action.AckSyncState:
+		rs.mxRooms.RLock()
+		defer rs.mxRooms.RUnlock()
+
+		u, ok := state.Users[act.AckSyncState.Username]
+		if !ok {
+			break
+		}
+		rid := u.CurrentRoomID
+		if rid == "" {
+			rid = u.SpectatingRoomID
+		}
+		if r, ok := state.Rooms[rid]; ok && r.Snapshots != nil {
+			r.Snapshots.Ack(u.ID, act.AckSyncState.SnapshotID)
+		}
+	case action.SyncUser:
+		rs.mxRooms.Lock()
+		defer rs.mxRooms.Unlock()
+
+		u, ok := state.Users[act.SyncUser.Username]
+		if !ok {
+			break
+		}
+		u.Rating = act.SyncUser.Rating
+		if u.Conn == nil {
+			break
+		}
+
+		err := rs.ws.Write(context.Background(), u.Conn, act)
+		if err != nil {
+			log.Fatal(err)
+		}
 	case action.RemovePlayer:
 		rs.mxRooms.Lock()
 		defer rs.mxRooms.Unlock()
@@ -557,18 +716,23 @@ func (rs *RoomsStore) removePlayer(state *RoomsState, pid, room string) {
 	if room != "" {
 		pc := state.Rooms[room].Players[pid]
 		delete(state.Rooms[room].Players, pid)
+		state.Rooms[room].Snapshots.Remove(pid)
 		delete(state.Rooms[room].Connections, pc.User.RemoteAddr)
 
 		state.Rooms[room].Game.Dispatch(action.NewRemovePlayer(pid))
 
-		if pc.IsBot {
-			state.Rooms[room].Bots[pid].Stop()
+		// The Bot can also be the one playing
+		// for a disconnected User
+		if b, ok := state.Rooms[room].Bots[pid]; ok {
+			b.Stop()
 			delete(state.Rooms[room].Bots, pid)
 		}
 
 		r := state.Rooms[room]
 		if len(r.Players) == 0 {
 			delete(state.Rooms, room)
+			rs.notifySpectatorsRoomDeleted(r)
+			rs.saveReplay(r)
 			currentNumberOfGames.Dec()
 			if Environment == "prod" {
 				err := rs.discord.ChannelMessageDelete(rs.options.DiscordChannelID, r.LFGMessageID)
@@ -591,6 +755,8 @@ func (rs *RoomsStore) removePlayer(state *RoomsState, pid, room string) {
 				b.Stop()
 			}
 			delete(state.Rooms, room)
+			rs.notifySpectatorsRoomDeleted(r)
+			rs.saveReplay(r)
 			currentNumberOfGames.Dec()
 			if Environment == "prod" {
 				err := rs.discord.ChannelMessageDelete(rs.options.DiscordChannelID, r.LFGMessageID)
@@ -614,14 +780,170 @@ func (rs *RoomsStore) removePlayer(state *RoomsState, pid, room string) {
 	}
 }
 
+// findSearchingRoom returns the Searching Room of the kind sID that the User u
+// can join. If it's ranked it's the one with the closest Rating to the User
+// that is inside the ratingWindow of the Room.
+func (rs *RoomsStore) findSearchingRoom(state RoomsState, sID string, u *User, ranked bool, now time.Time) *Room {
+	var (
+		best     *Room
+		bestDiff int
+	)
+	for _, sr := range state.Searching {
+		if sr.SearchID != sID {
+			continue
+		}
+		if !ranked {
+			return sr
+		}
+		d := sr.Rating() - u.Rating
+		if d < 0 {
+			d = -d
+		}
+		if d > ratingWindow(now.Sub(sr.SearchingSince)) {
+			continue
+		}
+		if best == nil || d < bestDiff {
+			best, bestDiff = sr, d
+		}
+	}
+	return best
+}
+
+// ratingWindow is the max Rating difference between a player and a
+// Searching Room to join it, it grows the longer the Room is
+// searching so at some point anyone can join it
+func ratingWindow(d time.Duration) int {
+	return ratingWindowBase + ratingWindowGrowth*int(d/ratingWindowInterval)
+}
+
+// resumeUser resumes the Game of the User u that was disconnected
+// by stopping the Bot playing for it and sending the full state
+func (rs *RoomsStore) resumeUser(state *RoomsState, u *User) {
+	u.DisconnectedAt = time.Time{}
+
+	r, ok := state.Rooms[u.CurrentRoomID]
+	if !ok || r.Game == nil {
+		return
+	}
+	if b, ok := r.Bots[u.ID]; ok {
+		b.Stop()
+		delete(r.Bots, u.ID)
+	}
+	// The client starts again so it has no snapshots
+	r.Snapshots.Remove(u.ID)
+
+	err := rs.ws.Write(context.Background(), u.Conn, rs.newStartGame(r, u))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newStartGame returns the StartGame of the Room r for the player User u
+func (rs *RoomsStore) newStartGame(r *Room, u *User) *action.Action {
+	sga := action.NewStartGame(rs.SyncState(r, u.ID), r.Seed)
+	sga.StartGame.ResumeToken = u.ResumeToken
+	sga.StartGame.BalanceHash = r.Game.Store.Balance().Hash()
+	sga.StartGame.Rules = r.Rules
+	return sga
+}
+
+// ListDisconnectedUsers returns the Usernames of the
+// Users that were disconnected before the time b
+func (rs *RoomsStore) ListDisconnectedUsers(b time.Time) []string {
+	rs.mxRooms.RLock()
+	defer rs.mxRooms.RUnlock()
+
+	var uns []string
+	for _, u := range rs.GetState().Users {
+		if !u.DisconnectedAt.IsZero() && u.DisconnectedAt.Before(b) {
+			uns = append(uns, u.Username)
+		}
+	}
+	return uns
+}
+
+// removeSpectator removes the user u from the Room it's spectating
+func (rs *RoomsStore) removeSpectator(state *RoomsState, u *User) {
+	if r, ok := state.Rooms[u.SpectatingRoomID]; ok {
+		delete(r.Spectators, u.ID)
+		r.Snapshots.Remove(u.ID)
+	}
+	u.SpectatingRoomID = ""
+}
+
+// notifySpectatorsRoomDeleted sends the spectators of the
+// deleted Room r back to the root as the Game has ended
+func (rs *RoomsStore) notifySpectatorsRoomDeleted(r *Room) {
+	nto := action.NewNavigateTo(utils.RootRoute)
+	for _, u := range r.Spectators {
+		u.SpectatingRoomID = ""
+		err := rs.ws.Write(context.Background(), u.Conn, nto)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	r.Spectators = make(map[string]*User)
+}
+
+// saveReplay stops the recording of the Room r and stores
+// the replay on the Options.ReplaysDir
+func (rs *RoomsStore) saveReplay(r *Room) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Stop()
+	if rs.options.ReplaysDir == "" {
+		return
+	}
+
+	rp := r.Recorder.Replay()
+	fn := filepath.Join(rs.options.ReplaysDir, fmt.Sprintf("%d-%s.replay", r.StartedAt.Unix(), r.Name))
+	go func() {
+		err := os.MkdirAll(rs.options.ReplaysDir, 0755)
+		if err != nil {
+			rs.logger.Error("Failed to create the replays directory", "error", err.Error())
+			return
+		}
+		f, err := os.Create(fn)
+		if err != nil {
+			rs.logger.Error("Failed to create the replay file", "error", err.Error())
+			return
+		}
+		defer f.Close()
+
+		err = replay.Write(f, rp)
+		if err != nil {
+			rs.logger.Error("Failed to write the replay", "error", err.Error())
+		}
+	}()
+}
+
 func (rs RoomsStore) startRoom(state RoomsState, rid string) {
 	rd := flux.NewDispatcher[*action.Action]()
 	g := NewGame(rd, rs.logger)
 	cr := state.Rooms[rid]
+	if cr.Balance == nil {
+		cr.Balance = rs.options.Balances.Default
+	}
+	// The Balance and Rules have to be set before adding
+	// the players as they have the starting numbers
+	g.Store.SetBalance(cr.Balance)
+	g.Store.SetRules(cr.Rules)
 	cr.StartedAt = time.Now()
+	cr.Seed = cr.StartedAt.UnixNano()
 	ctx := context.Background()
 	cr.Context, cr.ContextCancelFn = context.WithCancel(ctx)
 	cr.Game = g
+	cr.Dispatcher = rd
+	cr.Recorder = replay.NewRecorder(rd, g.Store)
+	cr.Snapshots = NewSnapshots()
+	if cr.Ranked && cr.Type == RoomTypePlayers {
+		pids := make([]string, 0, len(cr.Players))
+		for pid := range cr.Players {
+			pids = append(pids, pid)
+		}
+		cr.Standings = NewStandings(pids)
+	}
 	pcount := 0
 	for pid, pc := range cr.Players {
 		if pc.IsBot {
@@ -632,12 +954,14 @@ func (rs RoomsStore) startRoom(state RoomsState, rid string) {
 			//uu := state.Users[u.Username]
 			//uu.CurrentRoomID = rid
 			pc.User.CurrentRoomID = rid
+			pc.User.ResumeToken = uuid.Must(uuid.NewV4()).String()
 			g.Dispatch(action.NewAddPlayer(pid, pc.User.Username, pc.User.ImageKey, pcount, pc.IsBot))
 		}
 		pcount++
 	}
 	ssp := rs.SyncState(cr, "")
-	sga := action.NewStartGame(ssp)
+	sga := action.NewStartGame(ssp, cr.Seed)
+	sga.StartGame.Rules = cr.Rules
 
 	g.Dispatch(sga)
 	for pid, pc := range cr.Players {
@@ -645,9 +969,7 @@ func (rs RoomsStore) startRoom(state RoomsState, rid string) {
 			cr.Bots[pid].Start()
 			continue
 		} else {
-			ssp := rs.SyncState(cr, pc.User.ID)
-			sga := action.NewStartGame(ssp)
-			err := rs.ws.Write(context.Background(), pc.User.Conn, sga)
+			err := rs.ws.Write(context.Background(), pc.User.Conn, rs.newStartGame(cr, pc.User))
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -660,7 +982,6 @@ func (rs RoomsStore) startRoom(state RoomsState, rid string) {
 func (rs RoomsStore) SyncState(r *Room, pid string) action.SyncStatePayload {
 	// Players
 	players := make(map[string]*action.SyncStatePlayerPayload)
-	lstate := r.Game.Store.Game.GetState()
 	lplayers := r.Game.Store.Game.ListPlayers()
 	for _, p := range lplayers {
 		ap := p
@@ -680,6 +1001,8 @@ func (rs RoomsStore) SyncState(r *Room, pid string) action.SyncStatePayload {
 		for t, uu := range ap.UnitUpdates {
 			uspp.UnitUpdates[t] = action.SyncStatePlayerUnitUpdatePayload(uu)
 		}
+		uspp.Hero = action.SyncStatePlayerHeroPayload(ap.Hero)
+		uspp.Hero.UsedAt = maps.Clone(ap.Hero.UsedAt)
 		if pid == ap.ID {
 			uspp.Current = true
 		}
@@ -713,16 +1036,20 @@ func (rs RoomsStore) SyncState(r *Room, pid string) action.SyncStatePayload {
 		}
 	}
 
+	gerr, gerrAt := r.Game.Store.Game.GetError()
 	return action.SyncStatePayload{
-		&action.SyncStatePlayersPayload{
+		Version:  action.SyncStateVersion,
+		Keyframe: true,
+		Players: &action.SyncStatePlayersPayload{
 			Players:     players,
 			IncomeTimer: r.Game.Store.Game.GetIncomeTimer(),
 		},
-		&action.SyncStateLinesPayload{
+		Lines: &action.SyncStateLinesPayload{
 			Lines: lines,
 		},
-		r.StartedAt,
-		lstate.Error,
-		lstate.ErrorAt,
+		StartedAt: r.StartedAt,
+		Tick:      r.Game.Store.Game.GetTick(),
+		Error:     gerr,
+		ErrorAt:   gerrAt,
 	}
 }
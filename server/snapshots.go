@@ -0,0 +1,101 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/xescugc/maze-wars/action"
+)
+
+const (
+	// snapshotsKeyframeInterval is every how many snapshots
+	// a full keyframe is sent to all the users (5s)
+	snapshotsKeyframeInterval = 20
+)
+
+// Snapshots keeps the last SyncState sent on a Room and the last one
+// acknowledged by each user so only the changes from it are sent
+type Snapshots struct {
+	mx sync.Mutex
+
+	lastID  int
+	history map[int]action.SyncStatePayload
+
+	// acks is the last SnapshotID acknowledged
+	// by each user, the key is the User.ID
+	acks map[string]int
+}
+
+// NewSnapshots returns a new empty Snapshots
+func NewSnapshots() *Snapshots {
+	return &Snapshots{
+		history: make(map[int]action.SyncStatePayload),
+		acks:    make(map[string]int),
+	}
+}
+
+// Add stores the ss as the new snapshot and returns it with the SnapshotID set
+func (s *Snapshots) Add(ss action.SyncStatePayload) action.SyncStatePayload {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.lastID++
+	ss.Version = action.SyncStateVersion
+	ss.SnapshotID = s.lastID
+	ss.Keyframe = true
+	s.history[ss.SnapshotID] = ss
+	delete(s.history, ss.SnapshotID-action.SyncStateHistory)
+
+	return ss
+}
+
+// Ack marks the snapshot sid as the last one applied by the user uid
+func (s *Snapshots) Ack(uid string, sid int) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	// We ignore the ones from the future or
+	// older than the one we already have
+	if sid > s.lastID || sid <= s.acks[uid] {
+		return
+	}
+	s.acks[uid] = sid
+}
+
+// Remove removes the acknowledged snapshots of uid
+func (s *Snapshots) Remove(uid string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	delete(s.acks, uid)
+}
+
+// For returns the payload of the snapshot ss, returned by Add, that has to be
+// sent to the user uid. It'll be a delta from the last snapshot acknowledged
+// by the user or a keyframe if it has none or it's time for one.
+// The player uid, if present, is marked as the Current one.
+func (s *Snapshots) For(uid string, ss action.SyncStatePayload) action.SyncStatePayload {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	p := ss
+	base, ok := s.history[s.acks[uid]]
+	if ok && ss.SnapshotID%snapshotsKeyframeInterval != 0 {
+		p = ss.Diff(base)
+	}
+
+	if cp, ok := p.Players.Players[uid]; ok {
+		players := make(map[string]*action.SyncStatePlayerPayload, len(p.Players.Players))
+		for id, pp := range p.Players.Players {
+			players[id] = pp
+		}
+		ncp := *cp
+		ncp.Current = true
+		players[uid] = &ncp
+
+		np := *p.Players
+		np.Players = players
+		p.Players = &np
+	}
+
+	return p
+}
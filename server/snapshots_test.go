@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/server"
+)
+
+func newSnapshot(gold int) action.SyncStatePayload {
+	return action.SyncStatePayload{
+		Players: &action.SyncStatePlayersPayload{
+			Players: map[string]*action.SyncStatePlayerPayload{
+				"p1": {ID: "p1", Gold: gold},
+				"p2": {ID: "p2"},
+			},
+		},
+		Lines: &action.SyncStateLinesPayload{
+			Lines: map[int]*action.SyncStateLinePayload{},
+		},
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	s := server.NewSnapshots()
+
+	ss1 := s.Add(newSnapshot(1))
+	assert.Equal(t, 1, ss1.SnapshotID)
+	assert.Equal(t, action.SyncStateVersion, ss1.Version)
+
+	t.Run("KeyframeWithoutAck", func(t *testing.T) {
+		p := s.For("p1", ss1)
+		assert.True(t, p.Keyframe)
+		assert.True(t, p.Players.Players["p1"].Current)
+		assert.False(t, p.Players.Players["p2"].Current)
+		// The snapshot is not changed
+		assert.False(t, ss1.Players.Players["p1"].Current)
+	})
+	t.Run("DeltaWithAck", func(t *testing.T) {
+		s.Ack("p1", ss1.SnapshotID)
+		ss2 := s.Add(newSnapshot(2))
+
+		p := s.For("p1", ss2)
+		assert.False(t, p.Keyframe)
+		assert.Equal(t, ss1.SnapshotID, p.BaseSnapshotID)
+		assert.Len(t, p.Players.Players, 1)
+		assert.True(t, p.Players.Players["p1"].Current)
+
+		p = s.For("p2", ss2)
+		assert.True(t, p.Keyframe)
+	})
+	t.Run("IgnoreFutureAck", func(t *testing.T) {
+		s.Ack("p2", 100)
+		ss3 := s.Add(newSnapshot(3))
+		assert.True(t, s.For("p2", ss3).Keyframe)
+	})
+}
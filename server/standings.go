@@ -0,0 +1,82 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/xescugc/maze-wars/store"
+)
+
+// Standings keeps the order in which the players of a
+// Room are eliminated so the final places are known
+// when the Game has a Winner
+type Standings struct {
+	mx sync.Mutex
+
+	// places has the place of the eliminated
+	// players, the key is the Player.ID
+	places map[string]int
+	alive  map[string]struct{}
+	done   bool
+}
+
+// NewStandings returns a new Standings for the players pids
+func NewStandings(pids []string) *Standings {
+	s := &Standings{
+		places: make(map[string]int),
+		alive:  make(map[string]struct{}),
+	}
+	for _, id := range pids {
+		s.alive[id] = struct{}{}
+	}
+	return s
+}
+
+// Update updates the Standings with the current players of the Game, the
+// ones that are no longer present or have no lives are eliminated. When
+// a Winner is found it returns the final places, only once.
+func (s *Standings) Update(players []*store.Player) (map[string]int, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.done {
+		return nil, false
+	}
+
+	var winner string
+	current := make(map[string]*store.Player, len(players))
+	for _, p := range players {
+		current[p.ID] = p
+		if p.Winner {
+			winner = p.ID
+		}
+	}
+
+	// All the players eliminated at the same
+	// time share the same place
+	var eliminated []string
+	for id := range s.alive {
+		if p, ok := current[id]; !ok || (p.Lives == 0 && !p.Winner) {
+			eliminated = append(eliminated, id)
+		}
+	}
+	for _, id := range eliminated {
+		delete(s.alive, id)
+	}
+	for _, id := range eliminated {
+		s.places[id] = len(s.alive) + 1
+	}
+
+	if winner == "" {
+		return nil, false
+	}
+
+	// If more than one is still alive they
+	// are all after the Winner
+	for id := range s.alive {
+		s.places[id] = 2
+	}
+	s.places[winner] = 1
+	s.done = true
+
+	return s.places, true
+}
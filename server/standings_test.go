@@ -0,0 +1,49 @@
+package server_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xescugc/maze-wars/server"
+	"github.com/xescugc/maze-wars/store"
+)
+
+func TestStandings(t *testing.T) {
+	s := server.NewStandings([]string{"p1", "p2", "p3", "p4"})
+
+	places, ok := s.Update([]*store.Player{
+		{ID: "p1", Lives: 10},
+		{ID: "p2", Lives: 10},
+		{ID: "p3", Lives: 10},
+		{ID: "p4", Lives: 10},
+	})
+	assert.False(t, ok)
+	assert.Nil(t, places)
+
+	// p4 left the Game
+	_, ok = s.Update([]*store.Player{
+		{ID: "p1", Lives: 10},
+		{ID: "p2", Lives: 10},
+		{ID: "p3", Lives: 10},
+	})
+	assert.False(t, ok)
+
+	places, ok = s.Update([]*store.Player{
+		{ID: "p1", Lives: 30, Winner: true},
+		{ID: "p2", Lives: 0},
+		{ID: "p3", Lives: 0},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]int{
+		"p1": 1,
+		"p2": 2,
+		"p3": 2,
+		"p4": 4,
+	}, places)
+
+	// It's only returned once
+	_, ok = s.Update([]*store.Player{
+		{ID: "p1", Lives: 30, Winner: true},
+	})
+	assert.False(t, ok)
+}
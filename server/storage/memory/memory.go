@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/xescugc/maze-wars/server/storage"
+)
+
+// Memory is a storage.Storage that keeps all the
+// data in memory so it's lost on restart
+type Memory struct {
+	mx sync.RWMutex
+
+	accounts map[string]storage.Account
+	sessions map[string]storage.Session
+}
+
+// New returns a new empty Memory storage
+func New() *Memory {
+	return &Memory{
+		accounts: make(map[string]storage.Account),
+		sessions: make(map[string]storage.Session),
+	}
+}
+
+func (m *Memory) CreateAccount(ctx context.Context, a *storage.Account) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if _, ok := m.accounts[a.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+	if _, ok := m.findAccountByUsername(a.Username); ok {
+		return storage.ErrAlreadyExists
+	}
+	m.accounts[a.ID] = *a
+	return nil
+}
+
+func (m *Memory) UpdateAccount(ctx context.Context, a *storage.Account) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if _, ok := m.accounts[a.ID]; !ok {
+		return storage.ErrNotFound
+	}
+	if ua, ok := m.findAccountByUsername(a.Username); ok && ua.ID != a.ID {
+		return storage.ErrAlreadyExists
+	}
+	m.accounts[a.ID] = *a
+	return nil
+}
+
+func (m *Memory) FindAccountByID(ctx context.Context, id string) (*storage.Account, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	a, ok := m.accounts[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m *Memory) FindAccountByUsername(ctx context.Context, un string) (*storage.Account, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	a, ok := m.findAccountByUsername(un)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m *Memory) findAccountByUsername(un string) (storage.Account, bool) {
+	for _, a := range m.accounts {
+		if a.Username == un {
+			return a, true
+		}
+	}
+	return storage.Account{}, false
+}
+
+func (m *Memory) CreateSession(ctx context.Context, s *storage.Session) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if _, ok := m.sessions[s.ID]; ok {
+		return storage.ErrAlreadyExists
+	}
+	m.sessions[s.ID] = *s
+	return nil
+}
+
+func (m *Memory) FindSession(ctx context.Context, id string) (*storage.Session, error) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &s, nil
+}
+
+func (m *Memory) DeleteSession(ctx context.Context, id string) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *Memory) Close() error { return nil }
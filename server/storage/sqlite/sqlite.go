@@ -0,0 +1,227 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xescugc/maze-wars/server/rating"
+	"github.com/xescugc/maze-wars/server/storage"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// migrations are applied in order and only once, the
+// index+1 of the last applied one is stored as the
+// PRAGMA user_version
+var migrations = []string{
+	`
+	CREATE TABLE IF NOT EXISTS accounts (
+		id TEXT PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		image_key TEXT NOT NULL,
+		password_hash BLOB,
+		created_at INTEGER NOT NULL,
+		last_sign_in_at INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		created_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL
+	);
+	`,
+	fmt.Sprintf(`
+	ALTER TABLE accounts ADD COLUMN rating INTEGER NOT NULL DEFAULT %d;
+	ALTER TABLE accounts ADD COLUMN ranked_games INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE accounts ADD COLUMN ranked_wins INTEGER NOT NULL DEFAULT 0;
+
+	CREATE INDEX accounts_rating ON accounts(rating);
+	`, rating.Default),
+}
+
+// SQLite is a storage.Storage that persists the data on a SQLite database
+type SQLite struct {
+	db *sql.DB
+}
+
+// New opens, and creates if needed, the SQLite database
+// on the path p and initializes the schema
+func New(p string) (*SQLite, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", p))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %w", p, err)
+	}
+	// SQLite only supports one writer at a time
+	db.SetMaxOpenConns(1)
+
+	err = migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate the schema: %w", err)
+	}
+
+	return &SQLite{db: db}, nil
+}
+
+// migrate applies the migrations not yet applied on the db
+func migrate(db *sql.DB) error {
+	var v int
+	err := db.QueryRow(`PRAGMA user_version`).Scan(&v)
+	if err != nil {
+		return err
+	}
+
+	for i := v; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(migrations[i])
+		if err == nil {
+			// PRAGMA does not support placeholders
+			_, err = tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1))
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) CreateAccount(ctx context.Context, a *storage.Account) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO accounts (id, username, image_key, password_hash, rating, ranked_games, ranked_wins, created_at, last_sign_in_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.ID, a.Username, a.ImageKey, a.PasswordHash, a.Rating, a.RankedGames, a.RankedWins, toUnix(a.CreatedAt), toUnix(a.LastSignInAt))
+	if err != nil {
+		return fmt.Errorf("failed to create account: %w", toStorageErr(err))
+	}
+	return nil
+}
+
+func (s *SQLite) UpdateAccount(ctx context.Context, a *storage.Account) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE accounts
+		SET username = ?, image_key = ?, password_hash = ?, rating = ?, ranked_games = ?, ranked_wins = ?, created_at = ?, last_sign_in_at = ?
+		WHERE id = ?
+	`, a.Username, a.ImageKey, a.PasswordHash, a.Rating, a.RankedGames, a.RankedWins, toUnix(a.CreatedAt), toUnix(a.LastSignInAt), a.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", toStorageErr(err))
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLite) FindAccountByID(ctx context.Context, id string) (*storage.Account, error) {
+	return s.findAccount(ctx, "id", id)
+}
+
+func (s *SQLite) FindAccountByUsername(ctx context.Context, un string) (*storage.Account, error) {
+	return s.findAccount(ctx, "username", un)
+}
+
+// findAccount finds the account with the column col equal to v
+func (s *SQLite) findAccount(ctx context.Context, col, v string) (*storage.Account, error) {
+	var (
+		a         storage.Account
+		cat, lsat int64
+	)
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT id, username, image_key, password_hash, rating, ranked_games, ranked_wins, created_at, last_sign_in_at
+		FROM accounts
+		WHERE %s = ?
+	`, col), v).Scan(&a.ID, &a.Username, &a.ImageKey, &a.PasswordHash, &a.Rating, &a.RankedGames, &a.RankedWins, &cat, &lsat)
+	if err != nil {
+		return nil, toStorageErr(err)
+	}
+	a.CreatedAt = fromUnix(cat)
+	a.LastSignInAt = fromUnix(lsat)
+	return &a, nil
+}
+
+func (s *SQLite) CreateSession(ctx context.Context, ss *storage.Session) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, account_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, ss.ID, ss.AccountID, toUnix(ss.CreatedAt), toUnix(ss.ExpiresAt))
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", toStorageErr(err))
+	}
+	return nil
+}
+
+func (s *SQLite) FindSession(ctx context.Context, id string) (*storage.Session, error) {
+	var (
+		ss       storage.Session
+		cat, eat int64
+	)
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, account_id, created_at, expires_at
+		FROM sessions
+		WHERE id = ?
+	`, id).Scan(&ss.ID, &ss.AccountID, &cat, &eat)
+	if err != nil {
+		return nil, toStorageErr(err)
+	}
+	ss.CreatedAt = fromUnix(cat)
+	ss.ExpiresAt = fromUnix(eat)
+	return &ss, nil
+}
+
+func (s *SQLite) DeleteSession(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// toStorageErr converts the SQLite errors to the storage ones
+func toStorageErr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotFound
+	}
+	var serr *sqlite.Error
+	if errors.As(err, &serr) {
+		switch serr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return storage.ErrAlreadyExists
+		}
+	}
+	return err
+}
+
+// The times are stored as UnixNano so they
+// can be compared and sorted on the queries
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnix(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
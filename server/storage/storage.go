@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// SessionDuration is for how long a Session is valid
+	SessionDuration = time.Hour * 24 * 30
+)
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+)
+
+// Storage is where the Accounts and Sessions are persisted
+type Storage interface {
+	CreateAccount(ctx context.Context, a *Account) error
+	UpdateAccount(ctx context.Context, a *Account) error
+	FindAccountByID(ctx context.Context, id string) (*Account, error)
+	FindAccountByUsername(ctx context.Context, un string) (*Account, error)
+
+	CreateSession(ctx context.Context, s *Session) error
+	// FindSession finds the Session with the ID, that
+	// is the hash of the token returned by NewSession
+	FindSession(ctx context.Context, id string) (*Session, error)
+	DeleteSession(ctx context.Context, id string) error
+
+	Close() error
+}
+
+// Account is the persisted profile of a User
+type Account struct {
+	ID       string
+	Username string
+	ImageKey string
+
+	// PasswordHash is empty if the Account can
+	// only be used with a Session token
+	PasswordHash []byte
+
+	// Rating is the rating on the ranked ladder
+	Rating      int
+	RankedGames int
+	RankedWins  int
+
+	CreatedAt    time.Time
+	LastSignInAt time.Time
+}
+
+// SetPassword stores the hash of the pw
+func (a *Account) SetPassword(pw string) error {
+	h, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	a.PasswordHash = h
+	return nil
+}
+
+// CheckPassword checks if pw is the password of the Account,
+// if it has no password it's always false
+func (a *Account) CheckPassword(pw string) bool {
+	if len(a.PasswordHash) == 0 {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(a.PasswordHash, []byte(pw)) == nil
+}
+
+// Session is a signed in Account, only the hash
+// of the token is stored so the ID is not usable
+// to sign in if the Storage is leaked
+type Session struct {
+	ID        string
+	AccountID string
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// NewSession returns a new Session for the Account aid
+// and the token the client has to use to sign in
+func NewSession(aid string, now time.Time) (*Session, string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	tk := hex.EncodeToString(b)
+
+	return &Session{
+		ID:        HashToken(tk),
+		AccountID: aid,
+		CreatedAt: now,
+		ExpiresAt: now.Add(SessionDuration),
+	}, tk, nil
+}
+
+// HashToken returns the Session.ID of the token tk
+func HashToken(tk string) string {
+	h := sha256.Sum256([]byte(tk))
+	return hex.EncodeToString(h[:])
+}
+
+// IsExpired checks if the Session is expired on the time t
+func (s *Session) IsExpired(t time.Time) bool {
+	return !t.Before(s.ExpiresAt)
+}
+
+// FindAccountByToken returns the Account of the
+// Session token tk if it's valid on the time now
+func FindAccountByToken(ctx context.Context, st Storage, tk string, now time.Time) (*Account, error) {
+	s, err := st.FindSession(ctx, HashToken(tk))
+	if err != nil {
+		return nil, err
+	}
+	if s.IsExpired(now) {
+		// If it fails it'll be removed the next time
+		_ = st.DeleteSession(ctx, s.ID)
+		return nil, ErrNotFound
+	}
+	return st.FindAccountByID(ctx, s.AccountID)
+}
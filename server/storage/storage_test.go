@@ -0,0 +1,102 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/server/storage"
+	"github.com/xescugc/maze-wars/server/storage/memory"
+	"github.com/xescugc/maze-wars/server/storage/sqlite"
+)
+
+func storages(t *testing.T) map[string]storage.Storage {
+	sq, err := sqlite.New(filepath.Join(t.TempDir(), "maze-wars.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sq.Close() })
+
+	return map[string]storage.Storage{
+		"Memory": memory.New(),
+		"SQLite": sq,
+	}
+}
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	for n, st := range storages(t) {
+		t.Run(n, func(t *testing.T) {
+			a := &storage.Account{
+				ID:           "id",
+				Username:     "user",
+				ImageKey:     "cyclope",
+				Rating:       1500,
+				CreatedAt:    now,
+				LastSignInAt: now,
+			}
+			require.NoError(t, a.SetPassword("secret"))
+			require.NoError(t, st.CreateAccount(ctx, a))
+
+			t.Run("AlreadyExists", func(t *testing.T) {
+				ea := *a
+				ea.ID = "other-id"
+				assert.ErrorIs(t, st.CreateAccount(ctx, &ea), storage.ErrAlreadyExists)
+			})
+
+			t.Run("FindAccount", func(t *testing.T) {
+				fa, err := st.FindAccountByID(ctx, a.ID)
+				require.NoError(t, err)
+				assert.Equal(t, a, fa)
+
+				fa, err = st.FindAccountByUsername(ctx, a.Username)
+				require.NoError(t, err)
+				assert.Equal(t, a, fa)
+				assert.True(t, fa.CheckPassword("secret"))
+				assert.False(t, fa.CheckPassword("wrong"))
+
+				_, err = st.FindAccountByUsername(ctx, "not-found")
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+			})
+
+			t.Run("UpdateAccount", func(t *testing.T) {
+				a.ImageKey = "robot"
+				a.LastSignInAt = now.Add(time.Hour)
+				a.Rating = 1516
+				a.RankedGames = 1
+				a.RankedWins = 1
+				require.NoError(t, st.UpdateAccount(ctx, a))
+
+				fa, err := st.FindAccountByID(ctx, a.ID)
+				require.NoError(t, err)
+				assert.Equal(t, a, fa)
+
+				assert.ErrorIs(t, st.UpdateAccount(ctx, &storage.Account{ID: "not-found"}), storage.ErrNotFound)
+			})
+
+			t.Run("Session", func(t *testing.T) {
+				s, tk, err := storage.NewSession(a.ID, now)
+				require.NoError(t, err)
+				assert.NotEqual(t, tk, s.ID)
+				require.NoError(t, st.CreateSession(ctx, s))
+
+				fa, err := storage.FindAccountByToken(ctx, st, tk, now)
+				require.NoError(t, err)
+				assert.Equal(t, a, fa)
+
+				_, err = storage.FindAccountByToken(ctx, st, "invalid", now)
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+
+				_, err = storage.FindAccountByToken(ctx, st, tk, now.Add(storage.SessionDuration))
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+
+				// The expired Session is removed
+				_, err = st.FindSession(ctx, s.ID)
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+			})
+		})
+	}
+}
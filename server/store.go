@@ -6,16 +6,25 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/server/storage"
 	"github.com/xescugc/maze-wars/store"
 )
 
 type Store struct {
 	Rooms   *RoomsStore
 	Lobbies *store.Lobbies
+
+	// Accounts is where the Users are persisted
+	Accounts storage.Storage
 }
 
-func NewStore(d *flux.Dispatcher[*action.Action], ws WSConnector, dgo *discordgo.Session, opt Options, l *slog.Logger) *Store {
-	ss := &Store{}
+func NewStore(d *flux.Dispatcher[*action.Action], ws WSConnector, st storage.Storage, dgo *discordgo.Session, opt Options, l *slog.Logger) *Store {
+	if opt.Balances == nil {
+		opt.Balances = NewBalances()
+	}
+	ss := &Store{
+		Accounts: st,
+	}
 
 	rooms := NewRoomsStore(d, ss, ws, dgo, opt, l)
 	lobbies := store.NewLobbies(d)
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/unit"
+)
+
+var (
+	ErrRateLimited      = errors.New("rate limited")
+	ErrMissingPayload   = errors.New("missing payload")
+	ErrNotInGame        = errors.New("not in a game")
+	ErrInvalidPlayer    = errors.New("invalid player")
+	ErrInvalidLine      = errors.New("invalid line")
+	ErrInvalidPosition  = errors.New("invalid position")
+	ErrInvalidTower     = errors.New("invalid tower")
+	ErrInvalidUnit      = errors.New("invalid unit")
+	ErrInvalidBalance   = errors.New("invalid balance")
+	ErrInvalidRules     = errors.New("invalid rules")
+	ErrInvalidTargeting = errors.New("invalid targeting")
+	ErrInvalidAbility   = errors.New("invalid ability")
+)
+
+// ValidateAction checks that the action a sent by the User un is valid
+// before dispatching it. The client can only act for its own Player so
+// the PlayerID is set to it and if it was a different one it's rejected.
+func (rs *RoomsStore) ValidateAction(un string, a *action.Action) error {
+	if a.Type == action.CreateLobby {
+		if a.CreateLobby == nil {
+			return ErrMissingPayload
+		}
+		if _, ok := rs.options.Balances.Find(a.CreateLobby.LobbyBalance); !ok {
+			return ErrInvalidBalance
+		}
+		return validateRules(a.CreateLobby.LobbyRules)
+	}
+	if a.Type == action.SpectateGame {
+		if a.SpectateGame == nil {
+			return ErrMissingPayload
+		}
+		// Only the User itself can start spectating
+		return bindPlayer(&a.SpectateGame.Username, un)
+	}
+	if !isGameAction(a.Type) {
+		return nil
+	}
+	if !hasPayload(a) {
+		return ErrMissingPayload
+	}
+
+	rs.mxRooms.RLock()
+	defer rs.mxRooms.RUnlock()
+
+	state := rs.GetState()
+	u, ok := state.Users[un]
+	if !ok {
+		return ErrNotInGame
+	}
+	r, ok := state.Rooms[u.CurrentRoomID]
+	if !ok || r.Game == nil {
+		return ErrNotInGame
+	}
+
+	p := r.Game.Game.FindPlayerByID(u.ID)
+	if p.ID == "" {
+		return ErrNotInGame
+	}
+
+	switch a.Type {
+	case action.PlaceTower:
+		err := bindPlayer(&a.PlaceTower.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		t, ok := tower.Towers[a.PlaceTower.Type]
+		if !ok || !r.Rules.AllowsTower(a.PlaceTower.Type) {
+			return ErrInvalidTower
+		}
+		// The Tower has to be fully inside the Line of the Player
+		g := r.Game.Game.FindLineByID(p.LineID).Graph
+		w, h := t.Size()
+		if g.GetNodeOf(a.PlaceTower.X, a.PlaceTower.Y) == nil || g.GetNodeOf(a.PlaceTower.X+w-1, a.PlaceTower.Y+h-1) == nil {
+			return ErrInvalidPosition
+		}
+	case action.UpdateTower:
+		err := bindPlayer(&a.UpdateTower.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		if _, ok := tower.Towers[a.UpdateTower.TowerType]; !ok || !r.Rules.AllowsTower(a.UpdateTower.TowerType) {
+			return ErrInvalidTower
+		}
+		if _, ok := r.Game.Game.FindLineByID(p.LineID).Towers[a.UpdateTower.TowerID]; !ok {
+			return ErrInvalidTower
+		}
+	case action.UpdateTowerTargeting:
+		err := bindPlayer(&a.UpdateTowerTargeting.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		if _, ok := r.Game.Game.FindLineByID(p.LineID).Towers[a.UpdateTowerTargeting.TowerID]; !ok {
+			return ErrInvalidTower
+		}
+		if !a.UpdateTowerTargeting.Targeting.IsATargeting() {
+			return ErrInvalidTargeting
+		}
+	case action.RemoveTower:
+		err := bindPlayer(&a.RemoveTower.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		if _, ok := r.Game.Game.FindLineByID(p.LineID).Towers[a.RemoveTower.TowerID]; !ok {
+			return ErrInvalidTower
+		}
+	case action.SummonUnit:
+		err := bindPlayer(&a.SummonUnit.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		if _, ok := unit.Units[a.SummonUnit.Type]; !ok || !r.Rules.AllowsUnit(a.SummonUnit.Type) {
+			return ErrInvalidUnit
+		}
+		// The Units are always sent to the next Line
+		if a.SummonUnit.PlayerLineID != p.LineID || a.SummonUnit.CurrentLineID != r.Game.Map.GetNextLineID(p.LineID) {
+			return ErrInvalidLine
+		}
+	case action.SummonHero:
+		err := bindPlayer(&a.SummonHero.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		if a.SummonHero.PlayerLineID != p.LineID || a.SummonHero.CurrentLineID != r.Game.Map.GetNextLineID(p.LineID) {
+			return ErrInvalidLine
+		}
+	case action.UseHeroAbility:
+		err := bindPlayer(&a.UseHeroAbility.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		// The cooldowns are checked by the Game
+		// as they depend on the state
+		if !a.UseHeroAbility.Ability.IsAAbility() {
+			return ErrInvalidAbility
+		}
+	case action.UpdateUnit:
+		err := bindPlayer(&a.UpdateUnit.PlayerID, p.ID)
+		if err != nil {
+			return err
+		}
+		if _, ok := unit.Units[a.UpdateUnit.Type]; !ok || !r.Rules.AllowsUnit(a.UpdateUnit.Type) {
+			return ErrInvalidUnit
+		}
+	case action.RemovePlayer:
+		return bindPlayer(&a.RemovePlayer.ID, p.ID)
+	}
+
+	return nil
+}
+
+// validateRules checks that the GameRules gr of a lobby can be played
+func validateRules(gr action.GameRules) error {
+	if gr.Gold < 0 || gr.Income < 0 || gr.Lives < 0 || gr.IncomeTimer < 0 || gr.MaxCapacity < 0 || gr.SuddenDeath < 0 {
+		return ErrInvalidRules
+	}
+	for _, ut := range gr.Units {
+		if _, ok := unit.Units[ut]; !ok {
+			return ErrInvalidRules
+		}
+	}
+	// At least one of the first Towers has to be allowed
+	// or the Players would not be able to place any
+	var canPlace bool
+	for _, tt := range gr.Towers {
+		t, ok := tower.Towers[tt]
+		if !ok {
+			return ErrInvalidRules
+		}
+		if slices.Contains(tower.FirstTowers, t) {
+			canPlace = true
+		}
+	}
+	if len(gr.Towers) != 0 && !canPlace {
+		return ErrInvalidRules
+	}
+	return nil
+}
+
+// isGameAction checks if the type t is an action
+// that a Player does on the Game
+func isGameAction(t action.Type) bool {
+	switch t {
+	case action.PlaceTower, action.UpdateTower, action.UpdateTowerTargeting, action.RemoveTower,
+		action.SummonUnit, action.UpdateUnit, action.SummonHero, action.UseHeroAbility, action.RemovePlayer:
+		return true
+	}
+	return false
+}
+
+// hasPayload checks if the game action a has its payload
+func hasPayload(a *action.Action) bool {
+	switch a.Type {
+	case action.PlaceTower:
+		return a.PlaceTower != nil
+	case action.UpdateTower:
+		return a.UpdateTower != nil
+	case action.UpdateTowerTargeting:
+		return a.UpdateTowerTargeting != nil
+	case action.RemoveTower:
+		return a.RemoveTower != nil
+	case action.SummonUnit:
+		return a.SummonUnit != nil
+	case action.UpdateUnit:
+		return a.UpdateUnit != nil
+	case action.SummonHero:
+		return a.SummonHero != nil
+	case action.UseHeroAbility:
+		return a.UseHeroAbility != nil
+	case action.RemovePlayer:
+		return a.RemovePlayer != nil
+	}
+	return true
+}
+
+// bindPlayer sets the pid on the id if it's empty
+// and fails if it's from another Player
+func bindPlayer(id *string, pid string) error {
+	if *id != "" && *id != pid {
+		return ErrInvalidPlayer
+	}
+	*id = pid
+	return nil
+}
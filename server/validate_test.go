@@ -0,0 +1,129 @@
+package server_test
+
+import (
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/codec"
+	"github.com/xescugc/maze-wars/server"
+	"github.com/xescugc/maze-wars/server/mock"
+	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/tower/targeting"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/hero"
+)
+
+func TestValidateAction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mwsc := mock.NewMockWSConnector(ctrl)
+
+	ad, s := initStore(mwsc)
+	var (
+		owner  = "owner"
+		player = "player"
+		lid    = "lobby-id"
+	)
+
+	mwsc.EXPECT().SetCodec(gomock.Any(), gomock.Any()).Times(2)
+	mwsc.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	ad.UserSignUp(owner+"-id", owner, "ImageKey", 1500)
+	ad.UserSignIn(owner, "owner-address", codec.JSON, &websocket.Conn{})
+	ad.UserSignUp(player+"-id", player, "ImageKey", 1500)
+	ad.UserSignIn(player, "player-address", codec.JSON, &websocket.Conn{})
+
+	assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewPlaceTower("range1", owner+"-id", 0, 0)), server.ErrNotInGame)
+	// The non game actions are not validated
+	assert.NoError(t, s.Rooms.ValidateAction(owner, action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{})))
+	// except the balance pack of the lobbies
+	assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewCreateLobby(lid, owner, "name", 2, "unknown", action.GameRules{})), server.ErrInvalidBalance)
+	// and the rules
+	assert.NoError(t, s.Rooms.ValidateAction(owner, action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{Gold: 100, Units: []string{"ninja"}, Towers: []string{"range1", "range2"}})))
+	assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{Lives: -1})), server.ErrInvalidRules)
+	assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{Units: []string{"dragon"}})), server.ErrInvalidRules)
+	assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{Towers: []string{"range2"}})), server.ErrInvalidRules)
+
+	// Users can only spectate for themselves
+	sga := action.NewSpectateGame("", lid)
+	assert.NoError(t, s.Rooms.ValidateAction(player, sga))
+	assert.Equal(t, player, sga.SpectateGame.Username)
+	assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewSpectateGame(player, lid)), server.ErrInvalidPlayer)
+	assert.ErrorIs(t, s.Rooms.ValidateAction(owner, &action.Action{Type: action.SpectateGame}), server.ErrMissingPayload)
+
+	ad.Dispatch(action.NewCreateLobby(lid, owner, "name", 2, "", action.GameRules{}))
+	ad.Dispatch(action.NewJoinLobby(lid, player, false))
+	ad.Dispatch(action.NewStartLobby(lid))
+
+	r := s.Rooms.FindRoomByID(lid)
+	require.NotNil(t, r)
+	u, _ := s.Rooms.FindUserByUsername(owner)
+	ou, _ := s.Rooms.FindUserByUsername(player)
+	p := r.Game.Game.FindPlayerByID(u.ID)
+	g := r.Game.Game.FindLineByID(p.LineID).Graph
+	x, y := g.OffsetX, g.OffsetY+(g.SpawnZoneH*g.Scale)
+
+	t.Run("PlaceTower", func(t *testing.T) {
+		pta := action.NewPlaceTower("range1", "", x, y)
+		assert.NoError(t, s.Rooms.ValidateAction(owner, pta))
+		assert.Equal(t, u.ID, pta.PlaceTower.PlayerID)
+
+		pta = action.NewPlaceTower("range1", ou.ID, x, y)
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, pta), server.ErrInvalidPlayer)
+
+		pta = action.NewPlaceTower("invalid", u.ID, x, y)
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, pta), server.ErrInvalidTower)
+
+		w, _ := tower.Towers["range1"].Size()
+		pta = action.NewPlaceTower("range1", u.ID, g.OffsetX+(g.W*g.Scale)-w+1, y)
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, pta), server.ErrInvalidPosition)
+
+		pta = action.NewPlaceTower("range1", u.ID, -1, -1)
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, pta), server.ErrInvalidPosition)
+	})
+
+	t.Run("SummonUnit", func(t *testing.T) {
+		sua := action.NewSummonUnit(unit.Ninja.String(), u.ID, p.LineID, r.Game.Map.GetNextLineID(p.LineID))
+		assert.NoError(t, s.Rooms.ValidateAction(owner, sua))
+
+		sua = action.NewSummonUnit(unit.Ninja.String(), u.ID, p.LineID, p.LineID)
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, sua), server.ErrInvalidLine)
+
+		sua = action.NewSummonUnit("invalid", u.ID, p.LineID, r.Game.Map.GetNextLineID(p.LineID))
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, sua), server.ErrInvalidUnit)
+	})
+
+	t.Run("SummonHero", func(t *testing.T) {
+		sha := action.NewSummonHero(u.ID, p.LineID, r.Game.Map.GetNextLineID(p.LineID))
+		assert.NoError(t, s.Rooms.ValidateAction(owner, sha))
+
+		sha = action.NewSummonHero(u.ID, p.LineID, p.LineID)
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, sha), server.ErrInvalidLine)
+	})
+
+	t.Run("UseHeroAbility", func(t *testing.T) {
+		assert.NoError(t, s.Rooms.ValidateAction(owner, action.NewUseHeroAbility(u.ID, hero.Dash)))
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewUseHeroAbility(u.ID, hero.Ability(42))), server.ErrInvalidAbility)
+	})
+
+	t.Run("RemoveTower", func(t *testing.T) {
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewRemoveTower(u.ID, "not-found")), server.ErrInvalidTower)
+	})
+
+	t.Run("UpdateTowerTargeting", func(t *testing.T) {
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewUpdateTowerTargeting(u.ID, "not-found", targeting.First)), server.ErrInvalidTower)
+	})
+
+	t.Run("RemovePlayer", func(t *testing.T) {
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, action.NewRemovePlayer(ou.ID)), server.ErrInvalidPlayer)
+		assert.NoError(t, s.Rooms.ValidateAction(owner, action.NewRemovePlayer(u.ID)))
+	})
+
+	t.Run("MissingPayload", func(t *testing.T) {
+		assert.ErrorIs(t, s.Rooms.ValidateAction(owner, &action.Action{Type: action.UpdateUnit}), server.ErrMissingPayload)
+	})
+}
@@ -2,25 +2,55 @@ package server
 
 import (
 	"context"
+	"sync"
 
 	"github.com/coder/websocket"
-	"github.com/coder/websocket/wsjson"
+	"github.com/xescugc/maze-wars/codec"
 )
 
 //go:generate mockgen -destination=./mock/websocket.go -package mock github.com/xescugc/maze-wars/server WSConnector
 type WSConnector interface {
 	Write(context.Context, *websocket.Conn, interface{}) error
 	Read(context.Context, *websocket.Conn, interface{}) error
+
+	// SetCodec sets the codec.Codec used to Write on the conn,
+	// if it's nil it'll go back to the default one
+	SetCodec(*websocket.Conn, codec.Codec)
 }
 
-type WS struct{}
+type WS struct {
+	mxCodecs sync.RWMutex
+	codecs   map[*websocket.Conn]codec.Codec
+}
 
-func NewWS() *WS { return &WS{} }
+func NewWS() *WS {
+	return &WS{
+		codecs: make(map[*websocket.Conn]codec.Codec),
+	}
+}
 
 func (ws *WS) Write(ctx context.Context, conn *websocket.Conn, d interface{}) error {
-	return wsjson.Write(ctx, conn, d)
+	ws.mxCodecs.RLock()
+	c, ok := ws.codecs[conn]
+	ws.mxCodecs.RUnlock()
+	if !ok {
+		c, _ = codec.New(codec.JSON)
+	}
+	return codec.Write(ctx, conn, c, d)
 }
 
+// Read reads from the conn with the codec of the message type
 func (ws *WS) Read(ctx context.Context, conn *websocket.Conn, d interface{}) error {
-	return wsjson.Read(ctx, conn, d)
+	return codec.Read(ctx, conn, d)
+}
+
+func (ws *WS) SetCodec(conn *websocket.Conn, c codec.Codec) {
+	ws.mxCodecs.Lock()
+	defer ws.mxCodecs.Unlock()
+
+	if c == nil {
+		delete(ws.codecs, conn)
+		return
+	}
+	ws.codecs[conn] = c
 }
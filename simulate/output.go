@@ -0,0 +1,117 @@
+package simulate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xescugc/maze-wars/unit"
+)
+
+const (
+	JSON = "json"
+	CSV  = "csv"
+)
+
+// Writer writes the Results of the simulated Games
+type Writer interface {
+	Write(r *Result) error
+	Flush() error
+}
+
+// NewWriter returns the Writer of the format f to w
+func NewWriter(w io.Writer, f string) (Writer, error) {
+	switch f {
+	case JSON:
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case CSV:
+		return newCSVWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", f)
+	}
+}
+
+// jsonWriter writes each Result as a JSON on a new line
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func (jw *jsonWriter) Write(r *Result) error { return jw.enc.Encode(r) }
+func (jw *jsonWriter) Flush() error          { return nil }
+
+// csvWriter writes one row for each Player of the Result,
+// the curves are a list of the values separated by ';'
+type csvWriter struct {
+	w *csv.Writer
+
+	units  []string
+	header bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	units := make([]string, 0, len(unit.Units))
+	for t := range unit.Units {
+		units = append(units, t)
+	}
+	sort.Strings(units)
+
+	return &csvWriter{
+		w:     csv.NewWriter(w),
+		units: units,
+	}
+}
+
+func (cw *csvWriter) Write(r *Result) error {
+	if !cw.header {
+		h := []string{"game", "seed", "player", "strategy", "winner", "duration_seconds", "lives", "lives_stolen", "lives_lost", "towers_built"}
+		for _, u := range cw.units {
+			h = append(h, "units_"+u)
+		}
+		h = append(h, "gold_curve", "income_curve", "lives_curve")
+		err := cw.w.Write(h)
+		if err != nil {
+			return err
+		}
+		cw.header = true
+	}
+
+	for _, p := range r.Players {
+		row := []string{
+			strconv.Itoa(r.Game),
+			strconv.FormatInt(r.Seed, 10),
+			p.ID,
+			string(p.Strategy),
+			strconv.FormatBool(p.Winner),
+			strconv.Itoa(int(r.Duration.Seconds())),
+			strconv.Itoa(p.Lives),
+			strconv.Itoa(p.LivesStolen),
+			strconv.Itoa(p.LivesLost),
+			strconv.Itoa(p.TowersBuilt),
+		}
+		for _, u := range cw.units {
+			row = append(row, strconv.Itoa(p.UnitsSummoned[u]))
+		}
+		var gold, income, lives []string
+		for _, s := range p.Curve {
+			gold = append(gold, strconv.Itoa(s.Gold))
+			income = append(income, strconv.Itoa(s.Income))
+			lives = append(lives, strconv.Itoa(s.Lives))
+		}
+		row = append(row, strings.Join(gold, ";"), strings.Join(income, ";"), strings.Join(lives, ";"))
+
+		err := cw.w.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
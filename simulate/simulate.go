@@ -0,0 +1,269 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	bht "github.com/joeycumines/go-behaviortree"
+	"github.com/xescugc/go-flux/v2"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/server/bot"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/tiled"
+	"github.com/xescugc/maze-wars/unit"
+)
+
+const (
+	isOnServer = true
+
+	// step is the game time between each
+	// action of the Bots, as on the server
+	step = time.Second / 4
+)
+
+var (
+	ErrInvalidPlayers = errors.New("invalid number of players")
+
+	// startedAt is the start time of all the simulated Games
+	startedAt = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Options are the configuration of a simulated Game
+type Options struct {
+	// Strategies are the Strategy of each
+	// Bot, one for each player of the Game
+	Strategies []bot.Strategy
+
+	Seed int64
+
+	// MaxDuration is the maximum game time, if
+	// reached the Game ends without Winner
+	MaxDuration time.Duration
+
+	// Balance is the balance the Game is
+	// simulated with, if nil it's the default
+	Balance *balance.Balance
+}
+
+// Result are the stats of a simulated Game
+type Result struct {
+	// Game is the number of the Game on the simulation
+	Game int   `json:"game"`
+	Seed int64 `json:"seed"`
+	// Winner is the ID of the winner Player,
+	// empty if the MaxDuration was reached
+	Winner   string        `json:"winner"`
+	Duration time.Duration `json:"duration_ns"`
+
+	Players []*PlayerResult `json:"players"`
+}
+
+// PlayerResult are the stats of one Player of the Game
+type PlayerResult struct {
+	ID       string       `json:"id"`
+	Strategy bot.Strategy `json:"strategy"`
+	Winner   bool         `json:"winner"`
+
+	Lives       int `json:"lives"`
+	LivesStolen int `json:"lives_stolen"`
+	LivesLost   int `json:"lives_lost"`
+
+	TowersBuilt   int            `json:"towers_built"`
+	UnitsSummoned map[string]int `json:"units_summoned"`
+
+	// Curve has the state of the Player on each second
+	Curve []Sample `json:"curve"`
+}
+
+// Sample is the state of a Player on a second of the Game
+type Sample struct {
+	Second int `json:"second"`
+	Gold   int `json:"gold"`
+	Income int `json:"income"`
+	Lives  int `json:"lives"`
+}
+
+// clock is the store.Clock of the simulation
+// so it follows the game time and not the system
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+// Run simulates a Game between Bots as fast as possible and returns its stats
+func Run(ctx context.Context, opt Options, l *slog.Logger) (*Result, error) {
+	if _, ok := tiled.Maps[len(opt.Strategies)]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidPlayers, len(opt.Strategies))
+	}
+
+	clk := &clock{now: startedAt}
+	d := flux.NewDispatcher[*action.Action]()
+	s := store.NewStoreWithClock(d, l, isOnServer, clk)
+	s.SetBalance(opt.Balance)
+
+	res := &Result{
+		Seed:    opt.Seed,
+		Players: make([]*PlayerResult, 0, len(opt.Strategies)),
+	}
+	for i, st := range opt.Strategies {
+		id := fmt.Sprintf("p%d", i+1)
+		s.Dispatch(action.NewAddPlayer(id, id, unit.Ninja.String(), i, true))
+		res.Players = append(res.Players, &PlayerResult{
+			ID:            id,
+			Strategy:      st,
+			UnitsSummoned: make(map[string]int),
+		})
+	}
+	s.Dispatch(action.NewStartGame(newStartGameState(s), opt.Seed))
+
+	nodes := make([]bht.Node, 0, len(opt.Strategies))
+	for i, st := range opt.Strategies {
+		// Each Bot has its own Rand from the Seed so the Game can be reproduced
+		r := rand.New(rand.NewSource(opt.Seed + int64(i)))
+		b := bot.NewWithOptions(ctx, d, s, res.Players[i].ID, bot.Options{Strategy: st, VsBots: true, Rand: r})
+		nodes = append(nodes, b.Node())
+	}
+
+	st := newStats(res)
+	for i := 1; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		clk.now = startedAt.Add(time.Duration(i) * step)
+
+		for _, n := range nodes {
+			_, err := n.Tick()
+			if err != nil {
+				return nil, fmt.Errorf("failed to tick bot: %w", err)
+			}
+		}
+		if clk.now.Sub(startedAt)%time.Second == 0 {
+			s.Dispatch(action.NewIncomeTick())
+		}
+		s.Dispatch(action.NewTPS(clk.now))
+
+		st.update(s, clk.now.Sub(startedAt))
+
+		res.Duration = clk.now.Sub(startedAt)
+		if res.Winner != "" || (opt.MaxDuration != 0 && res.Duration >= opt.MaxDuration) {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// newStartGameState returns the state to start
+// the Game with the players already added on s
+func newStartGameState(s *store.Store) action.SyncStatePayload {
+	players := make(map[string]*action.SyncStatePlayerPayload)
+	for _, p := range s.Game.ListPlayers() {
+		sp := action.SyncStatePlayerPayload{
+			ID:          p.ID,
+			Name:        p.Name,
+			ImageKey:    p.ImageKey,
+			Lives:       p.Lives,
+			LineID:      p.LineID,
+			Income:      p.Income,
+			Gold:        p.Gold,
+			IsBot:       p.IsBot,
+			UnitUpdates: make(map[string]action.SyncStatePlayerUnitUpdatePayload),
+		}
+		for t, uu := range p.UnitUpdates {
+			sp.UnitUpdates[t] = action.SyncStatePlayerUnitUpdatePayload(uu)
+		}
+		players[p.ID] = &sp
+	}
+	return action.SyncStatePayload{
+		Players:   &action.SyncStatePlayersPayload{Players: players, IncomeTimer: s.Balance().IncomeTimer},
+		Lines:     &action.SyncStateLinesPayload{},
+		StartedAt: startedAt,
+	}
+}
+
+// stats collects the stats of the Result from the
+// state of the Game after each step
+type stats struct {
+	res *Result
+
+	players map[string]*PlayerResult
+	lives   map[string]int
+
+	// towers and units are the IDs already
+	// counted so each one is only counted once
+	towers map[string]struct{}
+	units  map[string]struct{}
+}
+
+func newStats(res *Result) *stats {
+	st := &stats{
+		res:     res,
+		players: make(map[string]*PlayerResult),
+		lives:   make(map[string]int),
+		towers:  make(map[string]struct{}),
+		units:   make(map[string]struct{}),
+	}
+	for _, p := range res.Players {
+		st.players[p.ID] = p
+	}
+	return st
+}
+
+// update updates the stats with the state of s at the game time d
+func (st *stats) update(s *store.Store, d time.Duration) {
+	for _, p := range s.Game.ListPlayers() {
+		pr := st.players[p.ID]
+
+		if lv, ok := st.lives[p.ID]; ok {
+			if p.Lives > lv {
+				pr.LivesStolen += p.Lives - lv
+			} else {
+				pr.LivesLost += lv - p.Lives
+			}
+		}
+		st.lives[p.ID] = p.Lives
+		pr.Lives = p.Lives
+
+		if p.Winner {
+			pr.Winner = true
+			st.res.Winner = p.ID
+		}
+
+		if d%time.Second == 0 {
+			pr.Curve = append(pr.Curve, Sample{
+				Second: int(d / time.Second),
+				Gold:   p.Gold,
+				Income: p.Income,
+				Lives:  p.Lives,
+			})
+		}
+	}
+
+	for _, l := range s.Game.ListLines() {
+		for id, t := range l.Towers {
+			if _, ok := st.towers[id]; ok {
+				continue
+			}
+			st.towers[id] = struct{}{}
+			if pr, ok := st.players[t.PlayerID]; ok {
+				pr.TowersBuilt++
+			}
+		}
+		for id, u := range l.Units {
+			if _, ok := st.units[id]; ok {
+				continue
+			}
+			st.units[id] = struct{}{}
+			if pr, ok := st.players[u.PlayerID]; ok {
+				pr.UnitsSummoned[u.Type]++
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package simulate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/server/bot"
+	"github.com/xescugc/maze-wars/simulate"
+)
+
+func newEmptyLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestRun(t *testing.T) {
+	res, err := simulate.Run(context.Background(), simulate.Options{
+		Strategies:  []bot.Strategy{bot.Attacker, bot.Defender},
+		Seed:        42,
+		MaxDuration: time.Minute,
+	}, newEmptyLogger())
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Minute, res.Duration)
+	assert.Equal(t, "", res.Winner)
+	require.Len(t, res.Players, 2)
+
+	att, def := res.Players[0], res.Players[1]
+	assert.Equal(t, bot.Attacker, att.Strategy)
+	assert.Equal(t, bot.Defender, def.Strategy)
+
+	for _, p := range res.Players {
+		assert.Len(t, p.Curve, 60)
+		assert.Equal(t, 1, p.Curve[0].Second)
+		assert.Equal(t, 60, p.Curve[59].Second)
+	}
+
+	// Each one prefers to spend on its Strategy
+	var summoned int
+	for _, n := range att.UnitsSummoned {
+		summoned += n
+	}
+	assert.NotZero(t, summoned)
+	assert.NotZero(t, def.TowersBuilt)
+
+	t.Run("SameSeed", func(t *testing.T) {
+		opt := simulate.Options{
+			Strategies:  []bot.Strategy{bot.Random, bot.Random},
+			Seed:        7,
+			MaxDuration: 2 * time.Minute,
+		}
+		res1, err := simulate.Run(context.Background(), opt, newEmptyLogger())
+		require.NoError(t, err)
+		res2, err := simulate.Run(context.Background(), opt, newEmptyLogger())
+		require.NoError(t, err)
+		assert.Equal(t, res1, res2)
+	})
+	t.Run("InvalidPlayers", func(t *testing.T) {
+		_, err := simulate.Run(context.Background(), simulate.Options{
+			Strategies: []bot.Strategy{bot.Random},
+		}, newEmptyLogger())
+		assert.ErrorIs(t, err, simulate.ErrInvalidPlayers)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	res := &simulate.Result{
+		Game:     1,
+		Seed:     42,
+		Winner:   "p1",
+		Duration: 2 * time.Second,
+		Players: []*simulate.PlayerResult{
+			{
+				ID: "p1", Strategy: bot.Random, Winner: true, Lives: 21, LivesStolen: 1, TowersBuilt: 2,
+				UnitsSummoned: map[string]int{"ninja": 3},
+				Curve:         []simulate.Sample{{Second: 1, Gold: 40, Income: 25, Lives: 20}, {Second: 2, Gold: 10, Income: 26, Lives: 21}},
+			},
+			{
+				ID: "p2", Strategy: bot.Random, LivesLost: 1, Lives: 0,
+				UnitsSummoned: map[string]int{},
+			},
+		},
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		var b bytes.Buffer
+		w, err := simulate.NewWriter(&b, simulate.JSON)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(res))
+		require.NoError(t, w.Flush())
+
+		var r simulate.Result
+		require.NoError(t, json.Unmarshal(b.Bytes(), &r))
+		assert.Equal(t, res, &r)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var b bytes.Buffer
+		w, err := simulate.NewWriter(&b, simulate.CSV)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(res))
+		require.NoError(t, w.Flush())
+
+		rows, err := csv.NewReader(&b).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+
+		row := make(map[string]string)
+		for i, h := range rows[0] {
+			row[h] = rows[1][i]
+		}
+		assert.Equal(t, "p1", row["player"])
+		assert.Equal(t, "true", row["winner"])
+		assert.Equal(t, "2", row["duration_seconds"])
+		assert.Equal(t, "3", row["units_ninja"])
+		assert.Equal(t, "40;10", row["gold_curve"])
+		assert.Equal(t, "25;26", row["income_curve"])
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, err := simulate.NewWriter(io.Discard, "xml")
+		assert.Error(t, err)
+	})
+}
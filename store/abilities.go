@@ -1,6 +1,103 @@
 package store
 
-import "time"
+import (
+	"maps"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/unit/ability"
+	"github.com/xescugc/maze-wars/unit/buff"
+)
+
+// abilityHandler is the behaviour of an ability.Ability on the Game, the
+// hooks are only called for the Units that have the Ability. To add a new
+// Ability it only has to be registered on the abilityHandlers
+type abilityHandler interface {
+	// onDamage is called each time the Unit u is damaged
+	onDamage(g *Game, u *Unit, t time.Time)
+
+	// onDeath is called when the Unit u is killed by the Player pid,
+	// if it returns true the Ability handled it and the Unit
+	// is not removed nor the Bounty given
+	onDeath(g *Game, state GameState, l *Line, pid string, u *Unit, t time.Time) bool
+
+	// onTick is called at the start of each tick, if
+	// it returns false the Unit skips the tick
+	onTick(g *Game, u *Unit, t time.Time) bool
+
+	// onLineChange is called when the Unit u enters
+	// a new Line, also when it's summoned
+	onLineChange(g *Game, state GameState, u *Unit)
+
+	// decodeState converts the synced v to the type of the state
+	// the Ability stores on the Unit.Abilities, nil if it has none
+	decodeState(v interface{}) interface{}
+}
+
+var (
+	// abilityHandlers are the Abilities that have behaviour on the Game,
+	// the rest only change the stats or are checked directly
+	abilityHandlers = map[ability.Ability]abilityHandler{
+		ability.Split:        splitHandler{},
+		ability.Burrow:       burrowHandler{},
+		ability.Resurrection: resurrectionHandler{},
+		ability.Hybrid:       hybridHandler{},
+	}
+)
+
+// baseHandler is an abilityHandler that does nothing,
+// the handlers embed it to only define the hooks they need
+type baseHandler struct{}
+
+func (baseHandler) onDamage(g *Game, u *Unit, t time.Time) {}
+func (baseHandler) onDeath(g *Game, state GameState, l *Line, pid string, u *Unit, t time.Time) bool {
+	return false
+}
+func (baseHandler) onTick(g *Game, u *Unit, t time.Time) bool      { return true }
+func (baseHandler) onLineChange(g *Game, state GameState, u *Unit) {}
+func (baseHandler) decodeState(v interface{}) interface{}          { return nil }
+
+// unitHandlers returns the handlers of the Abilities of the Unit u on the Balance b
+func unitHandlers(b *balance.Balance, u *Unit) []abilityHandler {
+	res := make([]abilityHandler, 0)
+	for _, a := range b.Unit(u.Type).Abilities {
+		if h, ok := abilityHandlers[a]; ok {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// abilityParams returns the parameters T of the Ability a of the Unit u on its rank
+func abilityParams[T any](b *balance.Balance, u *Unit, a ability.Ability) T {
+	return ability.ParamsOf[T](b.Unit(u.Type).AbilityParams, a, u.AbilityRank(b))
+}
+
+// AbilityRank returns the rank of the Abilities of the
+// Unit which depends on the Level it was summoned with
+func (u *Unit) AbilityRank(b *balance.Balance) int {
+	return b.AbilityRank(u.Level)
+}
+
+// decodeState decodes the v to the state s with the
+// times as they are encoded as strings when synced
+func decodeState(v interface{}, s interface{}) {
+	d, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeHookFunc(time.RFC3339Nano),
+		WeaklyTypedInput: true,
+		Result:           s,
+	})
+	_ = d.Decode(v)
+}
+
+// setAbilityState sets the state s of the Ability a to the Unit u
+func (u *Unit) setAbilityState(a ability.Ability, s interface{}) {
+	if u.Abilities == nil {
+		u.Abilities = make(map[string]interface{})
+	}
+	u.Abilities[a.String()] = s
+}
 
 type AbilitySplit struct {
 	// UnitID  is the other unit
@@ -10,38 +107,268 @@ type AbilitySplit struct {
 	UnitID string `mapstructure:"UnitID"`
 }
 
-const (
-	burrowTime    = time.Second * 15
-	maxBurrowTime = time.Second * 45
-)
+// splitHandler splits the Unit in 2 when killed, each one with
+// half of the Health and the SplitParams.SpeedFactor
+type splitHandler struct{ baseHandler }
+
+func (splitHandler) onDeath(g *Game, state GameState, l *Line, pid string, u *Unit, t time.Time) bool {
+	if as, ok := u.Abilities[ability.Split.String()].(AbilitySplit); ok {
+		// TODO: Check if it moved into another line
+		// The bounty is only given when the last one of the split is killed
+		if _, ok := l.Units[as.UnitID]; ok {
+			pid = ""
+		}
+		g.killUnit(state, l, pid, u)
+		return true
+	}
+
+	// TODO: This should only be done on the server not on the client port
+	sp := abilityParams[ability.SplitParams](g.store.Balance(), u, ability.Split)
+	u1 := *u
+	u2 := *u
+
+	u1.ID = g.newID()
+	u2.ID = g.newID()
+
+	h := u.MaxHealth / 2
+
+	u1.MaxHealth = h
+	u1.Health = h
+	u2.MaxHealth = h
+	u2.Health = h
+
+	u1.MovementSpeed = u1.MovementSpeed * sp.SpeedFactor
+	u2.MovementSpeed = u2.MovementSpeed * sp.SpeedFactor
+
+	// The second unit created we move it forward if possible
+	for i := 0; i < sp.Offset; i++ {
+		if len(u2.Path) != 0 {
+			l.stepUnit(g.store.Balance(), &u2)
+		}
+	}
+
+	// The maps can not be shared between the split Units
+	u1.Abilities = maps.Clone(u.Abilities)
+	u2.Abilities = maps.Clone(u.Abilities)
+	u1.Buffs = maps.Clone(u.Buffs)
+	u2.Buffs = maps.Clone(u.Buffs)
+	u1.setAbilityState(ability.Split, AbilitySplit{
+		UnitID: u2.ID,
+	})
+	u2.setAbilityState(ability.Split, AbilitySplit{
+		UnitID: u1.ID,
+	})
+
+	// The Hero is only the first one
+	if u.Hero {
+		u2.Hero = false
+		state.Players[u.PlayerID].Hero.UnitID = u1.ID
+	}
+
+	l.Units[u1.ID] = &u1
+	l.Units[u2.ID] = &u2
+
+	g.killUnit(state, l, "", u)
+	return true
+}
+
+func (splitHandler) decodeState(v interface{}) interface{} {
+	var a AbilitySplit
+	decodeState(v, &a)
+	return a
+}
+
+// isLastSplit checks if the Unit is not a Split or it's
+// the last one of the Split left on the Line l
+func (u *Unit) isLastSplit(l *Line) bool {
+	as, ok := u.Abilities[ability.Split.String()].(AbilitySplit)
+	if !ok {
+		return true
+	}
+	_, ok = l.Units[as.UnitID]
+	return !ok
+}
 
 type AbilityBurrow struct {
 	// BurrowAt is the time in which it was burrowed.
-	// It'll stay there for 15s and then the next unit
-	// that steps on it it'll pop up again.
-	// If in 45s no unit stept on it it'll unburrow
-	// itself up
+	// It'll stay there for the BurrowParams.Duration and then
+	// the next unit that steps on it it'll pop up again.
+	// If in the BurrowParams.MaxDuration no unit stept on
+	// it it'll unburrow itself up
 	BurrowAt   time.Time `mapstructure:"BurrowAt"`
 	Unburrowed bool      `mapstructure:"Unburrowed"`
 }
 
-func (ab AbilityBurrow) CanUnburrow(t time.Time) bool {
-	return t.Sub(ab.BurrowAt) > burrowTime
+// burrowHandler burrows the Unit when it reaches the
+// BurrowParams.Health, it can only burrow once
+type burrowHandler struct{ baseHandler }
+
+func (burrowHandler) onDamage(g *Game, u *Unit, t time.Time) {
+	b := g.store.Balance()
+	bp := abilityParams[ability.BurrowParams](b, u, ability.Burrow)
+	if u.Health <= u.MaxHealth*bp.Health && !u.WasBurrowed() {
+		u.setAbilityState(ability.Burrow, AbilityBurrow{
+			BurrowAt: t,
+		})
+		u.AddBuff(buff.Burrowoed)
+	}
 }
 
-func (ab AbilityBurrow) MustUnburrow(t time.Time) bool {
-	return t.Sub(ab.BurrowAt) > maxBurrowTime
+func (burrowHandler) onTick(g *Game, u *Unit, t time.Time) bool {
+	if !u.HasBuff(buff.Burrowoed) {
+		return true
+	}
+	if !u.MustUnburrow(g.store.Balance(), t) {
+		u.AnimationCount += 1
+		return false
+	}
+	u.Unburrow()
+	return true
 }
 
-const (
-	resurrectionTime = time.Second + (time.Second / 2)
-)
+func (burrowHandler) decodeState(v interface{}) interface{} {
+	var a AbilityBurrow
+	decodeState(v, &a)
+	return a
+}
+
+// burrowState returns the AbilityBurrow of the Unit if it has it
+func (u *Unit) burrowState() (AbilityBurrow, bool) {
+	ab, ok := u.Abilities[ability.Burrow.String()].(AbilityBurrow)
+	return ab, ok
+}
+
+// MustUnburrow checks if the Unit has been burrowed more
+// than the BurrowParams.MaxDuration on the Balance b
+func (u *Unit) MustUnburrow(b *balance.Balance, t time.Time) bool {
+	// As it should have the buff but it does not we just
+	// say yes
+	ab, ok := u.burrowState()
+	if !ok {
+		return true
+	}
+	bp := abilityParams[ability.BurrowParams](b, u, ability.Burrow)
+	return t.Sub(ab.BurrowAt) > secondsToDuration(bp.MaxDuration)
+}
+
+// CanUnburrow checks if the Unit has been burrowed more
+// than the BurrowParams.Duration on the Balance b
+func (u *Unit) CanUnburrow(b *balance.Balance, t time.Time) bool {
+	// As it should have the buff but it does not we just
+	// say yes
+	ab, ok := u.burrowState()
+	if !ok {
+		return true
+	}
+	bp := abilityParams[ability.BurrowParams](b, u, ability.Burrow)
+	return t.Sub(ab.BurrowAt) > secondsToDuration(bp.Duration)
+}
+
+func (u *Unit) Unburrow() {
+	u.RemoveBuff(buff.Burrowoed)
+	ab, ok := u.burrowState()
+	if !ok {
+		return
+	}
+
+	ab.Unburrowed = true
+	u.Abilities[ability.Burrow.String()] = ab
+	u.AnimationCount = 0
+}
+
+func (u *Unit) WasBurrowed() bool {
+	ab, ok := u.burrowState()
+	return ok && ab.Unburrowed
+}
 
 type AbilityResurrection struct {
 	KilledAt    time.Time `mapstructure:"killed_at"`
 	Resurrected bool      `mapstructure:"resurrected"`
 }
 
-func (ar AbilityResurrection) CanResurrect(t time.Time) bool {
-	return t.Sub(ar.KilledAt) > resurrectionTime
+// resurrectionHandler returns the Unit to life after the
+// ResurrectionParams.Delay, it can only resurrect once
+type resurrectionHandler struct{ baseHandler }
+
+func (resurrectionHandler) onDeath(g *Game, state GameState, l *Line, pid string, u *Unit, t time.Time) bool {
+	if u.WasResurrected() {
+		return false
+	}
+	u.Health = 0
+	u.setAbilityState(ability.Resurrection, AbilityResurrection{
+		KilledAt: t,
+	})
+	u.AddBuff(buff.Resurrecting)
+	return true
+}
+
+func (resurrectionHandler) onTick(g *Game, u *Unit, t time.Time) bool {
+	if !u.HasBuff(buff.Resurrecting) {
+		return true
+	}
+	b := g.store.Balance()
+	if !u.CanResurrect(b, t) {
+		return false
+	}
+	u.Resurrect(b)
+	return true
+}
+
+func (resurrectionHandler) decodeState(v interface{}) interface{} {
+	var a AbilityResurrection
+	decodeState(v, &a)
+	return a
+}
+
+// resurrectionState returns the AbilityResurrection of the Unit if it has it
+func (u *Unit) resurrectionState() (AbilityResurrection, bool) {
+	ar, ok := u.Abilities[ability.Resurrection.String()].(AbilityResurrection)
+	return ar, ok
+}
+
+// CanResurrect checks if the Unit has been killed more
+// than the ResurrectionParams.Delay on the Balance b
+func (u *Unit) CanResurrect(b *balance.Balance, t time.Time) bool {
+	// As it should have the buff but it does not we just
+	// say yes
+	ar, ok := u.resurrectionState()
+	if !ok {
+		return true
+	}
+	rp := abilityParams[ability.ResurrectionParams](b, u, ability.Resurrection)
+	return t.Sub(ar.KilledAt) > secondsToDuration(rp.Delay)
+}
+
+// Resurrect returns the Unit to life with the
+// ResurrectionParams.Health of the Balance b
+func (u *Unit) Resurrect(b *balance.Balance) {
+	u.RemoveBuff(buff.Resurrecting)
+	ar, ok := u.resurrectionState()
+	if !ok {
+		return
+	}
+
+	ar.Resurrected = true
+	u.Abilities[ability.Resurrection.String()] = ar
+	u.AnimationCount = 0
+	rp := abilityParams[ability.ResurrectionParams](b, u, ability.Resurrection)
+	u.Health = u.MaxHealth * rp.Health
+}
+
+func (u *Unit) WasResurrected() bool {
+	ar, ok := u.resurrectionState()
+	return ok && ar.Resurrected
+}
+
+// hybridHandler changes the stats of the Unit depending
+// on the Income of the owner and the one of the Line
+type hybridHandler struct{ baseHandler }
+
+func (hybridHandler) onLineChange(g *Game, state GameState, u *Unit) {
+	u.Hybrid(g.store.Balance(), state.Players[u.PlayerID].Income, g.findPlayerByLineID(u.CurrentLineID).Income)
+}
+
+// secondsToDuration converts the seconds s to a time.Duration
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
 }
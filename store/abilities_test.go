@@ -0,0 +1,107 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/buff"
+)
+
+func TestGame_Abilities(t *testing.T) {
+	// play summons the Unit ut, updated ups times, next to a Tower with the Balance
+	// raw and runs ticks until the until returns true or the Line has no Units
+	play := func(raw string, ut unit.Type, ups int, until func(units []*store.Unit) bool) (*store.Store, []*store.Unit) {
+		s := newGameStore(t, raw)
+
+		g := s.Game.FindLineByID(1).Graph
+		s.Dispatch(action.NewPlaceTower("range1", "p2", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		for i := 0; i < ups; i++ {
+			s.Dispatch(action.NewUpdateUnit("p1", ut.String()))
+		}
+		s.Dispatch(action.NewSummonUnit(ut.String(), "p1", 0, 1))
+		for i := 1; i <= 600; i++ {
+			s.Dispatch(action.NewTPS(startedAt.Add(time.Duration(i) * store.TickDuration)))
+			units := s.Game.FindLineByID(1).ListSortedUnits()
+			if len(units) == 0 || until(units) {
+				return s, units
+			}
+		}
+		return s, nil
+	}
+
+	t.Run("Split", func(t *testing.T) {
+		s, units := play(`{
+			"towers": {"range1": {"range": 20, "damage": 100}},
+			"units": {"slime": {"ability_params": {"split": [{"speed_factor": 2, "offset": 0}]}}}
+		}`, unit.Slime, 0, func(units []*store.Unit) bool { return len(units) > 1 })
+		require.Len(t, units, 2)
+		ms := s.Balance().Unit(unit.Slime.String()).MovementSpeed
+		for _, u := range units {
+			assert.Equal(t, 2*ms, u.MovementSpeed)
+			assert.Equal(t, u.MaxHealth, float64(s.Balance().Unit(unit.Slime.String()).Health)/2)
+		}
+		assert.Equal(t, units[0].X, units[1].X)
+		assert.Equal(t, units[0].Y, units[1].Y)
+	})
+	t.Run("SplitEffects", func(t *testing.T) {
+		_, units := play(`{
+			"towers": {"range1": {"range": 20, "damage": 5, "effect": {"buff": "poisoned", "value": 1, "duration": 5}}},
+			"units": {"slime": {"ability_params": {"split": [{"speed_factor": 2, "offset": 0}]}}}
+		}`, unit.Slime, 0, func(units []*store.Unit) bool { return len(units) > 1 })
+		require.Len(t, units, 2)
+		require.True(t, units[1].HasBuff(buff.Poisoned))
+
+		// The Effects applied to one half do not affect the other
+		be, _ := units[1].BuffEffect(buff.Poisoned)
+		units[0].ApplyEffect(buff.Effect{Buff: buff.Poisoned, Value: 1, Duration: 5}, "p2", startedAt)
+		units[0].ApplyEffect(buff.Effect{Buff: buff.Stunned, Value: 1, Duration: 5}, "p2", startedAt)
+		nbe, _ := units[1].BuffEffect(buff.Poisoned)
+		assert.Equal(t, be, nbe)
+		assert.False(t, units[1].HasBuff(buff.Stunned))
+		assert.True(t, units[0].HasBuff(buff.Stunned))
+	})
+	t.Run("Burrow", func(t *testing.T) {
+		raw := `{
+			"towers": {"range1": {"range": 20, "damage": 1}},
+			"units": {"mole": {"ability_params": {"burrow": [{"health": 0.95, "duration": 0.5, "max_duration": 1}]}}}
+		}`
+		_, units := play(raw, unit.Mole, 0, func(units []*store.Unit) bool { return units[0].HasBuff(buff.Burrowoed) })
+		require.Len(t, units, 1)
+		assert.False(t, units[0].WasBurrowed())
+
+		_, units = play(raw, unit.Mole, 0, func(units []*store.Unit) bool { return units[0].WasBurrowed() })
+		require.Len(t, units, 1)
+		assert.False(t, units[0].HasBuff(buff.Burrowoed))
+	})
+	t.Run("Resurrection", func(t *testing.T) {
+		raw := `{
+			"towers": {"range1": {"range": 20, "damage": 100}},
+			"units": {"skeleton_demon": {"ability_params": {"resurrection": [{"delay": 0.5, "health": 0.5}, {"health": 0.75}]}}}
+		}`
+		_, units := play(raw, unit.SkeletonDemon, 0, func(units []*store.Unit) bool { return units[0].HasBuff(buff.Resurrecting) })
+		require.Len(t, units, 1)
+		assert.Equal(t, float64(0), units[0].Health)
+
+		_, units = play(raw, unit.SkeletonDemon, 0, func(units []*store.Unit) bool { return units[0].WasResurrected() })
+		require.Len(t, units, 1)
+		assert.False(t, units[0].HasBuff(buff.Resurrecting))
+		assert.Equal(t, units[0].MaxHealth/2, units[0].Health)
+	})
+	t.Run("ResurrectionRank", func(t *testing.T) {
+		raw := `{
+			"game": {"gold": 10000, "ability_rank_levels": [1, 2]},
+			"towers": {"range1": {"range": 20, "damage": 100}},
+			"units": {"skeleton_demon": {"ability_params": {"resurrection": [{"delay": 0.5, "health": 0.5}, {"health": 0.75}]}}}
+		}`
+		s, units := play(raw, unit.SkeletonDemon, 1, func(units []*store.Unit) bool { return units[0].WasResurrected() })
+		require.Len(t, units, 1)
+		assert.Equal(t, 2, units[0].Level)
+		assert.Equal(t, 2, units[0].AbilityRank(s.Balance()))
+		assert.Equal(t, units[0].MaxHealth*0.75, units[0].Health)
+	})
+}
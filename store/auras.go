@@ -0,0 +1,96 @@
+package store
+
+import (
+	"math"
+
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/unit/ability"
+	"github.com/xescugc/maze-wars/utils"
+)
+
+const (
+	// AuraRadius is the distance in pixels from the middle of
+	// a Unit with an Aura in which the friendly Units are affected
+	AuraRadius = 48
+
+	// healingAuraRate is the Health per second healed
+	healingAuraRate = 2
+	// shieldAuraRate is the Shield per second regenerated
+	shieldAuraRate = 1
+	// speedAuraSteps is every how many steps a Unit
+	// with the SpeedAura moves one extra step
+	speedAuraSteps = 4
+)
+
+// HasAura checks if the Unit is affected by the Aura a
+func (u *Unit) HasAura(a ability.Ability) bool {
+	for _, au := range u.Auras {
+		if au == a {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCamouflaged checks if the Unit has the Camouflage
+// ability or it's on range of a Commander
+func (u *Unit) IsCamouflaged(b *balance.Balance) bool {
+	return u.HasAbility(b, ability.Camouflage) || u.HasAura(ability.Commander)
+}
+
+// center returns the middle point of the Unit
+func (u *Unit) center() utils.Object {
+	return utils.Object{X: u.X + float64(u.W)/2, Y: u.Y + float64(u.H)/2}
+}
+
+// calculateAuras sets to each of the units the Auras they are affected by,
+// which are the ones of the Units of the same Player on AuraRadius.
+// It's calculated with the positions at the start of the tick
+func calculateAuras(b *balance.Balance, units []*Unit) {
+	sources := make([]*Unit, 0)
+	for _, u := range units {
+		u.Auras = nil
+		if u.Health <= 0 {
+			continue
+		}
+		for _, a := range b.Unit(u.Type).Abilities {
+			if a.IsAura() {
+				sources = append(sources, u)
+				break
+			}
+		}
+	}
+	for _, u := range units {
+		for _, s := range sources {
+			if s.PlayerID != u.PlayerID || !u.IsCollidingCircle(s.center(), AuraRadius) {
+				continue
+			}
+			for _, a := range b.Unit(s.Type).Abilities {
+				if a.IsAura() && !u.HasAura(a) {
+					u.Auras = append(u.Auras, a)
+				}
+			}
+		}
+	}
+}
+
+// step moves the Unit to the next Step of the Path
+func (u *Unit) step() {
+	nextStep := u.Path[0]
+	u.Path = u.Path[1:]
+	u.MovingCount += 1
+	u.Y = nextStep.Y
+	u.X = nextStep.X
+	u.Facing = nextStep.Facing
+}
+
+// applyAuras heals and regenerates the Shield of
+// the Unit depending on the Auras it's affected by
+func (u *Unit) applyAuras() {
+	if u.HasAura(ability.HealingAura) {
+		u.Health = math.Min(u.MaxHealth, u.Health+healingAuraRate*TickDuration.Seconds())
+	}
+	if u.HasAura(ability.ShieldAura) {
+		u.Shield = math.Min(u.MaxShield, u.Shield+shieldAuraRate*TickDuration.Seconds())
+	}
+}
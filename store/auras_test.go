@@ -0,0 +1,76 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/ability"
+)
+
+func TestGame_Auras(t *testing.T) {
+	// play summons the units ut with the ninja having the abilities abs and returns
+	// them after a few ticks, before them the Health and Shield are halved
+	play := func(abs string, ut ...unit.Type) (*balance.Balance, []*store.Unit) {
+		s := newGameStore(t, `{"units": {"ninja": {"abilities": `+abs+`}}}`)
+		b := s.Balance()
+
+		for _, typ := range ut {
+			s.Dispatch(action.NewSummonUnit(typ.String(), "p1", 0, 1))
+		}
+		units := s.Game.FindLineByID(1).ListSortedUnits()
+		for _, u := range units {
+			u.Health /= 2
+			u.Shield /= 2
+		}
+		for i := 1; i <= 8; i++ {
+			s.Dispatch(action.NewTPS(startedAt.Add(time.Duration(i) * store.TickDuration)))
+		}
+		return b, s.Game.FindLineByID(1).ListSortedUnits()
+	}
+
+	t.Run("HealingAura", func(t *testing.T) {
+		_, units := play(`["healing_aura"]`, unit.Ninja)
+		require.Len(t, units, 1)
+		assert.Equal(t, []ability.Ability{ability.HealingAura}, units[0].Auras)
+		assert.Greater(t, units[0].Health, units[0].MaxHealth/2)
+		assert.LessOrEqual(t, units[0].Health, units[0].MaxHealth)
+	})
+	t.Run("ShieldAura", func(t *testing.T) {
+		_, units := play(`["shield_aura"]`, unit.Ninja, unit.Robot)
+		require.Len(t, units, 2)
+		for _, u := range units {
+			if u.Type == unit.Robot.String() {
+				assert.True(t, u.HasAura(ability.ShieldAura))
+				assert.Greater(t, u.Shield, u.MaxShield/2)
+			}
+		}
+	})
+	t.Run("SpeedAura", func(t *testing.T) {
+		_, units := play(`["efficiency"]`, unit.Ninja)
+		_, sunits := play(`["speed_aura"]`, unit.Ninja)
+		require.Len(t, units, 1)
+		require.Len(t, sunits, 1)
+		assert.Empty(t, units[0].Auras)
+		assert.Less(t, len(sunits[0].Path), len(units[0].Path))
+	})
+	t.Run("Commander", func(t *testing.T) {
+		b, units := play(`["commander"]`, unit.Ninja, unit.Statue)
+		require.Len(t, units, 2)
+		for _, u := range units {
+			assert.True(t, u.HasAura(ability.Commander))
+			assert.True(t, u.IsCamouflaged(b))
+		}
+	})
+	t.Run("WithoutAura", func(t *testing.T) {
+		b, units := play(`["commander"]`, unit.Statue)
+		require.Len(t, units, 1)
+		assert.Empty(t, units[0].Auras)
+		assert.False(t, units[0].IsCamouflaged(b))
+	})
+}
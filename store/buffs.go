@@ -1,7 +1,129 @@
 package store
 
-//type Buffs struct {
-//Burrowed *BuffBurrowed
-//}
+import (
+	"math"
+	"time"
 
-//type BuffBurrowed struct{}
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/unit/buff"
+)
+
+const (
+	// armorFactor is how much each point of Armor
+	// reduces (or increases if negative) the damage
+	armorFactor = 0.06
+)
+
+// BuffEffect is the state of a buff.Effect applied to a Unit, it's
+// stored on the Unit.Buffs with the buff.Buff.String() as key
+type BuffEffect struct {
+	// Value is the buff.Effect.Value of each Stack
+	Value  float64 `mapstructure:"Value"`
+	Stacks int     `mapstructure:"Stacks"`
+
+	// ExpiresAt is when the Buff is removed from the Unit
+	ExpiresAt time.Time `mapstructure:"ExpiresAt"`
+
+	// PlayerID is the owner of the Tower that applied it so
+	// it gets the Bounty if the Unit is killed by it
+	PlayerID string `mapstructure:"PlayerID"`
+
+	// Progress is the movement accumulated by a Slowed
+	// Unit, each time it reaches 1 the Unit moves a Step
+	Progress float64 `mapstructure:"Progress"`
+}
+
+// ApplyEffect applies the Effect e from a Tower of the Player pid at t.
+// If the Unit already has it a new stack is added, if it's not on
+// the max stacks, and the duration refreshed. The Buffs without
+// stacks keep the strongest Value
+func (u *Unit) ApplyEffect(e buff.Effect, pid string, t time.Time) {
+	if u.Buffs == nil {
+		u.Buffs = make(map[string]interface{})
+	}
+	be, _ := u.Buffs[e.Buff.String()].(BuffEffect)
+	if be.Stacks < buff.MaxStacks(e.Buff) {
+		be.Stacks++
+	}
+	if buff.MaxStacks(e.Buff) == 1 {
+		be.Value = math.Max(be.Value, e.Value)
+	} else {
+		be.Value = e.Value
+	}
+	exp := t.Add(time.Duration(e.Duration * float64(time.Second)))
+	if exp.After(be.ExpiresAt) {
+		be.ExpiresAt = exp
+	}
+	be.PlayerID = pid
+	u.Buffs[e.Buff.String()] = be
+}
+
+// BuffEffect returns the BuffEffect of b if the Unit has it
+func (u *Unit) BuffEffect(b buff.Buff) (BuffEffect, bool) {
+	be, ok := u.Buffs[b.String()].(BuffEffect)
+	return be, ok
+}
+
+// Armor returns the current armor of the Unit
+func (u *Unit) Armor() float64 {
+	var a float64
+	if be, ok := u.BuffEffect(buff.ArmorShredded); ok {
+		a -= be.Value * float64(be.Stacks)
+	}
+	if be, ok := u.BuffEffect(buff.Shielded); ok {
+		a += be.Value
+	}
+	return a
+}
+
+// expireEffects removes the Effects that have expired on t
+func (u *Unit) expireEffects(t time.Time) {
+	for _, b := range buff.BuffValues() {
+		if be, ok := u.BuffEffect(b); ok && !t.Before(be.ExpiresAt) {
+			u.RemoveBuff(b)
+		}
+	}
+}
+
+// canMove checks if the Unit moves to the next Step on this tick. The
+// Stunned Units do not move and the Slowed ones only on some of the ticks
+func (u *Unit) canMove() bool {
+	if u.HasBuff(buff.Stunned) {
+		return false
+	}
+	be, ok := u.BuffEffect(buff.Slowed)
+	if !ok {
+		return true
+	}
+	be.Progress += 1 - be.Value
+	move := be.Progress >= 1
+	if move {
+		be.Progress -= 1
+	}
+	u.Buffs[buff.Slowed.String()] = be
+	return move
+}
+
+// armorDamageFactor returns the multiplier of the damage
+// received with the armor a, the negative armor increases it
+func armorDamageFactor(a float64) float64 {
+	if a >= 0 {
+		return 1 - (armorFactor*a)/(1+armorFactor*a)
+	}
+	return 2 - math.Pow(1-armorFactor, -a)
+}
+
+// applyEffects runs the Effects of the Unit u for the tick of t and
+// returns false if the Unit is no longer on the Line after them
+func (g *Game) applyEffects(state GameState, l *Line, u *Unit, t time.Time) bool {
+	u.expireEffects(t)
+	if be, ok := u.BuffEffect(buff.Poisoned); ok {
+		// The Poison is always Magic damage
+		u.TakeDamage(g.store.Balance(), be.Value*float64(be.Stacks)*TickDuration.Seconds(), damage.Magic)
+		g.checkAfterDamage(state, l, be.PlayerID, u, t)
+		if _, ok := l.Units[u.ID]; !ok || u.Health <= 0 {
+			return false
+		}
+	}
+	return true
+}
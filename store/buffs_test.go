@@ -0,0 +1,103 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/buff"
+)
+
+func TestUnit_ApplyEffect(t *testing.T) {
+	sa := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Stacks", func(t *testing.T) {
+		u := &store.Unit{}
+		e := buff.Effect{Buff: buff.Poisoned, Value: 10, Duration: 2}
+		for i := 0; i < 10; i++ {
+			u.ApplyEffect(e, "p1", sa.Add(time.Duration(i)*time.Second))
+		}
+		be, ok := u.BuffEffect(buff.Poisoned)
+		require.True(t, ok)
+		assert.Equal(t, buff.MaxStacks(buff.Poisoned), be.Stacks)
+		assert.Equal(t, float64(10), be.Value)
+		assert.Equal(t, sa.Add(11*time.Second), be.ExpiresAt)
+		assert.Equal(t, "p1", be.PlayerID)
+	})
+	t.Run("KeepsTheStrongest", func(t *testing.T) {
+		u := &store.Unit{}
+		u.ApplyEffect(buff.Effect{Buff: buff.Slowed, Value: 0.5, Duration: 4}, "p1", sa)
+		u.ApplyEffect(buff.Effect{Buff: buff.Slowed, Value: 0.2, Duration: 1}, "p1", sa)
+		be, ok := u.BuffEffect(buff.Slowed)
+		require.True(t, ok)
+		assert.Equal(t, 1, be.Stacks)
+		assert.Equal(t, 0.5, be.Value)
+		assert.Equal(t, sa.Add(4*time.Second), be.ExpiresAt)
+	})
+	t.Run("ArmorShredded", func(t *testing.T) {
+		u := &store.Unit{Type: unit.Ninja.String(), Health: 100}
+		u.TakeDamage(balance.Default, 10, damage.Magic)
+		assert.Equal(t, float64(90), u.Health)
+
+		u.ApplyEffect(buff.Effect{Buff: buff.ArmorShredded, Value: 2, Duration: 1}, "p1", sa)
+		u.ApplyEffect(buff.Effect{Buff: buff.ArmorShredded, Value: 2, Duration: 1}, "p1", sa)
+		assert.Equal(t, float64(-4), u.Armor())
+
+		u.TakeDamage(balance.Default, 10, damage.Magic)
+		assert.Less(t, u.Health, float64(80))
+	})
+}
+
+func TestGame_Effects(t *testing.T) {
+	play := func(e *buff.Effect) *store.Unit {
+		s := newGameStore(t, `{"towers": {"range1": {"range": 20, "damage": 0.1}}}`)
+		s.Balance().Tower("range1").Effect = e
+
+		g := s.Game.FindLineByID(1).Graph
+		s.Dispatch(action.NewPlaceTower("range1", "p2", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		s.Dispatch(action.NewSummonUnit(unit.Statue.String(), "p1", 0, 1))
+		s.Dispatch(action.NewTPS(startedAt.Add(3 * time.Second)))
+
+		for _, u := range s.Game.FindLineByID(1).Units {
+			return u
+		}
+		return nil
+	}
+
+	u := play(nil)
+	require.NotNil(t, u)
+	assert.Empty(t, u.Buffs)
+
+	t.Run("Slowed", func(t *testing.T) {
+		su := play(&buff.Effect{Buff: buff.Slowed, Value: 0.5, Duration: 10})
+		require.NotNil(t, su)
+		assert.True(t, su.HasBuff(buff.Slowed))
+		// The Units go down to the end of the Line
+		assert.Less(t, su.Y, u.Y)
+	})
+	t.Run("Stunned", func(t *testing.T) {
+		su := play(&buff.Effect{Buff: buff.Stunned, Duration: 10})
+		require.NotNil(t, su)
+		assert.True(t, su.HasBuff(buff.Stunned))
+		assert.Less(t, su.Y, u.Y)
+	})
+	t.Run("Poisoned", func(t *testing.T) {
+		su := play(&buff.Effect{Buff: buff.Poisoned, Value: 5, Duration: 10})
+		require.NotNil(t, su)
+		be, ok := su.BuffEffect(buff.Poisoned)
+		require.True(t, ok)
+		assert.Equal(t, "p2", be.PlayerID)
+		assert.Less(t, su.Health, u.Health)
+	})
+	t.Run("Expired", func(t *testing.T) {
+		su := play(&buff.Effect{Buff: buff.Stunned, Duration: 0.1})
+		require.NotNil(t, su)
+		assert.False(t, su.HasBuff(buff.Stunned))
+	})
+}
@@ -3,7 +3,9 @@ package store
 import (
 	"fmt"
 	"log"
+	"maps"
 	"math"
+	"math/rand"
 	"sort"
 	"sync"
 	"time"
@@ -12,11 +14,15 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
 	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/tower/targeting"
 	"github.com/xescugc/maze-wars/unit"
 	"github.com/xescugc/maze-wars/unit/ability"
 	"github.com/xescugc/maze-wars/unit/buff"
 	"github.com/xescugc/maze-wars/unit/environment"
+	"github.com/xescugc/maze-wars/unit/hero"
 	"github.com/xescugc/maze-wars/utils"
 	"github.com/xescugc/maze-wars/utils/graph"
 )
@@ -25,13 +31,7 @@ const (
 	atScale  = true
 	useCache = true
 
-	incomeTimer = 15
-
-	updateFactor     = 0.1
-	updateCostFactor = 5
-	incomeFactor     = 5
-
-	resurrectionRank1 = 0.25
+	incomeFactor = 5
 
 	noTowerID = ""
 
@@ -39,8 +39,10 @@ const (
 	projectileArrow      = "arrow"
 )
 
-var (
-	tpsMS = (time.Second / 60).Milliseconds()
+const (
+	// TickDuration is the amount of game time that
+	// passes on each simulation tick (60 per second)
+	TickDuration = time.Second / 60
 )
 
 type Game struct {
@@ -48,6 +50,21 @@ type Game struct {
 
 	store *Store
 
+	// rand is the RNG of the simulation, it's seeded on
+	// the StartGame so the same actions produce the same state
+	rand *rand.Rand
+	uuid *uuid.Gen
+
+	// snapshots are the last SyncStates applied, needed
+	// as the base of the deltas sent from the server
+	snapshots  map[int]action.SyncStatePayload
+	snapshotID int
+
+	// state is the current GameState, it's set with the mxLines
+	// locked as the one of the ReduceStore is set after the Reduce
+	// without it so it can not be read safely from other goroutines
+	state GameState
+
 	mxLines sync.RWMutex
 }
 
@@ -59,6 +76,11 @@ type GameState struct {
 	IncomeTimer int
 	StartedAt   time.Time
 
+	// Tick is the number of simulation steps
+	// that have been run since StartedAt
+	Tick int
+	Seed int64
+
 	Error   string
 	ErrorAt time.Time
 }
@@ -70,17 +92,6 @@ type Line struct {
 	Units       map[string]*Unit
 
 	Graph *graph.Graph
-
-	// UpdatedAt is the last time
-	// something was updated on this Line.
-	// Towers added, Units added or
-	// when the Units position was updated
-	// the last time.
-	// Used for the SyncState to know how much
-	// time has passed since the last update
-	// and move the Units accordingly
-	// (60 moves per second pass)
-	UpdatedAt time.Time
 }
 
 func (l *Line) ListSortedUnits() []*Unit {
@@ -92,6 +103,28 @@ func (l *Line) ListSortedUnits() []*Unit {
 	return res
 }
 
+// ListSortedTowers returns the Towers sorted by ID so
+// the simulation does not depend on the map order
+func (l *Line) ListSortedTowers() []*Tower {
+	res := make([]*Tower, 0, len(l.Towers))
+	for _, t := range l.Towers {
+		res = append(res, t)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// listSortedProjectiles returns the Projectiles sorted by ID so
+// the simulation does not depend on the map order
+func (l *Line) listSortedProjectiles() []*Projectile {
+	res := make([]*Projectile, 0, len(l.Projectiles))
+	for _, p := range l.Projectiles {
+		res = append(res, p)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
 type Projectile struct {
 	ID string
 
@@ -103,6 +136,12 @@ type Projectile struct {
 	AoE       int
 	AoEDamage float64
 
+	// DamageType is the type of the Damage and AoEDamage
+	DamageType damage.Damage
+
+	// Effect is applied to the Units damaged
+	Effect *buff.Effect
+
 	PlayerID string
 
 	ImageKey string
@@ -147,6 +186,9 @@ type Tower struct {
 
 	Health float64
 
+	// Targeting is how the Tower chooses
+	// the Unit to attack
+	Targeting    targeting.Targeting
 	TargetUnitID string
 	LastAttack   time.Time
 }
@@ -159,27 +201,38 @@ func (t *Tower) CanTarget(env environment.Environment) bool {
 }
 func (t *Tower) CanUpdateTo(tt string) bool {
 	for _, u := range tower.Towers[t.Type].Updates {
-		if u.String() == tt {
+		if u == tt {
 			return true
 		}
 	}
 	return false
 }
 
-func (t *Tower) CanAttackUnit(u *Unit) bool {
-	if !t.CanTarget(unit.Units[u.Type].Environment) || u.HasBuff(buff.Burrowoed) || u.HasBuff(buff.Resurrecting) {
+// CanAttackUnit checks if u is on the Range of the Tower of the Balance b
+func (t *Tower) CanAttackUnit(b *balance.Balance, u *Unit) bool {
+	if !t.CanTarget(unit.Units[u.Type].Environment) || !u.CanBeAttacked() {
 		return false
 	}
 
 	// If we do not take the center of the tower, towers would calculate everything from the top right
 	// which is not good as a short range tower would not be able to attack from the right for example.
-	// We add 16 as it's the distance from the center of the tower to the edge of it so the range ignores that part
-	centerTower := utils.Object{X: t.X + 16, Y: t.Y + 16}
-	return u.Object.IsCollidingCircle(centerTower, tower.Towers[t.Type].Range*32+16)
+	return u.Object.IsCollidingCircle(t.Center(), t.RangeRadius(b))
 }
 
-func (t *Tower) CanAttack(tm time.Time) bool {
-	return tm.Sub(t.LastAttack) > time.Duration(int(tower.Towers[t.Type].AttackSpeed*float64(time.Second)))
+// Center returns the middle point of the Tower
+func (t *Tower) Center() utils.Object {
+	return utils.Object{X: t.X + float64(t.W)/2, Y: t.Y + float64(t.H)/2}
+}
+
+// RangeRadius returns the radius in pixels of the Range of the Tower from
+// the Center. We add the distance from the center of the tower to the
+// farthest edge of it so the range ignores that part
+func (t *Tower) RangeRadius(b *balance.Balance) float64 {
+	return b.Tower(t.Type).Range*32 + float64(max(t.W, t.H))/2
+}
+
+func (t *Tower) CanAttack(b *balance.Balance, tm time.Time) bool {
+	return tm.Sub(t.LastAttack) > time.Duration(int(b.Tower(t.Type).AttackSpeed*float64(time.Second)))
 }
 
 type Unit struct {
@@ -201,18 +254,20 @@ type Unit struct {
 	MovementSpeed float64
 	Bounty        int
 
+	// Damage is the damage to the Towers if
+	// the Unit has the ability Attack
+	Damage float64
+
 	// The current level of the unit from the PlayerID
 	Level int
 
+	// Hero is true if the Unit is the Hero of the
+	// PlayerID, then the Level is the one of the Hero
+	Hero bool
+
 	Path     []graph.Step
 	HashPath string
 
-	// CreatedAt has the time of creation so
-	// on the next SyncState will be moved just
-	// the diff amount and then it'll be set to 'nil'
-	// so we know it's on sync
-	CreatedAt time.Time
-
 	// Abilities stores data from the abilities that
 	// the unit has and need to be kept in check, for example
 	// if it's a slime which is the other unit and if it died
@@ -222,17 +277,25 @@ type Unit struct {
 	Abilities map[string]interface{}
 	Buffs     map[string]interface{}
 
+	// Auras are the aura abilities of the Units
+	// around that are affecting this one
+	Auras []ability.Ability
+
 	// If the Unit has the ability 'Attack' it'll have a
 	// TargetTowerID if it has a Tower to attack
 	TargetTowerID string
 	LastAttack    time.Time
 }
 
-func (u *Unit) FacesetKey() string                { return unit.Units[u.Type].FacesetKey() }
-func (u *Unit) WalkKey() string                   { return unit.Units[u.Type].WalkKey() }
-func (u *Unit) AttackKey() string                 { return unit.Units[u.Type].AttackKey() }
-func (u *Unit) IdleKey() string                   { return unit.Units[u.Type].IdleKey() }
-func (u *Unit) HasAbility(a ability.Ability) bool { return unit.Units[u.Type].HasAbility(a) }
+func (u *Unit) FacesetKey() string { return unit.Units[u.Type].FacesetKey() }
+func (u *Unit) WalkKey() string    { return unit.Units[u.Type].WalkKey() }
+func (u *Unit) AttackKey() string  { return unit.Units[u.Type].AttackKey() }
+func (u *Unit) IdleKey() string    { return unit.Units[u.Type].IdleKey() }
+
+// HasAbility checks if the Unit has the ability a on the Balance b
+func (u *Unit) HasAbility(b *balance.Balance, a ability.Ability) bool {
+	return b.Unit(u.Type).HasAbility(a)
+}
 
 func (u *Unit) AddBuff(b buff.Buff) {
 	if u.Buffs == nil {
@@ -244,23 +307,17 @@ func (u *Unit) HasBuff(b buff.Buff) bool { _, ok := u.Buffs[b.String()]; return
 func (u *Unit) RemoveBuff(b buff.Buff) {
 	delete(u.Buffs, b.String())
 }
-func (u *Unit) CanBeAttacked(t time.Time) bool {
-	if u.HasBuff(buff.Burrowoed) {
-		if !u.MustUnburrow(t) {
-			u.AnimationCount += 1
-			return false
-		}
-		u.Unburrow()
-	} else if u.HasBuff(buff.Resurrecting) {
-		if !u.CanResurrect(t) {
-			return false
-		}
-		u.Resurrect()
-	}
-	return true
+
+// CanBeAttacked checks if the Unit is not burrowed nor resurrecting
+func (u *Unit) CanBeAttacked() bool {
+	return !u.HasBuff(buff.Burrowoed) && !u.HasBuff(buff.Resurrecting)
 }
 
-func (u *Unit) TakeDamage(d float64) {
+// TakeDamage reduces the Shield, or the Health if it has none, by the
+// damage d of type dt modified by the ArmorType and the Armor of the Unit
+func (u *Unit) TakeDamage(b *balance.Balance, d float64, dt damage.Damage) {
+	d *= b.Effectiveness.Factor(dt, b.Unit(u.Type).ArmorType)
+	d *= armorDamageFactor(u.Armor())
 	if u.Shield != 0 {
 		u.Shield -= d
 		if u.Shield < 0 {
@@ -270,136 +327,16 @@ func (u *Unit) TakeDamage(d float64) {
 		u.Health -= d
 	}
 }
-func (u *Unit) MustUnburrow(t time.Time) bool {
-	// As it should have the buff but it does not we just
-	// say yes
-	if u.Abilities == nil {
-		return true
-	}
-	amb, ok := u.Abilities[ability.Burrow.String()]
-	if !ok {
-		return true
-	}
-	ab, ok := amb.(AbilityBurrow)
-	if !ok {
-		return true
-	}
-	return ab.MustUnburrow(t)
-}
-
-func (u *Unit) CanUnburrow(t time.Time) bool {
-	// As it should have the buff but it does not we just
-	// say yes
-	if u.Abilities == nil {
-		return true
-	}
-	amb, ok := u.Abilities[ability.Burrow.String()]
-	if !ok {
-		return true
-	}
-	ab, ok := amb.(AbilityBurrow)
-	if !ok {
-		return true
-	}
-	return ab.CanUnburrow(t)
-}
-
-func (u *Unit) Unburrow() {
-	u.RemoveBuff(buff.Burrowoed)
-	amb, ok := u.Abilities[ability.Burrow.String()]
-	if !ok {
-		return
-	}
-
-	ab, ok := amb.(AbilityBurrow)
-	if !ok {
-		return
-	}
-
-	ab.Unburrowed = true
-	u.Abilities[ability.Burrow.String()] = ab
-	u.AnimationCount = 0
-}
-
-func (u *Unit) WasBurrowed() bool {
-	// As it should have the buff but it does not we just
-	// say yes
-	if u.Abilities == nil {
-		return false
-	}
-	amb, ok := u.Abilities[ability.Burrow.String()]
-	if !ok {
-		return false
-	}
-	ab, ok := amb.(AbilityBurrow)
-	if !ok {
-		return false
-	}
-	return ab.Unburrowed
-}
-
-func (u *Unit) CanResurrect(t time.Time) bool {
-	// As it should have the buff but it does not we just
-	// say yes
-	if u.Abilities == nil {
-		return true
-	}
-	amb, ok := u.Abilities[ability.Resurrection.String()]
-	if !ok {
-		return true
-	}
-	ab, ok := amb.(AbilityResurrection)
-	if !ok {
-		return true
-	}
-	return ab.CanResurrect(t)
-}
-
-func (u *Unit) Resurrect() {
-	u.RemoveBuff(buff.Resurrecting)
-	ar, ok := u.Abilities[ability.Resurrection.String()]
-	if !ok {
-		return
-	}
-
-	ab, ok := ar.(AbilityResurrection)
-	if !ok {
-		return
-	}
-
-	ab.Resurrected = true
-	u.Abilities[ability.Resurrection.String()] = ab
-	u.AnimationCount = 0
-	// TODO: Figure out when to use the other ranks
-	u.Health = u.MaxHealth * resurrectionRank1
-}
-
-func (u *Unit) WasResurrected() bool {
-	// As it should have the buff but it does not we just
-	// say yes
-	if u.Abilities == nil {
-		return false
-	}
-	amb, ok := u.Abilities[ability.Resurrection.String()]
-	if !ok {
-		return false
-	}
-	ab, ok := amb.(AbilityResurrection)
-	if !ok {
-		return false
-	}
-	return ab.Resurrected
-}
-
-func (u *Unit) Hybrid(cp, op int) {
+func (u *Unit) Hybrid(b *balance.Balance, cp, op int) {
 	if cp < op {
 		return
 	}
 	// This is the Percentage Difference
 	// TODO: Potentially show this as a Buff
 	p := float64(((cp - op) / ((cp + op) / 2)) * 100)
-	bu := unit.Units[u.Type]
-	uu := unitUpdate(u.Level, u.Type, bu.Stats)
+	p *= abilityParams[ability.HybridParams](b, u, ability.Hybrid).Factor
+	bu := b.Unit(u.Type)
+	uu := unitUpdate(b, u.Level, u.Type, bu.Stats)
 
 	var (
 		hp float64 = 1
@@ -417,11 +354,11 @@ func (u *Unit) Hybrid(cp, op int) {
 	u.Shield = u.MaxShield * sp
 }
 
-func (u *Unit) CanAttack(tm time.Time) bool {
-	if !u.HasAbility(ability.Attack) {
+func (u *Unit) CanAttack(b *balance.Balance, tm time.Time) bool {
+	if !u.HasAbility(b, ability.Attack) {
 		return false
 	}
-	return tm.Sub(u.LastAttack) > time.Duration(int(unit.Units[u.Type].AttackSpeed*float64(time.Second)))
+	return tm.Sub(u.LastAttack) > time.Duration(int(b.Unit(u.Type).AttackSpeed*float64(time.Second)))
 }
 
 type Player struct {
@@ -439,6 +376,9 @@ type Player struct {
 
 	// UnitUpdates holds the current unit level
 	UnitUpdates map[string]UnitUpdate
+
+	// Hero is the state of the Hero of the Player
+	Hero Hero
 }
 
 type UnitUpdate struct {
@@ -456,34 +396,59 @@ type UnitUpdate struct {
 	Next unit.Stats
 }
 
-func (p Player) CanSummonUnit(ut string) bool {
-	return (p.Gold-p.UnitUpdates[ut].Current.Gold) >= 0 && (p.Capacity+1 <= utils.MaxCapacity)
+func (p Player) CanSummonUnit(gr action.GameRules, ut string) bool {
+	return gr.AllowsUnit(ut) && (p.Gold-p.UnitUpdates[ut].Current.Gold) >= 0 && (p.Capacity+1 <= gr.MaxCapacity)
 }
-func (p Player) CanUpdateUnit(ut string) bool {
-	return (p.Gold - p.UnitUpdates[ut].UpdateCost) >= 0
+func (p Player) CanUpdateUnit(gr action.GameRules, ut string) bool {
+	return gr.AllowsUnit(ut) && (p.Gold-p.UnitUpdates[ut].UpdateCost) >= 0
 }
-func (p Player) CanUpdateTower(tt string) bool {
-	return (p.Gold - tower.Towers[tt].Gold) >= 0
+func (p Player) CanUpdateTower(b *balance.Balance, gr action.GameRules, tt string) bool {
+	return gr.AllowsTower(tt) && (p.Gold-b.Tower(tt).Gold) >= 0
 }
-func (p Player) CanPlaceTower(tt string) bool {
-	return (p.Gold - tower.Towers[tt].Gold) >= 0
+func (p Player) CanPlaceTower(b *balance.Balance, gr action.GameRules, tt string) bool {
+	return gr.AllowsTower(tt) && (p.Gold-b.Tower(tt).Gold) >= 0
 }
 
 func NewGame(d *flux.Dispatcher[*action.Action], s *Store) *Game {
 	l := &Game{
-		store: s,
+		store:     s,
+		snapshots: make(map[int]action.SyncStatePayload),
 	}
+	l.seed(0)
 
-	l.ReduceStore = flux.NewReduceStore(d, l.Reduce, GameState{
+	l.state = GameState{
 		Lines:       make(map[int]*Line),
 		Players:     make(map[string]*Player),
-		IncomeTimer: incomeTimer,
-		StartedAt:   time.Now(),
-	})
+		IncomeTimer: s.Rules().IncomeTimer,
+		StartedAt:   s.clock.Now(),
+	}
+	l.ReduceStore = flux.NewReduceStore(d, l.Reduce, l.state)
 
 	return l
 }
 
+// GetState returns the current GameState, the callers from
+// other goroutines have to hold the mxLines to read it
+func (g *Game) GetState() GameState {
+	return g.state
+}
+
+// seed initializes the RNG and the ID generator of the simulation
+func (g *Game) seed(s int64) {
+	g.rand = rand.New(rand.NewSource(s))
+	g.uuid = uuid.NewGenWithOptions(uuid.WithRandomReader(g.rand))
+}
+
+// newID returns a new ID generated from the simulation RNG
+func (g *Game) newID() string {
+	return uuid.Must(g.uuid.NewV4()).String()
+}
+
+// tickTime returns the game time of the current state.Tick
+func tickTime(state GameState) time.Time {
+	return state.StartedAt.Add(time.Duration(state.Tick) * TickDuration)
+}
+
 func (g *Game) ListLines() []*Line {
 	g.mxLines.RLock()
 	defer g.mxLines.RUnlock()
@@ -606,7 +571,58 @@ func (g *Game) GetStartedAt() time.Time {
 	return state.StartedAt
 }
 
+func (g *Game) GetTick() int {
+	g.mxLines.RLock()
+	defer g.mxLines.RUnlock()
+
+	state := g.GetState()
+	return state.Tick
+}
+
+// GetError returns the last Error of the game and when it happened
+func (g *Game) GetError() (string, time.Time) {
+	g.mxLines.RLock()
+	defer g.mxLines.RUnlock()
+
+	state := g.GetState()
+	return state.Error, state.ErrorAt
+}
+
+// GetTickTime returns the game time of the current Tick
+func (g *Game) GetTickTime() time.Time {
+	g.mxLines.RLock()
+	defer g.mxLines.RUnlock()
+
+	return tickTime(g.GetState())
+}
+
+// GetSnapshotID returns the ID of the last SyncState applied
+func (g *Game) GetSnapshotID() int {
+	g.mxLines.RLock()
+	defer g.mxLines.RUnlock()
+
+	return g.snapshotID
+}
+
+// IsSuddenDeath returns if the SuddenDeath of the GameRules has started
+func (g *Game) IsSuddenDeath() bool {
+	g.mxLines.RLock()
+	defer g.mxLines.RUnlock()
+
+	return g.isSuddenDeath(g.GetState())
+}
+
 func (g *Game) Reduce(state GameState, act *action.Action) GameState {
+	state = g.reduce(state, act)
+
+	g.mxLines.Lock()
+	defer g.mxLines.Unlock()
+
+	g.state = state
+	return state
+}
+
+func (g *Game) reduce(state GameState, act *action.Action) GameState {
 	switch act.Type {
 	case action.IncomeTick:
 		g.mxLines.Lock()
@@ -614,7 +630,7 @@ func (g *Game) Reduce(state GameState, act *action.Action) GameState {
 
 		state.IncomeTimer -= 1
 		if state.IncomeTimer == 0 {
-			state.IncomeTimer = incomeTimer
+			state.IncomeTimer = g.store.Rules().IncomeTimer
 			for _, p := range state.Players {
 				p.Gold += p.Income
 			}
@@ -635,89 +651,112 @@ func (g *Game) Reduce(state GameState, act *action.Action) GameState {
 			break
 		}
 
+		b := g.store.Balance()
+		gr := g.store.Rules()
 		p := &Player{
 			ID:       act.AddPlayer.ID,
 			Name:     act.AddPlayer.Name,
 			ImageKey: act.AddPlayer.ImageKey,
-			Lives:    20,
+			Lives:    gr.Lives,
 			LineID:   act.AddPlayer.LineID,
-			Income:   25,
-			Gold:     40,
+			Income:   gr.Income,
+			Gold:     gr.Gold,
 			IsBot:    act.AddPlayer.IsBot,
 
 			UnitUpdates: make(map[string]UnitUpdate),
 		}
-		for _, u := range unit.Units {
-			p.UnitUpdates[u.Type.String()] = CalculateUnitUpdate(u.Type.String(), UnitUpdate{
-				Current: u.Stats,
-				Level:   1,
-
-				UpdateCost: updateCostFactor * u.Stats.Gold,
-				Next:       unitUpdate(2, u.Type.String(), u.Stats),
-			}, 1)
+		for ut := range b.Units {
+			p.UnitUpdates[ut] = CalculateUnitUpdate(b, ut, UnitUpdate{}, 1)
 		}
 
 		state.Players[act.AddPlayer.ID] = p
+		// The rules are set after the Game is created
+		// so the timer is started when adding the players
+		state.IncomeTimer = gr.IncomeTimer
 	case action.StartGame:
+		g.GetDispatcher().WaitFor(g.store.Map.GetDispatcherToken())
+
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
 
+		g.store.SetRules(act.StartGame.Rules)
 		for _, p := range state.Players {
 			state.Lines[p.LineID] = g.newLine(p.LineID)
 		}
 		g.syncState(&state, act.StartGame.State)
+		state.Seed = act.StartGame.Seed
+		g.seed(state.Seed)
+		g.snapshots = make(map[int]action.SyncStatePayload)
+		g.snapshotID = 0
 	case action.PlaceTower:
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
 
+		b := g.store.Balance()
 		p := state.Players[act.PlaceTower.PlayerID]
 
-		if !p.CanPlaceTower(act.PlaceTower.Type) {
-			state.Error = fmt.Sprintf("Cannot place tower %s", tower.Towers[act.PlaceTower.Type].Name())
-			state.ErrorAt = time.Now()
+		if !p.CanPlaceTower(b, g.store.Rules(), act.PlaceTower.Type) {
+			state.Error = fmt.Sprintf("Cannot place tower %s", b.Tower(act.PlaceTower.Type).Name)
+			state.ErrorAt = g.store.clock.Now()
 			break
 		}
 
-		var w, h int = 16 * 2, 16 * 2
-		tid := uuid.Must(uuid.NewV4())
+		w, h := b.Tower(act.PlaceTower.Type).Size()
 		tw := g.newTower(act.PlaceTower.Type, p, utils.Object{
 			X: float64(act.PlaceTower.X), Y: float64(act.PlaceTower.Y),
 			W: w, H: h,
-		})
-		tw.ID = tid.String()
+		}, tickTime(state))
+		tw.ID = g.newID()
 
 		l := state.Lines[p.LineID]
 		err := l.Graph.AddTower(tw.ID, act.PlaceTower.X, act.PlaceTower.Y, tw.W, tw.H)
 		if err != nil {
 			state.Error = err.Error()
-			state.ErrorAt = time.Now()
+			state.ErrorAt = g.store.clock.Now()
 			break
 		}
 
-		p.Gold -= tower.Towers[act.PlaceTower.Type].Gold
+		p.Gold -= b.Tower(act.PlaceTower.Type).Gold
 		l.Towers[tw.ID] = tw
 
-		g.recalculateLineUnitStepsAndMove(state, p.LineID, noTowerID)
+		g.recalculateLineUnitSteps(state, p.LineID, noTowerID)
 	case action.UpdateTower:
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
 
+		b := g.store.Balance()
 		p := state.Players[act.UpdateTower.PlayerID]
 		l := state.Lines[p.LineID]
 		t := l.Towers[act.UpdateTower.TowerID]
 
-		tw := g.newTower(act.UpdateTower.TowerType, p, t.Object)
+		tw := g.newTower(act.UpdateTower.TowerType, p, t.Object, tickTime(state))
 
-		if !t.CanUpdateTo(act.UpdateTower.TowerType) || !p.CanUpdateTower(tw.Type) {
-			state.Error = fmt.Sprintf("Cannot update to tower %s", tower.Towers[act.UpdateTower.TowerType].Name())
-			state.ErrorAt = time.Now()
+		if !t.CanUpdateTo(act.UpdateTower.TowerType) || !p.CanUpdateTower(b, g.store.Rules(), tw.Type) {
+			state.Error = fmt.Sprintf("Cannot update to tower %s", b.Tower(act.UpdateTower.TowerType).Name)
+			state.ErrorAt = g.store.clock.Now()
 			break
 		}
 
-		p.Gold -= tower.Towers[tw.Type].Gold
+		p.Gold -= b.Tower(tw.Type).Gold
 		tw.ID = t.ID
+		tw.Targeting = t.Targeting
 		l.Towers[tw.ID] = tw
 
+	case action.UpdateTowerTargeting:
+		g.mxLines.Lock()
+		defer g.mxLines.Unlock()
+
+		p := state.Players[act.UpdateTowerTargeting.PlayerID]
+		t, ok := state.Lines[p.LineID].Towers[act.UpdateTowerTargeting.TowerID]
+		if !ok || !act.UpdateTowerTargeting.Targeting.IsATargeting() {
+			break
+		}
+
+		t.Targeting = act.UpdateTowerTargeting.Targeting
+		// The current target was chosen with the previous
+		// Targeting so a new one has to be chosen
+		t.TargetUnitID = ""
+
 	case action.RemoveTower:
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
@@ -726,23 +765,24 @@ func (g *Game) Reduce(state GameState, act *action.Action) GameState {
 		l := state.Lines[p.LineID]
 		t := l.Towers[act.RemoveTower.TowerID]
 
-		state.Players[act.RemoveTower.PlayerID].Gold += tower.Towers[t.Type].Gold / 2
+		state.Players[act.RemoveTower.PlayerID].Gold += g.store.Balance().Tower(t.Type).Gold / 2
 
 		// TODO: Add the LineID
 		for lid, l := range state.Lines {
 			if ok := l.Graph.RemoveTower(act.RemoveTower.TowerID); ok {
 				delete(l.Towers, act.RemoveTower.TowerID)
-				g.recalculateLineUnitStepsAndMove(state, lid, act.RemoveTower.TowerID)
+				g.recalculateLineUnitSteps(state, lid, act.RemoveTower.TowerID)
 			}
 		}
 	case action.SummonUnit:
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
 
+		b := g.store.Balance()
 		cp := state.Players[act.SummonUnit.PlayerID]
-		if !cp.CanSummonUnit(act.SummonUnit.Type) {
-			state.Error = fmt.Sprintf("Cannot summon unit %s", unit.Units[act.SummonUnit.Type].Name())
-			state.ErrorAt = time.Now()
+		if !cp.CanSummonUnit(g.store.Rules(), act.SummonUnit.Type) {
+			state.Error = fmt.Sprintf("Cannot summon unit %s", b.Unit(act.SummonUnit.Type).Name())
+			state.ErrorAt = g.store.clock.Now()
 			break
 		}
 		state.Players[act.SummonUnit.PlayerID].Income += cp.UnitUpdates[act.SummonUnit.Type].Current.Income
@@ -751,22 +791,7 @@ func (g *Game) Reduce(state GameState, act *action.Action) GameState {
 		cp.Capacity += 1
 
 		uu := cp.UnitUpdates[act.SummonUnit.Type]
-		bu := unit.Units[act.SummonUnit.Type]
-
-		l := state.Lines[act.SummonUnit.CurrentLineID]
-
-		var w, h int = 16, 16
-		var n = l.Graph.GetRandomSpawnNode()
-		uid := uuid.Must(uuid.NewV4())
-		u := &Unit{
-			MovingObject: utils.MovingObject{
-				Object: utils.Object{
-					X: float64(n.X), Y: float64(n.Y),
-					W: w, H: h,
-				},
-				Facing: utils.Down,
-			},
-			ID:            uid.String(),
+		g.spawnUnit(state, &Unit{
 			Type:          act.SummonUnit.Type,
 			PlayerID:      act.SummonUnit.PlayerID,
 			PlayerLineID:  act.SummonUnit.PlayerLineID,
@@ -778,23 +803,81 @@ func (g *Game) Reduce(state GameState, act *action.Action) GameState {
 			Level:         uu.Level,
 			MovementSpeed: uu.Current.MovementSpeed,
 			Bounty:        uu.Current.Income,
-			CreatedAt:     time.Now(),
-			LastAttack:    time.Now(),
+			Damage:        uu.Current.Damage,
+		})
+	case action.SummonHero:
+		g.mxLines.Lock()
+		defer g.mxLines.Unlock()
+
+		b := g.store.Balance()
+		cp := state.Players[act.SummonHero.PlayerID]
+		if !cp.CanSummonHero(b, g.store.Rules()) {
+			state.Error = "Cannot summon the hero"
+			state.ErrorAt = g.store.clock.Now()
+			break
 		}
+		cp.Gold -= cp.HeroCost(b)
+		cp.Capacity += 1
 
-		if u.HasAbility(ability.Hybrid) {
-			u.Hybrid(cp.Income, g.findPlayerByLineID(act.SummonUnit.CurrentLineID).Income)
+		u := &Unit{
+			Type:          cp.HeroType(),
+			PlayerID:      act.SummonHero.PlayerID,
+			PlayerLineID:  act.SummonHero.PlayerLineID,
+			CurrentLineID: act.SummonHero.CurrentLineID,
+			Level:         cp.Hero.Level(),
+			Hero:          true,
 		}
+		g.spawnUnit(state, u)
+		cp.Hero.Summoned = true
+		cp.Hero.UnitID = u.ID
+	case action.UseHeroAbility:
+		g.mxLines.Lock()
+		defer g.mxLines.Unlock()
 
-		u.Path, u.TargetTowerID = l.Graph.Path(u.X, u.Y, u.MovementSpeed, u.Facing, l.Graph.DeathNode.X, l.Graph.DeathNode.Y, bu.Environment, u.HasAbility(ability.Attack), atScale, useCache)
-		u.HashPath = graph.HashSteps(u.Path)
-		l.Units[u.ID] = u
+		cp := state.Players[act.UseHeroAbility.PlayerID]
+		a := act.UseHeroAbility.Ability
+		t := tickTime(state)
+		u, l := findHeroUnit(state, cp)
+		var msg string
+		switch {
+		case !a.IsAAbility():
+			msg = "Invalid hero ability"
+		case u == nil || !u.CanBeAttacked():
+			msg = "The hero is not on the field"
+		case cp.HeroCooldown(a, t) > 0:
+			msg = fmt.Sprintf("%s is on cooldown for %.0fs", hero.Name(a), math.Ceil(cp.HeroCooldown(a, t).Seconds()))
+		}
+		if msg != "" {
+			state.Error = msg
+			state.ErrorAt = g.store.clock.Now()
+			break
+		}
+		g.useHeroAbility(l, u, a, t)
+		if cp.Hero.UsedAt == nil {
+			cp.Hero.UsedAt = make(map[hero.Ability]time.Time)
+		}
+		cp.Hero.UsedAt[a] = t
 	case action.TPS:
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
 
+		// Without Time (the client) each TPS is one tick, with it
+		// we run all the ticks needed to reach that time
+		tick := state.Tick + 1
+		if !act.TPS.Time.IsZero() {
+			tick = int(act.TPS.Time.Sub(state.StartedAt) / TickDuration)
+		}
+		lids := make([]int, 0, len(state.Lines))
 		for lid := range state.Lines {
-			g.moveLineUnitsTo(state, lid, act.TPS.Time)
+			lids = append(lids, lid)
+		}
+		sort.Ints(lids)
+		for state.Tick < tick {
+			state.Tick++
+			t := tickTime(state)
+			for _, lid := range lids {
+				g.moveLineUnits(state, lid, t)
+			}
 		}
 	case action.RemovePlayer:
 		g.mxLines.Lock()
@@ -825,470 +908,546 @@ func (g *Game) Reduce(state GameState, act *action.Action) GameState {
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
 
-		u := unit.Units[act.UpdateUnit.Type]
+		b := g.store.Balance()
 		buu := state.Players[act.UpdateUnit.PlayerID].UnitUpdates[act.UpdateUnit.Type]
 
-		if !state.Players[act.UpdateUnit.PlayerID].CanUpdateUnit(act.UpdateUnit.Type) {
-			state.Error = fmt.Sprintf("Cannot update unit %s", unit.Units[act.UpdateUnit.Type].Name())
-			state.ErrorAt = time.Now()
+		if !state.Players[act.UpdateUnit.PlayerID].CanUpdateUnit(g.store.Rules(), act.UpdateUnit.Type) {
+			state.Error = fmt.Sprintf("Cannot update unit %s", b.Unit(act.UpdateUnit.Type).Name())
+			state.ErrorAt = g.store.clock.Now()
 			break
 		}
 
 		state.Players[act.UpdateUnit.PlayerID].Gold -= buu.UpdateCost
-		state.Players[act.UpdateUnit.PlayerID].UnitUpdates[act.UpdateUnit.Type] = UnitUpdate{
-			Current:    buu.Next,
-			Level:      buu.Level + 1,
-			UpdateCost: updateCostFactor * buu.Next.Gold,
-			Next:       unitUpdate(buu.Level+2, u.Type.String(), u.Stats),
-		}
+		state.Players[act.UpdateUnit.PlayerID].UnitUpdates[act.UpdateUnit.Type] = CalculateUnitUpdate(b, act.UpdateUnit.Type, buu, buu.Level+1)
 
 	case action.SyncState:
 		g.mxLines.Lock()
 		defer g.mxLines.Unlock()
 
-		g.syncState(&state, *act.SyncState)
+		ss, ok := g.applySyncStateDelta(*act.SyncState)
+		if !ok {
+			break
+		}
+		g.syncState(&state, ss)
 
 	}
 	return state
 }
 
-// recalculateLineUnitStepsAndMove  will recalculate the paths on lid. The twID is if
-// a tower, the one with the ID, was removed to the Attackers should move
-// It also moves the units
-func (g *Game) recalculateLineUnitStepsAndMove(state GameState, lid int, twID string) {
-	t := time.Now()
-	g.moveLineUnitsTo(state, lid, t)
-
-	g.recalculateLineUnitSteps(state, lid, twID)
-}
-
 // recalculateLineUnitSteps will recalculate the paths on lid. The twID is if
 // a tower, the one with the ID, was removed to the Attackers should move
 func (g *Game) recalculateLineUnitSteps(state GameState, lid int, twID string) {
+	b := g.store.Balance()
 	l := state.Lines[lid]
 	for _, u := range l.Units {
 		// This means the unit is attacking the tower so no need to recalculate any path
-		if u.HasAbility(ability.Attack) && ((twID == "" || u.TargetTowerID != twID) && len(u.Path) == 0) {
+		if u.HasAbility(b, ability.Attack) && ((twID == "" || u.TargetTowerID != twID) && len(u.Path) == 0) {
 			continue
 		}
-		u.Path, u.TargetTowerID = l.Graph.Path(u.X, u.Y, u.MovementSpeed, u.Facing, l.Graph.DeathNode.X, l.Graph.DeathNode.Y, unit.Units[u.Type].Environment, u.HasAbility(ability.Attack), atScale, useCache)
+		// The ones following the flow field only have the Path to the next
+		// Node, if it can still reach the end the field already has the change
+		if l.followsFlow(b, u) && len(u.Path) != 0 {
+			ls := u.Path[len(u.Path)-1]
+			if _, ok := l.Graph.FlowDistance(int(ls.X), int(ls.Y)); ok {
+				continue
+			}
+		}
+		u.Path, u.TargetTowerID = l.unitPath(b, u)
 		u.HashPath = graph.HashSteps(u.Path)
 	}
 }
 
-// moveLineUnitsTo will move the units of 'lid' to the 't' time compared to the last time they
-// were moved.
+// followsFlow checks if u moves by following the flow field of the Line
+// one Node at a time, which are the Terrestrial units without a target
+func (l *Line) followsFlow(b *balance.Balance, u *Unit) bool {
+	return l.Graph.HasFlowField() && b.Unit(u.Type).Environment == environment.Terrestrial && !u.HasAbility(b, ability.Attack)
+}
+
+// unitPath returns the Path of u to the end of the Line and the Tower it targets. For the
+// Units that follow the flow field it's only the Path to the next Node, unless they
+// are out of it, like inside a Tower, and have to search for the whole path
+func (l *Line) unitPath(b *balance.Balance, u *Unit) ([]graph.Step, string) {
+	if l.followsFlow(b, u) {
+		if p := l.Graph.FlowSteps(u.X, u.Y, u.MovementSpeed, u.Facing); len(p) != 0 {
+			return p, ""
+		}
+	}
+	return l.Graph.Path(u.X, u.Y, u.MovementSpeed, u.Facing, l.Graph.DeathNode.X, l.Graph.DeathNode.Y, b.Unit(u.Type).Environment, u.HasAbility(b, ability.Attack), atScale, useCache)
+}
+
+// stepUnit moves u to the next Step of the Path and, if it follows the flow field
+// and reached the Node, sets the Path to the next one unless it's the end of the Line
+func (l *Line) stepUnit(b *balance.Balance, u *Unit) {
+	u.step()
+	if len(u.Path) != 0 || !l.followsFlow(b, u) || l.Graph.GetNodeOf(int(u.X), int(u.Y)) == l.Graph.DeathNode {
+		return
+	}
+	u.Path, u.TargetTowerID = l.unitPath(b, u)
+	u.HashPath = graph.HashSteps(u.Path)
+}
+
+// moveLineUnits will run one simulation tick on 'lid' being 't' the
+// game time of that tick.
 // It'll also check:
 // * If towers can attack
 // * If units can use abilities
 // * If units are dead, reached end or can go to the next line
-func (g *Game) moveLineUnitsTo(state GameState, lid int, t time.Time) {
+func (g *Game) moveLineUnits(state GameState, lid int, t time.Time) {
+	b := g.store.Balance()
 	l := state.Lines[lid]
-	lmoves := 1
-	if !t.IsZero() && !l.UpdatedAt.IsZero() {
-		lmoves = int(t.Sub(l.UpdatedAt).Milliseconds() / tpsMS)
-	}
+	// All the iterations are done on sorted lists so the
+	// result does not depend on the order of the maps
+	units := l.ListSortedUnits()
 	// First of all we need to get all the units that could be Unburrowed
 	// in this TPS so then we can check if any unit after moving is steping
 	// on one of them and unburrow it
 	burrowedUnits := make(map[string]*Unit)
-	for _, u := range l.Units {
-		if u.CanUnburrow(t) {
+	for _, u := range units {
+		if u.CanUnburrow(b, t) {
 			burrowedUnits[u.ID] = u
 		}
 	}
+	calculateAuras(b, units)
 	// We'll move all the Units 1 by 1 so we can calculate if they have
 	// an aura around and if they have been attacked/killed and if they
 	// reached the end to steal a live and change lines
-	for i := 1; i < lmoves+1; i++ {
-		for _, u := range l.Units {
-			if !u.CanBeAttacked(t) {
-				continue
-			}
-			// If a unit was added in between the TPS checks
-			// we only need to move partially
-			if !t.IsZero() && !u.CreatedAt.IsZero() {
-				um := int(t.Sub(u.CreatedAt).Milliseconds() / tpsMS)
-				// We only want to consider this unit move when
-				// it should do it
-				// If for some reason it gets desynchronized even just for
-				// 1 ms then the unit it'll be too old to ever get moved
-				// so if the expected time to move is too big we just move it
-				if um != (lmoves-i) && um < lmoves {
-					continue
-				}
-				// This way we mean it's up to date now
-				u.CreatedAt = time.Time{}
-			}
-			// TODO: Investigate why this is a case to check
-			// as if it's 0 it should be read for the next if
-			// and delete/change line
-			//
-			// This moves the unit to the next Path position
-			if len(u.Path) != 0 {
-				nextStep := u.Path[0]
-				u.Path = u.Path[1:]
-				u.MovingCount += 1
-				u.Y = nextStep.Y
-				u.X = nextStep.X
-				u.Facing = nextStep.Facing
+	for _, u := range units {
+		if !g.tickAbilities(u, t) {
+			continue
+		}
+		if !g.applyEffects(state, l, u, t) {
+			continue
+		}
+		u.applyAuras()
+		// TODO: Investigate why this is a case to check
+		// as if it's 0 it should be read for the next if
+		// and delete/change line
+		//
+		// This moves the unit to the next Path position
+		if len(u.Path) != 0 && u.canMove() {
+			l.stepUnit(b, u)
+			// The SpeedAura moves an extra step every few steps
+			if len(u.Path) != 0 && u.HasAura(ability.SpeedAura) && u.MovingCount%speedAuraSteps == 0 {
+				l.stepUnit(b, u)
 			}
+		}
 
-			// We check if the new path is stepping into a burrowed
-			// unit so we need to unburrow it
-			for bid, bu := range burrowedUnits {
-				if u.IsColliding(bu.Object) {
-					bu.Unburrow()
-					delete(burrowedUnits, bid)
-				}
+		// We check if the new path is stepping into a burrowed
+		// unit so we need to unburrow it
+		for bid, bu := range burrowedUnits {
+			if u.IsColliding(bu.Object) {
+				bu.Unburrow()
+				delete(burrowedUnits, bid)
 			}
+		}
 
-			// We reached the end of the line
-			// If it has the ability 'Attack' we have to check if it has
-			// a TargetTowerID as then it means is attacking and not that
-			// reached the end of the line
-			if len(u.Path) == 0 {
-				if u.HasAbility(ability.Attack) && u.TargetTowerID != "" {
-					// Attacking the tower
-					u.AnimationCount += 1
-					if u.CanAttack(t) {
-						cu := state.Players[u.PlayerID].UnitUpdates[u.Type]
-						tw, ok := l.Towers[u.TargetTowerID]
-						if ok {
-							tw.Health -= cu.Current.Damage
-							u.LastAttack = t
-							if tw.Health <= 0 {
-								tw.Health = 0
-								if ok := l.Graph.RemoveTower(tw.ID); ok {
-									delete(l.Towers, tw.ID)
-									g.recalculateLineUnitSteps(state, lid, tw.ID)
-								}
+		// We reached the end of the line
+		// If it has the ability 'Attack' we have to check if it has
+		// a TargetTowerID as then it means is attacking and not that
+		// reached the end of the line
+		if len(u.Path) == 0 {
+			if u.HasAbility(b, ability.Attack) && u.TargetTowerID != "" {
+				// Attacking the tower
+				u.AnimationCount += 1
+				if u.CanAttack(b, t) && !u.HasBuff(buff.Stunned) {
+					tw, ok := l.Towers[u.TargetTowerID]
+					if ok {
+						tw.Health -= u.Damage
+						u.LastAttack = t
+						if tw.Health <= 0 {
+							tw.Health = 0
+							if ok := l.Graph.RemoveTower(tw.ID); ok {
+								delete(l.Towers, tw.ID)
+								g.recalculateLineUnitSteps(state, lid, tw.ID)
 							}
 						}
 					}
-				} else {
-					// Reached the end of the line so we have to steal
-					// one live and move to the next line (if any)
-					var fpID string
-					for pid, p := range state.Players {
-						if p.LineID == lid {
-							fpID = pid
-							break
-						}
+				}
+			} else {
+				// Reached the end of the line so we have to steal
+				// one live and move to the next line (if any)
+				var fpID string
+				for pid, p := range state.Players {
+					if p.LineID == lid {
+						fpID = pid
+						break
 					}
-					// We steal a Live
-					g.stealLive(state, fpID, u.PlayerID)
-					nlid := g.store.Map.GetNextLineID(u.CurrentLineID)
-					// If the next line is the owner of the Unit we remove it
-					// If not then we change the unit to the next line
-					if nlid == u.PlayerLineID {
-						cp := state.Players[u.PlayerID]
-						// We check if the unit is a Split and then we check if the other partner is in
-						// the same line
-						if u.HasAbility(ability.Split) && u.Abilities != nil {
-							if as, ok := u.Abilities[ability.Split.String()].(AbilitySplit); ok {
-								if _, ok := l.Units[as.UnitID]; !ok {
-									// We know now the other is not in the line and it's the last one so we can reduce capacity
-									cp.Capacity -= 1
-								}
-							}
-						} else {
-							cp.Capacity -= 1
-						}
-						delete(state.Lines[lid].Units, u.ID)
-					} else {
-						g.changeUnitLine(state, u, nlid)
+				}
+				// We steal a Live
+				g.stealLive(state, fpID, u.PlayerID)
+				nlid := g.store.Map.GetNextLineID(u.CurrentLineID)
+				// If the next line is the owner of the Unit we remove it
+				// If not then we change the unit to the next line
+				if nlid == u.PlayerLineID {
+					cp := state.Players[u.PlayerID]
+					// We check if the unit is a Split and then we check if the other partner is in
+					// the same line
+					if u.isLastSplit(l) {
+						// We know now the other is not in the line and it's the last one so we can reduce capacity
+						cp.Capacity -= 1
 					}
+					delete(state.Lines[lid].Units, u.ID)
+				} else {
+					g.changeUnitLine(state, u, nlid)
 				}
 			}
 		}
-		// Now we need to move the Projectiles and check if they are hitting the target
-		// we'll also check first if the projectile is already hitting the target as it
-		// could happen that the unit move towards the projectile
-		for pid, p := range l.Projectiles {
-			u, ok := l.Units[p.TargetUnitID]
-			if !ok {
-				// If there is no unit for that projectile we just remove it
-				delete(l.Projectiles, pid)
-				continue
+	}
+	// Now we need to move the Projectiles and check if they are hitting the target
+	// we'll also check first if the projectile is already hitting the target as it
+	// could happen that the unit move towards the projectile
+	for _, p := range l.listSortedProjectiles() {
+		pid := p.ID
+		u, ok := l.Units[p.TargetUnitID]
+		if !ok {
+			// If there is no unit for that projectile we just remove it
+			delete(l.Projectiles, pid)
+			continue
+		}
+		if p.IsColliding(u.Object) {
+			g.attackUnit(state, l, p, u, t)
+			delete(l.Projectiles, pid)
+			continue
+		}
+		distance := p.PDistance(u.Object)
+
+		vx := (u.X - p.X)
+		vy := (u.Y - p.Y)
+
+		p.X = 3/distance*vx + p.X
+		p.Y = 3/distance*vy + p.Y
+
+		if p.IsColliding(u.Object) {
+			g.attackUnit(state, l, p, u, t)
+			delete(l.Projectiles, pid)
+			continue
+		}
+
+		p.CalculateImageKey(vx, vy)
+	}
+
+	//if !g.store.isOnServer {
+	//// If we are on the client we do not calculate any of those things
+	//continue
+	//}
+
+	// Now that the unit has moved we'll calculate if any
+	// tower can attack any Unit in their new positions
+	units = l.ListSortedUnits()
+	for _, tw := range l.ListSortedTowers() {
+		if !tw.CanAttack(b, t) {
+			continue
+		}
+		var targetUnit *Unit
+		if tw.TargetUnitID != "" {
+			if u, ok := l.Units[tw.TargetUnitID]; ok {
+				if tw.CanAttackUnit(b, u) {
+					targetUnit = u
+				} else {
+					tw.TargetUnitID = ""
+				}
+			} else {
+				tw.TargetUnitID = ""
 			}
-			if p.IsColliding(u.Object) {
-				g.attackUnit(state, l, p, u, t)
-				delete(l.Projectiles, pid)
-				continue
+		}
+		if targetUnit == nil {
+			targetUnit = chooseTarget(state, b, l, tw, units)
+		}
+		if targetUnit != nil {
+			// TODO: Should we change the current target if a priority target comes to range?
+			ot := b.Tower(tw.Type)
+			pid := g.newID()
+			c := tw.Center()
+			p := &Projectile{
+				ID: pid,
+				// The Projectile starts at the middle of the tower
+				Object: utils.Object{
+					X: c.X,
+					Y: c.Y,
+					W: 13, H: 5,
+				},
+				TargetUnitID: targetUnit.ID,
+				Damage:       ot.Damage,
+				AoE:          ot.AoE,
+				AoEDamage:    ot.AoEDamage,
+				DamageType:   ot.DamageType,
+				Effect:       ot.Effect,
+				PlayerID:     tw.PlayerID,
+				Type:         projectileCannonball,
 			}
-			distance := p.PDistance(u.Object)
 
-			vx := (u.X - p.X)
-			vy := (u.Y - p.Y)
+			if ot.ShootsArrows() {
+				p.Type = projectileArrow
 
-			p.X = 3/distance*vx + p.X
-			p.Y = 3/distance*vy + p.Y
+				vx := (targetUnit.X - p.X)
+				vy := (targetUnit.Y - p.Y)
 
-			if p.IsColliding(u.Object) {
-				g.attackUnit(state, l, p, u, t)
-				delete(l.Projectiles, pid)
-				continue
+				p.CalculateImageKey(vx, vy)
 			}
 
-			p.CalculateImageKey(vx, vy)
+			l.Projectiles[pid] = p
+			// The attack was done so we register it
+			tw.LastAttack = t
+			tw.TargetUnitID = targetUnit.ID
 		}
+	}
+}
 
-		//if !g.store.isOnServer {
-		//// If we are on the client we do not calculate any of those things
-		//continue
-		//}
+// chooseTarget returns the Unit from units of the Line l that tw attacks
+// depending on the Tower.Targeting. The Camouflage units are only attacked
+// if there is no other Unit on range
+func chooseTarget(state GameState, b *balance.Balance, l *Line, tw *Tower, units []*Unit) *Unit {
+	var (
+		target    *Unit
+		camTarget *Unit
+	)
+	if tw.Targeting == targeting.MostExpensive {
+		return chooseMostExpensiveTarget(state, b, tw, units)
+	}
+	for _, u := range units {
+		if !tw.CanAttackUnit(b, u) {
+			continue
+		}
+		if u.IsCamouflaged(b) {
+			if camTarget == nil || isBetterTarget(state, b, l, tw, u, camTarget) {
+				camTarget = u
+			}
+		} else if target == nil || isBetterTarget(state, b, l, tw, u, target) {
+			target = u
+		}
+	}
+	if target == nil {
+		return camTarget
+	}
+	return target
+}
 
-		// Now that the unit has moved we'll calculate if any
-		// tower can attack any Unit in their new positions
-		for _, tw := range l.Towers {
-			if !tw.CanAttack(t) {
-				continue
+// isBetterTarget checks if u is a better target than c for the tw
+// Targeting, on a tie c is kept so the first one is the target
+func isBetterTarget(state GameState, b *balance.Balance, l *Line, tw *Tower, u, c *Unit) bool {
+	switch tw.Targeting {
+	case targeting.First:
+		return u.remainingDistance(l) < c.remainingDistance(l)
+	case targeting.Last:
+		return u.remainingDistance(l) > c.remainingDistance(l)
+	case targeting.Strongest:
+		return u.Health+u.Shield > c.Health+c.Shield
+	case targeting.Weakest:
+		return u.Health+u.Shield < c.Health+c.Shield
+	case targeting.Closest:
+		tc := tw.Center()
+		return u.PDistance(tc) < c.PDistance(tc)
+	case targeting.AerialFirst:
+		ua := b.Unit(u.Type).Environment == environment.Aerial
+		ca := b.Unit(c.Type).Environment == environment.Aerial
+		if ua != ca {
+			return ua
+		}
+	}
+	return unitCost(state, u) > unitCost(state, c)
+}
+
+// remainingDistance returns the distance in pixels u has to move to reach the
+// end of the Line l, each Step moves the MovementSpeed on the basic TPS. The
+// Units following the flow field only have the Path to the next Node so
+// the distance from it is the one of the flow field
+func (u *Unit) remainingDistance(l *Line) float64 {
+	d := float64(len(u.Path)) * u.MovementSpeed / 60
+	if len(u.Path) != 0 && u.TargetTowerID == "" {
+		ls := u.Path[len(u.Path)-1]
+		if fd, ok := l.Graph.FlowDistance(int(ls.X), int(ls.Y)); ok {
+			d += float64(fd)
+		}
+	}
+	return d
+}
+
+// unitCost returns the gold that cost to summon u
+func unitCost(state GameState, u *Unit) int {
+	return state.Players[u.PlayerID].UnitUpdates[u.Type].Current.Gold
+}
+
+// chooseMostExpensiveTarget returns the Unit with the greatest cost on range
+// of tw giving priority to the ones that can attack the Towers
+func chooseMostExpensiveTarget(state GameState, b *balance.Balance, tw *Tower, units []*Unit) *Unit {
+	var (
+		minCost     int = 0
+		minCostUnit *Unit
+
+		// The potential Camouflage units
+		isAttacker     bool
+		minCostCam     int = 0
+		minCostCamUnit *Unit
+	)
+	for _, u := range units {
+		if !tw.CanAttackUnit(b, u) {
+			continue
+		}
+		// Target is based on the unit with the greatest cost
+		ug := unitCost(state, u)
+		if u.IsCamouflaged(b) {
+			if minCostCam == 0 {
+				minCostCam = ug
+				minCostCamUnit = u
 			}
-			// Get the closes unit to the current tower to attack it
-			var (
-				minCost     int = 0
-				minCostUnit *Unit
-
-				// The potential Camouflage units
-				isAttacker     bool
-				minCostCam     int = 0
-				minCostCamUnit *Unit
-
-				//targetUnit *Unit
-			)
-			if tw.TargetUnitID != "" {
-				if u, ok := l.Units[tw.TargetUnitID]; ok {
-					if tw.CanAttackUnit(u) {
-						minCostUnit = u
-						//targetUnit = u
-					} else {
-						tw.TargetUnitID = ""
-					}
-				} else {
-					tw.TargetUnitID = ""
-				}
+			if ug > minCostCam {
+				minCostCam = ug
+				minCostCamUnit = u
 			}
-			if minCostUnit == nil {
-				for _, u := range l.Units {
-					if !tw.CanAttackUnit(u) {
-						continue
+		} else {
+			if u.HasAbility(b, ability.Attack) {
+				if !isAttacker {
+					minCost = ug
+					minCostUnit = u
+				} else {
+					if minCost == 0 {
+						minCost = ug
 					}
-					// Target is based on the unit with the greatest cost
-					up := state.Players[u.PlayerID]
-					ug := up.UnitUpdates[u.Type].Current.Gold
-					if u.HasAbility(ability.Camouflage) {
-						if minCostCam == 0 {
-							minCostCam = ug
-							minCostCamUnit = u
-						}
-						if ug > minCostCam {
-							minCostCam = ug
-							minCostCamUnit = u
-						}
-					} else {
-						if u.HasAbility(ability.Attack) {
-							if !isAttacker {
-								minCost = ug
-								minCostUnit = u
-							} else {
-								if minCost == 0 {
-									minCost = ug
-								}
-								if ug >= minCost {
-									minCost = ug
-									minCostUnit = u
-								}
-							}
-						} else {
-							if minCost == 0 {
-								minCost = ug
-							}
-							if ug >= minCost {
-								minCost = ug
-								minCostUnit = u
-							}
-						}
+					if ug >= minCost {
+						minCost = ug
+						minCostUnit = u
 					}
 				}
-			}
-			if minCostUnit == nil && minCostCamUnit != nil {
-				minCostUnit = minCostCamUnit
-			}
-			if minCostUnit != nil {
-				// TODO: Should we change the current target if a priority target comes to range?
-				// We replace the minCostUnit calculated with the targetUnit only if the minCostUnit has 'Attack' (top priority)
-				// and if the targetUnit has 'Camouflage' (no priority)
-				//if !minCostUnit.HasAbility(ability.Attack) && targetUnit != nil && !targetUnit.HasAbility(ability.Camouflage) {
-				//minCostUnit = targetUnit
-				//}
-				ot := tower.Towers[tw.Type]
-				pid := uuid.Must(uuid.NewV4()).String()
-				p := &Projectile{
-					// The Projectile starts at the middle of the tower
-					Object: utils.Object{
-						X: tw.X + 16,
-						Y: tw.Y + 16,
-						W: 13, H: 5,
-					},
-					TargetUnitID: minCostUnit.ID,
-					Damage:       ot.Damage,
-					AoE:          ot.AoE,
-					AoEDamage:    ot.AoEDamage,
-					PlayerID:     tw.PlayerID,
-					Type:         projectileCannonball,
+			} else {
+				if minCost == 0 {
+					minCost = ug
 				}
-
-				if ot.ShootsArrows() {
-					p.Type = projectileArrow
-
-					vx := (minCostUnit.X - p.X)
-					vy := (minCostUnit.Y - p.Y)
-
-					p.CalculateImageKey(vx, vy)
+				if ug >= minCost {
+					minCost = ug
+					minCostUnit = u
 				}
-
-				l.Projectiles[pid] = p
-				// The attack was done so we register it
-				tw.LastAttack = t
-				tw.TargetUnitID = minCostUnit.ID
 			}
 		}
 	}
-	l.UpdatedAt = t
+	if minCostUnit == nil && minCostCamUnit != nil {
+		minCostUnit = minCostCamUnit
+	}
+	return minCostUnit
 }
 
 func (g *Game) attackUnit(state GameState, l *Line, p *Projectile, tu *Unit, t time.Time) {
 	// Tower Attack
-	tu.TakeDamage(p.Damage)
-	g.checkAfterDamage(state, l, p, tu, t)
+	b := g.store.Balance()
+	tu.TakeDamage(b, p.Damage, p.DamageType)
+	g.applyProjectileEffect(l, p, tu, t)
+	g.checkAfterDamage(state, l, p.PlayerID, tu, t)
 	// If the Tower does AoE Damage we need to check again all the units
 	// except the current and damage them
 	if p.AoE != 0 {
 		centerUnit := utils.Object{X: tu.X + 8, Y: tu.Y + 8}
-		for _, u := range l.Units {
+		for _, u := range l.ListSortedUnits() {
 			if u.ID == tu.ID {
 				continue
 			}
-			if !u.CanBeAttacked(t) {
+			// It could have been removed by a previous damage
+			if _, ok := l.Units[u.ID]; !ok {
+				continue
+			}
+			if !u.CanBeAttacked() {
 				continue
 			}
 			if !u.IsCollidingCircle(centerUnit, float64(p.AoE)*16) {
 				continue
 			}
-			u.TakeDamage(p.AoEDamage)
-			g.checkAfterDamage(state, l, p, u, t)
+			u.TakeDamage(b, p.AoEDamage, p.DamageType)
+			g.applyProjectileEffect(l, p, u, t)
+			g.checkAfterDamage(state, l, p.PlayerID, u, t)
 		}
 	}
 }
 
-func (g *Game) checkAfterDamage(state GameState, l *Line, p *Projectile, u *Unit, t time.Time) {
-	if u.Health <= u.MaxHealth/2 && u.HasAbility(ability.Burrow) && !u.WasBurrowed() {
-		if u.Abilities == nil {
-			u.Abilities = make(map[string]interface{})
-		}
-		u.Abilities[ability.Burrow.String()] = AbilityBurrow{
-			BurrowAt: time.Now(),
-		}
-		u.AddBuff(buff.Burrowoed)
+// applyProjectileEffect applies the Effect of p, if any, to the Unit u
+func (g *Game) applyProjectileEffect(l *Line, p *Projectile, u *Unit, t time.Time) {
+	if p.Effect == nil || u.Health <= 0 {
+		return
+	}
+	u.ApplyEffect(*p.Effect, p.PlayerID, t)
+}
+
+// checkAfterDamage checks the abilities that trigger with the damage
+// and if the Unit is killed, then the Player pid gets the Bounty
+func (g *Game) checkAfterDamage(state GameState, l *Line, pid string, u *Unit, t time.Time) {
+	hs := unitHandlers(g.store.Balance(), u)
+	for _, h := range hs {
+		h.onDamage(g, u, t)
 	}
 	// Unit is killed
 	if u.Health <= 0 {
-		if u.HasAbility(ability.Split) {
-			if u.Abilities != nil {
-				if as, ok := u.Abilities[ability.Split.String()].(AbilitySplit); ok {
-					// TODO: Check if it moved into another line
-					if _, ok := l.Units[as.UnitID]; !ok {
-						u.Health = 0
-
-						// Unit Killed by player so we give gold to the player
-						cp := state.Players[p.PlayerID]
-						cp.Gold += u.Bounty
-
-						// Delete Unit as we know this is the last one of the split left
-						// in the lane so we also reduce the capacity
-						up := state.Players[u.PlayerID]
-						up.Capacity -= 1
-						delete(l.Units, u.ID)
-						return
-					}
-				}
-			} else {
-				// TODO: This should only be done on the server not on the client port
-				u1 := *u
-				u2 := *u
-
-				u1.ID = uuid.Must(uuid.NewV4()).String()
-				u2.ID = uuid.Must(uuid.NewV4()).String()
-
-				h := u.MaxHealth / 2
-
-				u1.MaxHealth = h
-				u1.Health = h
-				u2.MaxHealth = h
-				u2.Health = h
-
-				u1.MovementSpeed = u1.MovementSpeed * 1.20
-				u2.MovementSpeed = u2.MovementSpeed * 1.20
-
-				// The second unit created we move it 10 positions forward if possible
-				for i := 0; i < 20; i++ {
-					if len(u2.Path) != 0 {
-						nextStep := u2.Path[0]
-						u2.Path = u2.Path[1:]
-						u2.MovingCount += 1
-						u2.Y = nextStep.Y
-						u2.X = nextStep.X
-						u2.Facing = nextStep.Facing
-					}
-				}
+		for _, h := range hs {
+			if h.onDeath(g, state, l, pid, u, t) {
+				return
+			}
+		}
+		g.killUnit(state, l, pid, u)
+	}
+}
 
-				if u1.Abilities == nil {
-					u1.Abilities = make(map[string]interface{})
-					u2.Abilities = make(map[string]interface{})
-				}
+// killUnit removes the Unit u from the Line l and
+// if pid is not empty the Player pid gets the Bounty
+func (g *Game) killUnit(state GameState, l *Line, pid string, u *Unit) {
+	u.Health = 0
 
-				u1.Abilities[ability.Split.String()] = AbilitySplit{
-					UnitID: u2.ID,
-				}
-				u2.Abilities[ability.Split.String()] = AbilitySplit{
-					UnitID: u1.ID,
-				}
+	// Unit Killed by player so we give gold to the player
+	if pid != "" {
+		cp := state.Players[pid]
+		cp.Gold += u.Bounty
+		g.addHeroXP(state, pid, heroKillXP)
+	}
 
-				l.Units[u1.ID] = &u1
-				l.Units[u2.ID] = &u2
-			}
-		} else if u.HasAbility(ability.Resurrection) && !u.WasResurrected() {
-			u.Health = 0
-			if u.Abilities == nil {
-				u.Abilities = make(map[string]interface{})
-			}
-			u.Abilities[ability.Resurrection.String()] = AbilityResurrection{
-				KilledAt: t,
-			}
-			u.AddBuff(buff.Resurrecting)
-			return
-		} else {
-			u.Health = 0
+	up := state.Players[u.PlayerID]
+	up.Capacity -= 1
+	delete(l.Units, u.ID)
+}
 
-			// Unit Killed by player so we give gold to the player
-			cp := state.Players[p.PlayerID]
-			cp.Gold += u.Bounty
-		}
+// spawnUnit adds the Unit u on a random spawn node of its CurrentLineID,
+// if it's a Hero it gets the stats of the Hero of its Level
+func (g *Game) spawnUnit(state GameState, u *Unit) {
+	b := g.store.Balance()
+	l := state.Lines[u.CurrentLineID]
 
-		// Delete Unit
-		up := state.Players[u.PlayerID]
-		up.Capacity -= 1
-		delete(l.Units, u.ID)
+	if u.Hero {
+		u.setHeroStats(heroStats(b, u.Type, u.Level))
+	}
+
+	n := l.Graph.GetRandomSpawnNode(g.rand)
+	u.MovingObject = utils.MovingObject{
+		Object: utils.Object{
+			X: float64(n.X), Y: float64(n.Y),
+			W: 16, H: 16,
+		},
+		Facing: utils.Down,
+	}
+	u.ID = g.newID()
+	u.LastAttack = tickTime(state)
+
+	for _, h := range unitHandlers(b, u) {
+		h.onLineChange(g, state, u)
+	}
+
+	u.Path, u.TargetTowerID = l.unitPath(b, u)
+	u.HashPath = graph.HashSteps(u.Path)
+	l.Units[u.ID] = u
+}
+
+// tickAbilities runs the abilities of the Unit u at the start of the
+// tick of t and returns false if the Unit has to skip this tick
+func (g *Game) tickAbilities(u *Unit, t time.Time) bool {
+	for _, h := range unitHandlers(g.store.Balance(), u) {
+		if !h.onTick(g, u, t) {
+			return false
+		}
 	}
+	return true
 }
 
 func (g *Game) newLine(lid int) *Line {
-	x, y := g.store.Map.GetHomeCoordinates(lid)
-	gph, err := graph.New(x+16, y+16, 16, 84, 16, 7, 74, 3)
+	gph, err := g.store.Map.GetLine(lid).Graph(16)
 	if err != nil {
 		panic(err)
 	}
+	// The paths of the units to the end of the Line follow the
+	// flow field so the towers changes do not need a search per unit
+	gph.EnableFlowField()
 	return &Line{
 		ID:          lid,
 		Towers:      make(map[string]*Tower),
@@ -1298,22 +1457,22 @@ func (g *Game) newLine(lid int) *Line {
 	}
 }
 
-func unitUpdate(nlvl int, ut string, u unit.Stats) unit.Stats {
-	bu := unit.Units[ut]
+func unitUpdate(b *balance.Balance, nlvl int, ut string, u unit.Stats) unit.Stats {
+	bu := b.Unit(ut)
 
-	u.Health = float64(levelToValue(nlvl, int(bu.Health)))
-	u.Damage = float64(levelToValue(nlvl, int(bu.Damage)))
-	u.Gold = levelToValue(nlvl, bu.Gold)
+	u.Health = float64(levelToValue(b, nlvl, int(bu.Health)))
+	u.Damage = float64(levelToValue(b, nlvl, int(bu.Damage)))
+	u.Gold = levelToValue(b, nlvl, bu.Gold)
 	u.Income = int(math.Round(float64(u.Gold) / float64(incomeFactor)))
-	u.Shield = float64(levelToValue(nlvl, int(bu.Shield)))
+	u.Shield = float64(levelToValue(b, nlvl, int(bu.Shield)))
 
 	return u
 }
 
-func levelToValue(lvl, base int) int {
+func levelToValue(b *balance.Balance, lvl, base int) int {
 	fb := float64(base)
 	for i := 1; i < lvl; i++ {
-		fb = fb * math.Pow(math.E, updateFactor)
+		fb = fb * math.Pow(math.E, b.UpdateFactor)
 	}
 	return int(math.Round(fb))
 }
@@ -1321,12 +1480,18 @@ func levelToValue(lvl, base int) int {
 func (g *Game) stealLive(state GameState, fpID, tpID string) {
 	fp := state.Players[fpID]
 	tp := state.Players[tpID]
+	g.addHeroXP(state, tpID, heroLiveXP)
 
-	fp.Lives -= 1
-	if fp.Lives < 0 {
+	if g.isSuddenDeath(state) {
+		tp.Lives += fp.Lives
 		fp.Lives = 0
 	} else {
-		tp.Lives += 1
+		fp.Lives -= 1
+		if fp.Lives < 0 {
+			fp.Lives = 0
+		} else {
+			tp.Lives += 1
+		}
 	}
 
 	var stillPlayersLeft bool
@@ -1344,6 +1509,12 @@ func (g *Game) stealLive(state GameState, fpID, tpID string) {
 	}
 }
 
+// isSuddenDeath returns if the SuddenDeath of the GameRules has started
+func (g *Game) isSuddenDeath(state GameState) bool {
+	sd := g.store.Rules().SuddenDeath
+	return sd > 0 && state.Tick >= sd*int(time.Second/TickDuration)
+}
+
 func (g *Game) changeUnitLine(state GameState, u *Unit, nlid int) {
 	cl := state.Lines[u.CurrentLineID]
 	// As we are gonna move it to another line
@@ -1352,32 +1523,58 @@ func (g *Game) changeUnitLine(state GameState, u *Unit, nlid int) {
 
 	u.CurrentLineID = nlid
 
+	b := g.store.Balance()
 	nl := state.Lines[u.CurrentLineID]
 
-	n := nl.Graph.GetRandomSpawnNode()
+	n := nl.Graph.GetRandomSpawnNode(g.rand)
 	u.X = float64(n.X)
 	u.Y = float64(n.Y)
 
-	u.Path, u.TargetTowerID = nl.Graph.Path(u.X, u.Y, u.MovementSpeed, u.Facing, nl.Graph.DeathNode.X, nl.Graph.DeathNode.Y, unit.Units[u.Type].Environment, u.HasAbility(ability.Attack), atScale, useCache)
+	u.Path, u.TargetTowerID = nl.unitPath(b, u)
 	u.HashPath = graph.HashSteps(u.Path)
 
-	u.CreatedAt = time.Now()
-	if u.HasAbility(ability.Hybrid) {
-		u.Hybrid(state.Players[u.PlayerID].Income, g.findPlayerByLineID(nlid).Income)
+	for _, h := range unitHandlers(b, u) {
+		h.onLineChange(g, state, u)
 	}
 	nl.Units[u.ID] = u
 }
 
-func (g *Game) newTower(tt string, p *Player, o utils.Object) *Tower {
-	ot := tower.Towers[tt]
+func (g *Game) newTower(tt string, p *Player, o utils.Object, t time.Time) *Tower {
+	ot := g.store.Balance().Tower(tt)
 	return &Tower{
 		Object:     o,
 		Type:       tt,
 		LineID:     p.LineID,
 		PlayerID:   p.ID,
 		Health:     ot.Health,
-		LastAttack: time.Now(),
+		LastAttack: t,
+	}
+}
+
+// applySyncStateDelta returns the full SyncState of ss, which may only have the
+// changes from a previous snapshot. If the base snapshot is unknown it returns
+// false and the state will be fixed on the next keyframe
+func (g *Game) applySyncStateDelta(ss action.SyncStatePayload) (action.SyncStatePayload, bool) {
+	if ss.Version != action.SyncStateVersion {
+		return ss, false
+	}
+	if !ss.Keyframe {
+		base, ok := g.snapshots[ss.BaseSnapshotID]
+		if !ok {
+			return ss, false
+		}
+		ss = base.Apply(ss)
 	}
+
+	g.snapshots[ss.SnapshotID] = ss
+	g.snapshotID = ss.SnapshotID
+	for id := range g.snapshots {
+		if id <= ss.SnapshotID-action.SyncStateHistory {
+			delete(g.snapshots, id)
+		}
+	}
+
+	return ss, true
 }
 
 func (g *Game) syncState(state *GameState, ss action.SyncStatePayload) {
@@ -1408,48 +1605,42 @@ func (g *Game) syncState(state *GameState, ss action.SyncStatePayload) {
 					nu.Y = ou.Y
 				}
 			}
+			// The payload is kept as a snapshot so the
+			// maps cannot be shared with the Unit
+			nu.Buffs = maps.Clone(nu.Buffs)
 			if nu.Abilities != nil {
+				nu.Abilities = maps.Clone(nu.Abilities)
 				for k, v := range nu.Abilities {
-					switch k {
-					case ability.Split.String():
-						var a AbilitySplit
-						_ = mapstructure.Decode(v, &a)
-						nu.Abilities[k] = a
-					case ability.Burrow.String():
-						var a AbilityBurrow
-						d, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-							DecodeHook:       mapstructure.StringToTimeHookFunc(time.RFC3339Nano),
-							WeaklyTypedInput: true,
-							Result:           &a,
-						})
-						_ = d.Decode(v)
-						nu.Abilities[k] = a
-					case ability.Resurrection.String():
-						var a AbilityResurrection
-						d, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-							DecodeHook:       mapstructure.StringToTimeHookFunc(time.RFC3339Nano),
-							WeaklyTypedInput: true,
-							Result:           &a,
-						})
-						_ = d.Decode(v)
-						nu.Abilities[k] = a
-					default:
+					a, err := ability.AbilityString(k)
+					if err != nil {
+						log.Fatal(fmt.Sprintf("ability %s not found", k))
+					}
+					h, ok := abilityHandlers[a]
+					if !ok {
 						log.Fatal(fmt.Sprintf("ability %s not found", k))
 					}
+					nu.Abilities[k] = h.decodeState(v)
+				}
+			}
+			// The Effects are decoded as maps so they have to
+			// be converted back to the BuffEffect
+			for k, v := range nu.Buffs {
+				bf, err := buff.BuffString(k)
+				if err != nil || !bf.IsEffect() {
+					continue
+				}
+				if _, ok := v.(BuffEffect); ok {
+					continue
 				}
+				var be BuffEffect
+				d, _ := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+					DecodeHook:       mapstructure.StringToTimeHookFunc(time.RFC3339Nano),
+					WeaklyTypedInput: true,
+					Result:           &be,
+				})
+				_ = d.Decode(v)
+				nu.Buffs[k] = be
 			}
-			//if nu.Buffs != nil {
-			//for k, v := range nu.Buffs {
-			//switch k {
-			//case buff.Burrowoed.String():
-			//var b BuffBurrowed
-			//_ = mapstructure.Decode(v, &b)
-			//nu.Buffs[k] = b
-			//default:
-			//log.Fatal(fmt.Sprintf("buff %s not found", k))
-			//}
-			//}
-			//}
 
 			cl.Units[id] = &nu
 		}
@@ -1531,6 +1722,7 @@ func (g *Game) syncState(state *GameState, ss action.SyncStatePayload) {
 			Winner:      p.Winner,
 			Capacity:    p.Capacity,
 			UnitUpdates: make(map[string]UnitUpdate),
+			Hero:        Hero(p.Hero),
 		}
 		for t, uu := range p.UnitUpdates {
 			np.UnitUpdates[t] = UnitUpdate(uu)
@@ -1542,24 +1734,25 @@ func (g *Game) syncState(state *GameState, ss action.SyncStatePayload) {
 	}
 	state.IncomeTimer = ss.Players.IncomeTimer
 	state.StartedAt = ss.StartedAt
+	state.Tick = ss.Tick
 }
 
-// CalculateUnitUpdate will return the UnitUpdate of t
-func CalculateUnitUpdate(t string, buu UnitUpdate, lvl int) UnitUpdate {
-	u := unit.Units[t]
+// CalculateUnitUpdate will return the UnitUpdate of t on the Balance b
+func CalculateUnitUpdate(b *balance.Balance, t string, buu UnitUpdate, lvl int) UnitUpdate {
+	u := b.Unit(t)
 	if lvl == 1 {
 		return UnitUpdate{
 			Current:    u.Stats,
 			Level:      lvl,
-			UpdateCost: updateCostFactor * u.Gold,
-			Next:       unitUpdate(lvl+1, t, u.Stats),
+			UpdateCost: b.UpdateCostFactor * u.Gold,
+			Next:       unitUpdate(b, lvl+1, t, u.Stats),
 		}
 	}
 	uu := UnitUpdate{
 		Current:    buu.Next,
 		Level:      buu.Level + 1,
-		UpdateCost: updateCostFactor * buu.Next.Gold,
-		Next:       unitUpdate(buu.Level+2, u.Type.String(), u.Stats),
+		UpdateCost: b.UpdateCostFactor * buu.Next.Gold,
+		Next:       unitUpdate(b, buu.Level+2, t, u.Stats),
 	}
 	return uu
 }
@@ -0,0 +1,202 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/tower/targeting"
+	"github.com/xescugc/maze-wars/unit"
+)
+
+func TestGame_Deterministic(t *testing.T) {
+	play := func(seed int64) store.GameState {
+		s := newSeededGameStore(t, "", seed, nil)
+
+		g := s.Game.FindLineByID(1).Graph
+		s.Dispatch(action.NewPlaceTower("range1", "p2", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		s.Dispatch(action.NewPlaceTower("range1", "p2", g.OffsetX+(g.Scale*4), g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		for i := 0; i < 4; i++ {
+			s.Dispatch(action.NewSummonUnit(unit.Ninja.String(), "p1", 0, 1))
+		}
+		for i := 1; i <= 40; i++ {
+			s.Dispatch(action.NewTPS(startedAt.Add(time.Duration(i) * time.Second / 4)))
+		}
+
+		return s.Game.GetState()
+	}
+
+	s1 := play(42)
+	s2 := play(42)
+
+	assert.Equal(t, 10*60, s1.Tick)
+	assert.NotEmpty(t, s1.Lines[1].Towers)
+	assert.Equal(t, s1, s2)
+	assert.NotEqual(t, s1, play(43))
+}
+
+func TestGame_Balance(t *testing.T) {
+	s := newGameStore(t, `{
+		"game": {"gold": 100, "lives": 5, "update_cost_factor": 2},
+		"towers": {"range1": {"gold": 10}}
+	}`)
+	b := s.Balance()
+	assert.False(t, b.IsDefault())
+
+	p := s.Game.FindPlayerByID("p1")
+	assert.Equal(t, 100, p.Gold)
+	assert.Equal(t, 5, p.Lives)
+	assert.Equal(t, 2*b.Unit(unit.Ninja.String()).Gold, p.UnitUpdates[unit.Ninja.String()].UpdateCost)
+
+	g := s.Game.FindLineByID(0).Graph
+	s.Dispatch(action.NewPlaceTower("range1", "p1", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+	assert.Equal(t, 90, s.Game.FindPlayerByID("p1").Gold)
+
+	s.SetBalance(nil)
+	assert.Equal(t, balance.Default, s.Balance())
+}
+
+func TestUnit_TakeDamage(t *testing.T) {
+	b, err := balance.Parse([]byte(`{
+		"game": {"effectiveness": {"siege": {"fortified": 2, "light": 0.5}}}
+	}`))
+	require.NoError(t, err)
+
+	tcs := []struct {
+		Name   string
+		Unit   unit.Type
+		Damage damage.Damage
+		Health float64
+	}{
+		{Name: "Strong", Unit: unit.Statue, Damage: damage.Siege, Health: 80},
+		{Name: "Weak", Unit: unit.Ninja, Damage: damage.Siege, Health: 95},
+		{Name: "Undefined", Unit: unit.Robot, Damage: damage.Siege, Health: 90},
+		{Name: "Default", Unit: unit.Ninja, Damage: damage.Piercing, Health: 85},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			u := &store.Unit{Type: tc.Unit.String(), Health: 100}
+			u.TakeDamage(b, 10, tc.Damage)
+			assert.Equal(t, tc.Health, u.Health)
+		})
+	}
+}
+
+func TestGame_TowerFootprint(t *testing.T) {
+	s := newGameStore(t, `{}`)
+	b := s.Balance()
+	b.Tower("range1").Footprint = tower.Footprint{W: 1, H: 3}
+
+	g := s.Game.FindLineByID(0).Graph
+	x, y := g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)
+	s.Dispatch(action.NewPlaceTower("range1", "p1", x, y))
+
+	l := s.Game.FindLineByID(0)
+	require.Len(t, l.Towers, 1)
+	var tw *store.Tower
+	for _, lt := range l.Towers {
+		tw = lt
+	}
+	assert.Equal(t, 16, tw.W)
+	assert.Equal(t, 48, tw.H)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, tw.ID, g.GetNodeOf(x, y+i*g.Scale).TowerID)
+	}
+	assert.False(t, g.GetNodeOf(x+g.Scale, y).HasTower())
+	assert.False(t, g.GetNodeOf(x, y+3*g.Scale).HasTower())
+
+	c := tw.Center()
+	assert.Equal(t, float64(x)+8, c.X)
+	assert.Equal(t, float64(y)+24, c.Y)
+	assert.Equal(t, b.Tower("range1").Range*32+24, tw.RangeRadius(b))
+}
+
+func TestGame_TowerTargeting(t *testing.T) {
+	target := func(tg targeting.Targeting) string {
+		// The range is big enough so all the Units are on it
+		s := newGameStore(t, `{"towers": {"range1": {"range": 20}}}`)
+
+		g := s.Game.FindLineByID(1).Graph
+		s.Dispatch(action.NewPlaceTower("range1", "p2", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		l := s.Game.FindLineByID(1)
+		require.Len(t, l.Towers, 1)
+		var tw *store.Tower
+		for _, lt := range l.Towers {
+			tw = lt
+		}
+		s.Dispatch(action.NewUpdateTowerTargeting("p2", tw.ID, tg))
+		assert.Equal(t, tg, tw.Targeting)
+
+		s.Dispatch(action.NewSummonUnit(unit.Ninja.String(), "p1", 0, 1))
+		s.Dispatch(action.NewSummonUnit(unit.Statue.String(), "p1", 0, 1))
+		// The Tower can attack once the AttackSpeed has passed
+		s.Dispatch(action.NewTPS(startedAt.Add(time.Second)))
+
+		l = s.Game.FindLineByID(1)
+		u, ok := l.Units[l.Towers[tw.ID].TargetUnitID]
+		require.True(t, ok)
+		return u.Type
+	}
+
+	assert.Equal(t, unit.Statue.String(), target(targeting.MostExpensive))
+	assert.Equal(t, unit.Statue.String(), target(targeting.Strongest))
+	assert.Equal(t, unit.Ninja.String(), target(targeting.Weakest))
+	assert.Equal(t, unit.Statue.String(), target(targeting.First))
+	assert.Equal(t, unit.Ninja.String(), target(targeting.Last))
+}
+
+func TestGame_Rules(t *testing.T) {
+	s := newSeededGameStore(t, "", 42, func(s *store.Store) {
+		s.SetRules(action.GameRules{
+			Gold:        200,
+			Lives:       3,
+			IncomeTimer: 10,
+			MaxCapacity: 1,
+			SuddenDeath: 1,
+			Units:       []string{unit.Ninja.String()},
+			Towers:      []string{"melee1"},
+		})
+	})
+
+	gr := s.Rules()
+	assert.Equal(t, 200, gr.Gold)
+	assert.Equal(t, balance.Default.Income, gr.Income)
+
+	p := s.Game.FindPlayerByID("p1")
+	assert.Equal(t, 200, p.Gold)
+	assert.Equal(t, 3, p.Lives)
+	assert.Equal(t, balance.Default.Income, p.Income)
+	assert.Equal(t, 10, s.Game.GetIncomeTimer())
+
+	t.Run("AllowedTowers", func(t *testing.T) {
+		g := s.Game.FindLineByID(0).Graph
+		s.Dispatch(action.NewPlaceTower("range1", "p1", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		assert.Empty(t, s.Game.FindLineByID(0).Towers)
+		assert.Equal(t, 200, s.Game.FindPlayerByID("p1").Gold)
+	})
+	t.Run("AllowedUnits", func(t *testing.T) {
+		s.Dispatch(action.NewSummonUnit(unit.Statue.String(), "p2", 1, 0))
+		assert.Empty(t, s.Game.FindLineByID(0).Units)
+	})
+	t.Run("MaxCapacity", func(t *testing.T) {
+		s.Dispatch(action.NewSummonUnit(unit.Ninja.String(), "p2", 1, 0))
+		s.Dispatch(action.NewSummonUnit(unit.Ninja.String(), "p2", 1, 0))
+		assert.Len(t, s.Game.FindLineByID(0).Units, 1)
+		assert.Equal(t, 1, s.Game.FindPlayerByID("p2").Capacity)
+	})
+	t.Run("SuddenDeath", func(t *testing.T) {
+		assert.False(t, s.Game.IsSuddenDeath())
+		s.Dispatch(action.NewTPS(startedAt.Add(3 * time.Minute)))
+		assert.True(t, s.Game.IsSuddenDeath())
+
+		// The only Unit that reached the end stole all the lives
+		assert.Equal(t, 0, s.Game.FindPlayerByID("p1").Lives)
+		assert.Equal(t, 6, s.Game.FindPlayerByID("p2").Lives)
+	})
+}
@@ -2,15 +2,29 @@ package store_test
 
 import (
 	"io"
+	"testing"
+	"time"
 
 	"github.com/sagikazarmark/slog-shim"
+	"github.com/stretchr/testify/require"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
 	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/unit"
 )
 
 const isSerer = true
 
+// startedAt is when the Games of the tests start
+var startedAt = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.t }
+
 func newEmptyLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(io.Discard, nil))
 }
@@ -20,6 +34,65 @@ func initStore() *store.Store {
 	return store.NewStore(d, newEmptyLogger(), isSerer)
 }
 
+// newGameStore returns a Store with the Balance raw, or the default one
+// if empty, with the Players p1 and p2 and the Game started at startedAt
+func newGameStore(t *testing.T, raw string) *store.Store {
+	t.Helper()
+	return newSeededGameStore(t, raw, 42, nil)
+}
+
+// newSeededGameStore is like newGameStore but the Game is started
+// with the seed and the setup, if any, is called before adding the Players
+func newSeededGameStore(t *testing.T, raw string, seed int64, setup func(s *store.Store)) *store.Store {
+	t.Helper()
+
+	d := flux.NewDispatcher[*action.Action]()
+	s := store.NewStoreWithClock(d, newEmptyLogger(), isSerer, fixedClock{t: startedAt})
+	if raw != "" {
+		b, err := balance.Parse([]byte(raw))
+		require.NoError(t, err)
+		s.SetBalance(b)
+	}
+	if setup != nil {
+		setup(s)
+	}
+
+	s.Dispatch(action.NewAddPlayer("p1", "p1", unit.Ninja.String(), 0, false))
+	s.Dispatch(action.NewAddPlayer("p2", "p2", unit.Ninja.String(), 1, false))
+	startGame(s, seed)
+
+	return s
+}
+
+// startGame dispatches the StartGame at startedAt with the current players
+// and the current rules of the store
+func startGame(s *store.Store, seed int64) {
+	players := make(map[string]*action.SyncStatePlayerPayload)
+	for _, p := range s.Game.ListPlayers() {
+		sp := action.SyncStatePlayerPayload{
+			ID:          p.ID,
+			Name:        p.Name,
+			ImageKey:    p.ImageKey,
+			Lives:       p.Lives,
+			LineID:      p.LineID,
+			Income:      p.Income,
+			Gold:        p.Gold,
+			UnitUpdates: make(map[string]action.SyncStatePlayerUnitUpdatePayload),
+		}
+		for t, uu := range p.UnitUpdates {
+			sp.UnitUpdates[t] = action.SyncStatePlayerUnitUpdatePayload(uu)
+		}
+		players[p.ID] = &sp
+	}
+	sga := action.NewStartGame(action.SyncStatePayload{
+		Players:   &action.SyncStatePlayersPayload{Players: players, IncomeTimer: s.Game.GetIncomeTimer()},
+		Lines:     &action.SyncStateLinesPayload{},
+		StartedAt: startedAt,
+	}, seed)
+	sga.StartGame.Rules = s.Rules()
+	s.Dispatch(sga)
+}
+
 //func addPlayer(s *store.Store) store.Player {
 //id := uuid.Must(uuid.NewV4())
 //name := fmt.Sprintf("name-%d", len(s.Game.ListPlayers()))
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"math"
+	"time"
+
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/buff"
+	"github.com/xescugc/maze-wars/unit/hero"
+)
+
+const (
+	// heroStatsFactor multiplies the stats of the
+	// Unit the Hero is based on at level 1
+	heroStatsFactor = 3
+	// heroLevelFactor is the increase of the
+	// heroStatsFactor on each level of the Hero
+	heroLevelFactor = 0.2
+	// heroGoldFactor multiplies the Gold of the
+	// Unit to know the cost of the Hero
+	heroGoldFactor = 5
+
+	// heroLevelXP is the XP needed for each level
+	heroLevelXP = 10
+	// HeroMaxLevel is the max level of the Hero
+	HeroMaxLevel = 10
+	// heroKillXP is the XP gained for each
+	// Unit killed by the Towers of the Player
+	heroKillXP = 1
+	// heroLiveXP is the XP gained for each
+	// live stolen by the Units of the Player
+	heroLiveXP = 5
+
+	// heroDashTiles is the number of tiles the Dash moves the Hero
+	heroDashTiles = 5
+	// heroShieldArmor is the armor the Shield gives
+	// for the heroShieldDuration seconds
+	heroShieldArmor    = 10
+	heroShieldDuration = 5
+)
+
+// Hero is the state of the Hero of a Player, it's a stronger
+// version of the Unit of the Player ImageKey that can only be
+// summoned once and has abilities the Player uses
+type Hero struct {
+	// UnitID is the ID of the Unit of the Hero once
+	// summoned, it may no longer be on any Line
+	UnitID   string
+	Summoned bool
+
+	// XP is the experience of the Player
+	// which sets the level of the Hero
+	XP int
+
+	// UsedAt is the last time each hero.Ability was used
+	UsedAt map[hero.Ability]time.Time
+}
+
+// Level returns the level of the Hero
+func (h Hero) Level() int {
+	return min(HeroMaxLevel, 1+h.XP/heroLevelXP)
+}
+
+// HeroType returns the Unit type the Hero of the Player is based on
+func (p Player) HeroType() string {
+	if _, ok := unit.Units[p.ImageKey]; ok {
+		return p.ImageKey
+	}
+	return unit.Ninja.String()
+}
+
+// HeroCost returns the Gold needed to summon the Hero on the Balance b
+func (p Player) HeroCost(b *balance.Balance) int {
+	return b.Unit(p.HeroType()).Gold * heroGoldFactor
+}
+
+func (p Player) CanSummonHero(b *balance.Balance, gr action.GameRules) bool {
+	return !p.Hero.Summoned && p.Gold >= p.HeroCost(b) && p.Capacity+1 <= gr.MaxCapacity
+}
+
+// HeroCooldown returns how long until the Ability a of the Hero can be used at t
+func (p Player) HeroCooldown(a hero.Ability, t time.Time) time.Duration {
+	ua, ok := p.Hero.UsedAt[a]
+	if !ok {
+		return 0
+	}
+	return max(0, hero.Cooldown(a)-t.Sub(ua))
+}
+
+// heroStats returns the stats of a Hero of the Unit type ut on the level lvl
+func heroStats(b *balance.Balance, ut string, lvl int) unit.Stats {
+	s := b.Unit(ut).Stats
+	f := heroStatsFactor * (1 + heroLevelFactor*float64(lvl-1))
+	s.Health *= f
+	s.Shield *= f
+	s.Damage *= f
+	s.Gold *= heroGoldFactor
+	return s
+}
+
+// FindHeroUnit returns the Unit of the Hero of the Player p if it's on any Line
+func (g *Game) FindHeroUnit(p Player) (Unit, bool) {
+	g.mxLines.RLock()
+	defer g.mxLines.RUnlock()
+
+	u, _ := findHeroUnit(g.GetState(), &p)
+	if u == nil {
+		return Unit{}, false
+	}
+	return *u, true
+}
+
+// findHeroUnit returns the Unit of the Hero of the Player
+// p and the Line it's on, nil if it's not on any
+func findHeroUnit(state GameState, p *Player) (*Unit, *Line) {
+	if p.Hero.UnitID == "" {
+		return nil, nil
+	}
+	for _, l := range state.Lines {
+		if u, ok := l.Units[p.Hero.UnitID]; ok {
+			return u, l
+		}
+	}
+	return nil, nil
+}
+
+// addHeroXP adds the xp to the Hero of the Player pid, if it
+// levels up and it's alive it gets the stats of the new level
+func (g *Game) addHeroXP(state GameState, pid string, xp int) {
+	p, ok := state.Players[pid]
+	if !ok {
+		return
+	}
+	lvl := p.Hero.Level()
+	p.Hero.XP += xp
+	if p.Hero.Level() == lvl {
+		return
+	}
+	u, _ := findHeroUnit(state, p)
+	if u == nil || u.Health <= 0 {
+		return
+	}
+	u.Level = p.Hero.Level()
+	u.setHeroStats(heroStats(g.store.Balance(), u.Type, u.Level))
+}
+
+// setHeroStats sets the stats hs of the Hero to u, the Health and
+// Shield it already lost are kept
+func (u *Unit) setHeroStats(hs unit.Stats) {
+	u.Health += hs.Health - u.MaxHealth
+	u.MaxHealth = hs.Health
+	u.Shield += hs.Shield - u.MaxShield
+	u.MaxShield = hs.Shield
+	u.Damage = hs.Damage
+	u.MovementSpeed = hs.MovementSpeed
+	u.Bounty = hs.Income * heroStatsFactor
+}
+
+// useHeroAbility uses the Ability a of the Hero u that is on the Line l at t
+func (g *Game) useHeroAbility(l *Line, u *Unit, a hero.Ability, t time.Time) {
+	switch a {
+	case hero.Dash:
+		d := float64(heroDashTiles * l.Graph.Scale)
+		for len(u.Path) != 0 && d > 0 {
+			d -= math.Hypot(u.Path[0].X-u.X, u.Path[0].Y-u.Y)
+			l.stepUnit(g.store.Balance(), u)
+		}
+	case hero.Shield:
+		for _, lu := range l.Units {
+			if lu.PlayerID != u.PlayerID {
+				continue
+			}
+			lu.ApplyEffect(buff.Effect{Buff: buff.Shielded, Value: heroShieldArmor, Duration: heroShieldDuration}, u.PlayerID, t)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/unit"
+	"github.com/xescugc/maze-wars/unit/buff"
+	"github.com/xescugc/maze-wars/unit/hero"
+)
+
+func TestGame_Heroes(t *testing.T) {
+	// newStore starts a Game with 2 Players and
+	// summons the Hero of the p1 on the Line 1
+	newStore := func(t *testing.T) (*store.Store, store.Unit) {
+		s := newGameStore(t, `{"game": {"gold": 10000}, "units": {"ninja": {"damage": 1}}}`)
+
+		p := s.Game.FindPlayerByID("p1")
+		require.True(t, p.CanSummonHero(s.Balance(), s.Rules()))
+		s.Dispatch(action.NewSummonHero("p1", 0, 1))
+
+		hu, ok := s.Game.FindHeroUnit(s.Game.FindPlayerByID("p1"))
+		require.True(t, ok)
+		return s, hu
+	}
+
+	t.Run("Summon", func(t *testing.T) {
+		s, hu := newStore(t)
+		p := s.Game.FindPlayerByID("p1")
+
+		bu := s.Balance().Unit(unit.Ninja.String())
+		assert.True(t, hu.Hero)
+		assert.Equal(t, 1, hu.Level)
+		assert.Greater(t, hu.MaxHealth, bu.Health)
+		assert.Greater(t, hu.Damage, bu.Damage)
+		assert.True(t, p.Hero.Summoned)
+		assert.Equal(t, 10000-p.HeroCost(s.Balance()), p.Gold)
+		assert.Equal(t, 1, p.Capacity)
+
+		// It can only be summoned once
+		assert.False(t, p.CanSummonHero(s.Balance(), s.Rules()))
+		s.Dispatch(action.NewSummonHero("p1", 0, 1))
+		assert.Len(t, s.Game.FindLineByID(1).Units, 1)
+		assert.Equal(t, p.Gold, s.Game.FindPlayerByID("p1").Gold)
+	})
+	t.Run("Dash", func(t *testing.T) {
+		s, hu := newStore(t)
+
+		s.Dispatch(action.NewUseHeroAbility("p1", hero.Dash))
+		du, ok := s.Game.FindHeroUnit(s.Game.FindPlayerByID("p1"))
+		require.True(t, ok)
+		assert.Greater(t, du.Y, hu.Y)
+
+		// The second use is blocked by the cooldown
+		s.Dispatch(action.NewUseHeroAbility("p1", hero.Dash))
+		cu, ok := s.Game.FindHeroUnit(s.Game.FindPlayerByID("p1"))
+		require.True(t, ok)
+		assert.Equal(t, du.Y, cu.Y)
+		assert.Contains(t, s.Game.GetState().Error, "cooldown")
+	})
+	t.Run("Split", func(t *testing.T) {
+		s := newGameStore(t, `{
+			"game": {"gold": 10000},
+			"towers": {"range1": {"range": 20, "damage": 100}},
+			"units": {"ninja": {"abilities": ["split"], "ability_params": {"split": [{"speed_factor": 1, "offset": 0}]}}}
+		}`)
+		g := s.Game.FindLineByID(1).Graph
+		s.Dispatch(action.NewPlaceTower("range1", "p2", g.OffsetX, g.OffsetY+(g.Scale*g.SpawnZoneH)))
+		s.Dispatch(action.NewSummonHero("p1", 0, 1))
+
+		var units []*store.Unit
+		for i := 1; i <= 600 && len(units) < 2; i++ {
+			s.Dispatch(action.NewTPS(startedAt.Add(time.Duration(i) * store.TickDuration)))
+			units = s.Game.FindLineByID(1).ListSortedUnits()
+		}
+		require.Len(t, units, 2)
+
+		// Only one of the halves is the Hero
+		hu, ok := s.Game.FindHeroUnit(s.Game.FindPlayerByID("p1"))
+		require.True(t, ok)
+		assert.True(t, hu.Hero)
+		for _, u := range units {
+			assert.Equal(t, u.ID == hu.ID, u.Hero)
+		}
+	})
+	t.Run("Shield", func(t *testing.T) {
+		s, _ := newStore(t)
+		s.Dispatch(action.NewSummonUnit(unit.Ninja.String(), "p1", 0, 1))
+
+		s.Dispatch(action.NewUseHeroAbility("p1", hero.Shield))
+		units := s.Game.FindLineByID(1).ListSortedUnits()
+		require.Len(t, units, 2)
+		for _, u := range units {
+			assert.True(t, u.HasBuff(buff.Shielded))
+		}
+	})
+}
@@ -24,6 +24,12 @@ type Lobby struct {
 	ID         string
 	Name       string
 	MaxPlayers int
+	// Balance is the name of the balance
+	// pack, empty for the default one
+	Balance string
+	// Rules are the GameRules the Game
+	// will be played with
+	Rules action.GameRules
 	// Players holds the usernames
 	// including the owner one.
 	// And the bool value represents
@@ -95,6 +101,8 @@ func (ls *Lobbies) Reduce(state LobbiesState, act *action.Action) LobbiesState {
 			ID:         act.CreateLobby.LobbyID,
 			Name:       act.CreateLobby.LobbyName,
 			MaxPlayers: act.CreateLobby.LobbyMaxPlayers,
+			Balance:    act.CreateLobby.LobbyBalance,
+			Rules:      act.CreateLobby.LobbyRules,
 			Owner:      act.CreateLobby.Owner,
 			Players:    map[string]bool{act.CreateLobby.Owner: false},
 		}
@@ -126,6 +134,7 @@ func (ls *Lobbies) Reduce(state LobbiesState, act *action.Action) LobbiesState {
 				ID:         al.ID,
 				Name:       al.Name,
 				MaxPlayers: al.MaxPlayers,
+				Balance:    al.Balance,
 				Owner:      al.Owner,
 				Players:    al.Players,
 			}
@@ -159,6 +168,8 @@ func (ls *Lobbies) Reduce(state LobbiesState, act *action.Action) LobbiesState {
 			ID:         ulp.ID,
 			Name:       ulp.Name,
 			MaxPlayers: ulp.MaxPlayers,
+			Balance:    ulp.Balance,
+			Rules:      l.Rules,
 			Players:    ulp.Players,
 			Owner:      ulp.Owner,
 			Current:    l.Current,
@@ -1,50 +1,18 @@
 package store
 
 import (
-	"bytes"
 	"fmt"
-	"image"
 	"log"
 
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
-	"github.com/xescugc/maze-wars/assets"
+	"github.com/xescugc/maze-wars/tiled"
 )
 
 var (
-	MapImages = map[int]image.Image{}
-
 	MapImageKeyFmt = "m-%d"
 )
 
-func init() {
-	m2, _, err := image.Decode(bytes.NewReader(assets.Map_2_png))
-	if err != nil {
-		log.Fatal(err)
-	}
-	m3, _, err := image.Decode(bytes.NewReader(assets.Map_3_png))
-	if err != nil {
-		log.Fatal(err)
-	}
-	m4, _, err := image.Decode(bytes.NewReader(assets.Map_4_png))
-	if err != nil {
-		log.Fatal(err)
-	}
-	m5, _, err := image.Decode(bytes.NewReader(assets.Map_5_png))
-	if err != nil {
-		log.Fatal(err)
-	}
-	m6, _, err := image.Decode(bytes.NewReader(assets.Map_6_png))
-	if err != nil {
-		log.Fatal(err)
-	}
-	MapImages[2] = m2
-	MapImages[3] = m3
-	MapImages[4] = m4
-	MapImages[5] = m5
-	MapImages[6] = m6
-}
-
 // Map is a struct that holds all the information of the current map
 type Map struct {
 	*flux.ReduceStore[MapState, *action.Action]
@@ -54,7 +22,7 @@ type Map struct {
 
 type MapState struct {
 	Players int
-	Image   image.Image
+	Map     *tiled.Map
 }
 
 // NewMap initializes the map
@@ -64,19 +32,19 @@ func NewMap(d *flux.Dispatcher[*action.Action], s *Store) *Map {
 	}
 	m.ReduceStore = flux.NewReduceStore(d, m.Reduce, MapState{
 		Players: 2,
-		Image:   MapImages[2],
+		Map:     tiled.Maps[2],
 	})
 
 	return m
 }
 
 // GetX returns the max X value of the map
-func (m *Map) GetX() int { return m.GetState().Image.Bounds().Dx() }
+func (m *Map) GetX() int { return m.GetState().Map.W }
 
 // GetY returns the max Y value of the map
-func (m *Map) GetY() int { return m.GetState().Image.Bounds().Dy() }
+func (m *Map) GetY() int { return m.GetState().Map.H }
 
-// GetY returns the max Y value of the map
+// GetImageKey returns the key of the image of the map
 func (m *Map) GetImageKey() string {
 	pc := m.GetState().Players
 	return fmt.Sprintf(MapImageKeyFmt, pc)
@@ -93,18 +61,28 @@ func (m *Map) GetNextLineID(clid int) int {
 	return clid
 }
 
+// GetLine returns the definition of the Line lid on the map
+func (m *Map) GetLine(lid int) *tiled.Line {
+	return m.GetState().Map.Lines[lid]
+}
+
 func (m *Map) GetHomeCoordinates(lid int) (int, int) {
-	return lid*16*(16+1+10+1) + (43 * 16), (43 * 16)
+	l := m.GetLine(lid)
+	return l.X, l.Y
 }
 
 func (m *Map) Reduce(state MapState, act *action.Action) MapState {
 	switch act.Type {
 	case action.StartGame:
-		m.GetDispatcher().WaitFor(m.store.Game.GetDispatcherToken())
+		// The Players can be already on the Game or
+		// on the State of the action if it's a sync
+		state.Players = len(m.store.Game.GetState().Players)
+		if ps := act.StartGame.State.Players; ps != nil && len(ps.Players) > state.Players {
+			state.Players = len(ps.Players)
+		}
 
 		var ok bool
-		state.Players = len(m.store.Game.ListPlayers())
-		state.Image, ok = MapImages[state.Players]
+		state.Map, ok = tiled.Maps[state.Players]
 		if !ok {
 			log.Fatalf("The map for the number of players %d is not available", state.Players)
 		}
@@ -1,16 +1,13 @@
 package store_test
 
 import (
-	"bytes"
-	"image"
-	"log"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
-	"github.com/xescugc/maze-wars/assets"
 	"github.com/xescugc/maze-wars/store"
+	"github.com/xescugc/maze-wars/tiled"
 )
 
 func TestNewMap(t *testing.T) {
@@ -18,13 +15,9 @@ func TestNewMap(t *testing.T) {
 	st := store.NewStore(d, newEmptyLogger(), isSerer)
 	ms := store.NewMap(d, st)
 	mstate := ms.GetState()
-	m2, _, err := image.Decode(bytes.NewReader(assets.Map_2_png))
-	if err != nil {
-		log.Fatal(err)
-	}
 	emstate := store.MapState{
 		Players: 2,
-		Image:   m2,
+		Map:     tiled.Maps[2],
 	}
 	assert.Equal(t, emstate, mstate)
 }
@@ -1,14 +1,27 @@
 package store
 
 import (
+	"sync"
 	"time"
 
 	"github.com/sagikazarmark/slog-shim"
 	"github.com/xescugc/go-flux/v2"
 	"github.com/xescugc/maze-wars/action"
+	"github.com/xescugc/maze-wars/balance"
 	"github.com/xescugc/maze-wars/utils"
 )
 
+// Clock is the source of the wall-clock time used by the Store for
+// everything that is not driven by the simulation ticks
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the default Clock that uses the time of the system
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
 type Store struct {
 	Game    *Game
 	Map     *Map
@@ -16,14 +29,33 @@ type Store struct {
 
 	dispatcher *flux.Dispatcher[*action.Action]
 	logger     *slog.Logger
+	clock      Clock
+
+	// balance are the numbers the Game is simulated
+	// with, it has to be set before the StartGame
+	mxBalance sync.RWMutex
+	balance   *balance.Balance
+
+	// rules are the GameRules of the lobby, like
+	// the balance they are set before the StartGame
+	mxRules sync.RWMutex
+	rules   action.GameRules
 
 	isOnServer bool
 }
 
 func NewStore(d *flux.Dispatcher[*action.Action], l *slog.Logger, server bool) *Store {
+	return NewStoreWithClock(d, l, server, SystemClock{})
+}
+
+// NewStoreWithClock initializes the Store with a custom Clock, which is
+// useful for tests and replays that need to control the time
+func NewStoreWithClock(d *flux.Dispatcher[*action.Action], l *slog.Logger, server bool, c Clock) *Store {
 	s := &Store{
 		dispatcher: d,
 		logger:     l,
+		clock:      c,
+		balance:    balance.Default,
 		isOnServer: server,
 	}
 	s.Map = NewMap(d, s)
@@ -39,3 +71,58 @@ func (s *Store) Dispatch(a *action.Action) {
 
 	s.dispatcher.Dispatch(a)
 }
+
+// SetBalance sets the Balance the Game is simulated with,
+// if b is nil the balance.Default is used
+func (s *Store) SetBalance(b *balance.Balance) {
+	if b == nil {
+		b = balance.Default
+	}
+	s.mxBalance.Lock()
+	defer s.mxBalance.Unlock()
+
+	s.balance = b
+}
+
+// Balance returns the Balance the Game is simulated with
+func (s *Store) Balance() *balance.Balance {
+	s.mxBalance.RLock()
+	defer s.mxBalance.RUnlock()
+
+	return s.balance
+}
+
+// SetRules sets the GameRules of the Game
+func (s *Store) SetRules(gr action.GameRules) {
+	s.mxRules.Lock()
+	defer s.mxRules.Unlock()
+
+	s.rules = gr
+}
+
+// Rules returns the GameRules of the Game with the
+// values not set filled from the Balance
+func (s *Store) Rules() action.GameRules {
+	s.mxRules.RLock()
+	gr := s.rules
+	s.mxRules.RUnlock()
+
+	b := s.Balance()
+	if gr.Gold == 0 {
+		gr.Gold = b.Gold
+	}
+	if gr.Income == 0 {
+		gr.Income = b.Income
+	}
+	if gr.Lives == 0 {
+		gr.Lives = b.Lives
+	}
+	if gr.IncomeTimer == 0 {
+		gr.IncomeTimer = b.IncomeTimer
+	}
+	if gr.MaxCapacity == 0 {
+		gr.MaxCapacity = utils.MaxCapacity
+	}
+
+	return gr
+}
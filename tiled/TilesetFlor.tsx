@@ -1,4 +1,4 @@
 <?xml version="1.0" encoding="UTF-8"?>
 <tileset version="1.8" tiledversion="1.8.2" name="TilesetFlor" tilewidth="16" tileheight="16" tilecount="572" columns="22">
- <image source="NinjaAdventure/Backgrounds/Tilesets/TilesetFloor.png" width="352" height="417"/>
+ <image source="../assets/ninja_adventure_asset_pack/Backgrounds/Tilesets/TilesetFloor.png" width="352" height="417"/>
 </tileset>
@@ -1,4 +1,4 @@
 <?xml version="1.0" encoding="UTF-8"?>
 <tileset version="1.8" tiledversion="1.8.2" name="TilesetHouse" tilewidth="16" tileheight="16" tilecount="667" columns="29">
- <image source="NinjaAdventure/Backgrounds/Tilesets/TilesetHouse.png" width="464" height="368"/>
+ <image source="../assets/ninja_adventure_asset_pack/Backgrounds/Tilesets/TilesetHouse.png" width="464" height="368"/>
 </tileset>
@@ -1,4 +1,4 @@
 <?xml version="1.0" encoding="UTF-8"?>
 <tileset version="1.8" tiledversion="1.8.2" name="TilesetInteriorFloor" tilewidth="16" tileheight="16" tilecount="374" columns="22">
- <image source="NinjaAdventure/Backgrounds/Tilesets/Interior/TilesetInteriorFloor.png" width="352" height="272"/>
+ <image source="../assets/ninja_adventure_asset_pack/Backgrounds/Tilesets/Interior/TilesetInteriorFloor.png" width="352" height="272"/>
 </tileset>
@@ -0,0 +1,502 @@
+package tiled
+
+import (
+	"embed"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xescugc/maze-wars/utils/graph"
+)
+
+const (
+	// LinesLayer is the name of the object layer
+	// with the definition of the Lines
+	LinesLayer = "lines"
+
+	// LineProperty is the property of the objects
+	// with the ID of the Line they belong to
+	LineProperty = "line"
+
+	// The classes of the objects of the LinesLayer, the
+	// rock, water and mud ones set the graph.Terrain
+	classLine     = "line"
+	classSpawn    = "spawn"
+	classBuilding = "building"
+	classDeath    = "death"
+	classBlocked  = "blocked"
+	classRock     = "rock"
+	classWater    = "water"
+	classMud      = "mud"
+
+	// gidMask removes the flip flags from the tile GIDs
+	gidMask = 0x1fffffff
+)
+
+var (
+	ErrInvalidMap = errors.New("invalid map")
+
+	// Maps are the maps with Lines, the key is the number of Lines
+	Maps map[int]*Map
+
+	//go:embed *.tmx *.tsx
+	files embed.FS
+)
+
+// Map is a map authored with Tiled
+type Map struct {
+	Name string
+
+	// W and H are the size of the Map in pixels
+	W, H int
+
+	// TileW and TileH are the size of each tile
+	TileW, TileH int
+
+	// Lines are sorted by ID, which is the index
+	Lines []*Line
+
+	// Layers are the tile layers to render in order
+	Layers []*Layer
+
+	Tilesets []*Tileset
+}
+
+// Line is the lane of one Player
+type Line struct {
+	ID int
+
+	// X, Y, W and H are the area of the Line in pixels,
+	// X and Y are the home coordinates of the Line
+	X, Y, W, H int
+
+	// OffsetX and OffsetY are where the Zones start
+	OffsetX, OffsetY int
+
+	// Zones are the graph.Zone of each tile of the
+	// Line as Zones[y][x] from the Offset
+	Zones [][]graph.Zone
+
+	// Terrains are the graph.Terrain of each tile of
+	// the Line with the same format as the Zones
+	Terrains [][]graph.Terrain
+}
+
+// Layer is a tile layer of the Map
+type Layer struct {
+	Name string
+
+	// Tiles are the GIDs of each tile, row by row,
+	// and the 0 means there is no tile
+	Tiles []int
+}
+
+// Tileset is the set of tiles used by the Layers
+type Tileset struct {
+	Name     string
+	FirstGID int
+	Columns  int
+
+	// Image is the path of the image of the Tileset
+	// relative to the directory of the maps
+	Image string
+}
+
+// tmxMap is the format of the .tmx files
+type tmxMap struct {
+	Infinite   int `xml:"infinite,attr"`
+	Width      int `xml:"width,attr"`
+	Height     int `xml:"height,attr"`
+	TileWidth  int `xml:"tilewidth,attr"`
+	TileHeight int `xml:"tileheight,attr"`
+
+	Tilesets []struct {
+		FirstGID int    `xml:"firstgid,attr"`
+		Source   string `xml:"source,attr"`
+	} `xml:"tileset"`
+
+	Layers []struct {
+		Name string `xml:"name,attr"`
+		Data struct {
+			Encoding string `xml:"encoding,attr"`
+			Content  string `xml:",chardata"`
+		} `xml:"data"`
+	} `xml:"layer"`
+
+	ObjectGroups []struct {
+		Name    string      `xml:"name,attr"`
+		Objects []tmxObject `xml:"object"`
+	} `xml:"objectgroup"`
+}
+
+type tmxObject struct {
+	ID     int     `xml:"id,attr"`
+	Class  string  `xml:"class,attr"`
+	Type   string  `xml:"type,attr"`
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+
+	Properties []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"properties>property"`
+}
+
+// hasLines checks if the map has the LinesLayer
+func (tm tmxMap) hasLines() bool {
+	for _, og := range tm.ObjectGroups {
+		if og.Name == LinesLayer {
+			return true
+		}
+	}
+	return false
+}
+
+// class returns the class of the object, which
+// was named type before Tiled 1.9
+func (o tmxObject) class() string {
+	if o.Class != "" {
+		return o.Class
+	}
+	return o.Type
+}
+
+// tsxTileset is the format of the .tsx files
+type tsxTileset struct {
+	Name    string `xml:"name,attr"`
+	Columns int    `xml:"columns,attr"`
+	Image   struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+}
+
+// Parse parses the .tmx file p from fsys, the Tilesets are
+// read from fsys relative to the directory of p
+func Parse(fsys fs.FS, p string) (*Map, error) {
+	tm, err := decode(fsys, p)
+	if err != nil {
+		return nil, err
+	}
+	return parse(fsys, p, tm)
+}
+
+// decode decodes the .tmx file p from fsys
+func decode(fsys fs.FS, p string) (*tmxMap, error) {
+	b, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read map %q: %w", p, err)
+	}
+
+	var tm tmxMap
+	err = xml.Unmarshal(b, &tm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode map %q: %w", p, err)
+	}
+	return &tm, nil
+}
+
+func parse(fsys fs.FS, p string, tm *tmxMap) (*Map, error) {
+	var err error
+	if tm.Infinite != 0 {
+		return nil, fmt.Errorf("%w %q: the infinite maps are not supported", ErrInvalidMap, p)
+	}
+	if tm.TileWidth <= 0 || tm.TileWidth != tm.TileHeight {
+		return nil, fmt.Errorf("%w %q: the tiles have to be squares", ErrInvalidMap, p)
+	}
+
+	m := &Map{
+		Name:  strings.TrimSuffix(path.Base(p), path.Ext(p)),
+		W:     tm.Width * tm.TileWidth,
+		H:     tm.Height * tm.TileHeight,
+		TileW: tm.TileWidth,
+		TileH: tm.TileHeight,
+	}
+
+	for _, ts := range tm.Tilesets {
+		tsp := path.Join(path.Dir(p), ts.Source)
+		b, err := fs.ReadFile(fsys, tsp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tileset %q: %w", tsp, err)
+		}
+		var tsx tsxTileset
+		err = xml.Unmarshal(b, &tsx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tileset %q: %w", tsp, err)
+		}
+		m.Tilesets = append(m.Tilesets, &Tileset{
+			Name:     tsx.Name,
+			FirstGID: ts.FirstGID,
+			Columns:  tsx.Columns,
+			Image:    path.Join(path.Dir(ts.Source), tsx.Image.Source),
+		})
+	}
+	sort.Slice(m.Tilesets, func(i, j int) bool { return m.Tilesets[i].FirstGID < m.Tilesets[j].FirstGID })
+
+	for _, tl := range tm.Layers {
+		if tl.Data.Encoding != "csv" {
+			return nil, fmt.Errorf("%w %q: layer %q has to be encoded as csv", ErrInvalidMap, p, tl.Name)
+		}
+		l := &Layer{
+			Name:  tl.Name,
+			Tiles: make([]int, 0, tm.Width*tm.Height),
+		}
+		for _, v := range strings.FieldsFunc(tl.Data.Content, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' || r == ' ' }) {
+			gid, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%w %q: layer %q: %w", ErrInvalidMap, p, tl.Name, err)
+			}
+			l.Tiles = append(l.Tiles, int(gid&gidMask))
+		}
+		if len(l.Tiles) != tm.Width*tm.Height {
+			return nil, fmt.Errorf("%w %q: layer %q has %d tiles instead of %d", ErrInvalidMap, p, tl.Name, len(l.Tiles), tm.Width*tm.Height)
+		}
+		m.Layers = append(m.Layers, l)
+	}
+
+	for _, og := range tm.ObjectGroups {
+		if og.Name != LinesLayer {
+			continue
+		}
+		m.Lines, err = parseLines(og.Objects, tm.TileWidth)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidMap, p, err)
+		}
+	}
+
+	return m, nil
+}
+
+// parseLines builds the Lines from the objects of the LinesLayer
+// in which all the areas have to be aligned to the tiles of size s
+func parseLines(objects []tmxObject, s int) ([]*Line, error) {
+	lines := make(map[int]*Line)
+	zones := make(map[int]map[string][]image.Rectangle)
+	for _, o := range objects {
+		var (
+			lid int
+			ok  bool
+			err error
+		)
+		for _, p := range o.Properties {
+			if p.Name == LineProperty {
+				lid, err = strconv.Atoi(p.Value)
+				if err != nil {
+					return nil, fmt.Errorf("object %d: invalid %s: %w", o.ID, LineProperty, err)
+				}
+				ok = true
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("object %d: missing property %s", o.ID, LineProperty)
+		}
+
+		r := image.Rect(int(o.X), int(o.Y), int(o.X+o.Width), int(o.Y+o.Height))
+		if r.Empty() || r.Min.X%s != 0 || r.Min.Y%s != 0 || r.Dx()%s != 0 || r.Dy()%s != 0 {
+			return nil, fmt.Errorf("object %d: the area has to be aligned to the tiles", o.ID)
+		}
+
+		switch c := o.class(); c {
+		case classLine:
+			if _, ok := lines[lid]; ok {
+				return nil, fmt.Errorf("object %d: line %d is already defined", o.ID, lid)
+			}
+			lines[lid] = &Line{
+				ID: lid,
+				X:  r.Min.X, Y: r.Min.Y,
+				W: r.Dx(), H: r.Dy(),
+			}
+		case classSpawn, classBuilding, classDeath, classBlocked, classRock, classWater, classMud:
+			if _, ok := zones[lid]; !ok {
+				zones[lid] = make(map[string][]image.Rectangle)
+			}
+			zones[lid][c] = append(zones[lid][c], r)
+		default:
+			return nil, fmt.Errorf("object %d: unknown class %q", o.ID, c)
+		}
+	}
+
+	for lid := range zones {
+		if _, ok := lines[lid]; !ok {
+			return nil, fmt.Errorf("line %d: it has areas but it is not defined", lid)
+		}
+	}
+
+	res := make([]*Line, len(lines))
+	for lid, l := range lines {
+		if lid < 0 || lid >= len(lines) {
+			return nil, fmt.Errorf("line %d: the IDs have to go from 0 to %d", lid, len(lines)-1)
+		}
+		lz := zones[lid]
+		if len(lz[classSpawn]) == 0 || len(lz[classBuilding]) == 0 || len(lz[classDeath]) == 0 {
+			return nil, fmt.Errorf("line %d: it needs spawn, building and death areas", lid)
+		}
+
+		var area image.Rectangle
+		for _, c := range []string{classSpawn, classBuilding, classDeath} {
+			for _, r := range lz[c] {
+				area = area.Union(r)
+			}
+		}
+		l.OffsetX, l.OffsetY = area.Min.X, area.Min.Y
+
+		l.Zones = make([][]graph.Zone, area.Dy()/s)
+		l.Terrains = make([][]graph.Terrain, area.Dy()/s)
+		for y := range l.Zones {
+			l.Zones[y] = make([]graph.Zone, area.Dx()/s)
+			l.Terrains[y] = make([]graph.Terrain, area.Dx()/s)
+		}
+		for _, cz := range []struct {
+			class string
+			zone  graph.Zone
+		}{
+			{classSpawn, graph.SpawnZone},
+			{classBuilding, graph.BuildingZone},
+			{classDeath, graph.DeathZone},
+		} {
+			for _, r := range lz[cz.class] {
+				for y := r.Min.Y; y < r.Max.Y; y += s {
+					for x := r.Min.X; x < r.Max.X; x += s {
+						zy, zx := (y-area.Min.Y)/s, (x-area.Min.X)/s
+						if l.Zones[zy][zx] != graph.NoZone && l.Zones[zy][zx] != cz.zone {
+							return nil, fmt.Errorf("line %d: the %s area overlaps with another one", lid, cz.class)
+						}
+						l.Zones[zy][zx] = cz.zone
+					}
+				}
+			}
+		}
+		// The blocked ones have to be set the last so
+		// they are removed from the other areas
+		for _, r := range lz[classBlocked] {
+			r = r.Intersect(area)
+			for y := r.Min.Y; y < r.Max.Y; y += s {
+				for x := r.Min.X; x < r.Max.X; x += s {
+					l.Zones[(y-area.Min.Y)/s][(x-area.Min.X)/s] = graph.NoZone
+				}
+			}
+		}
+		for _, ct := range []struct {
+			class   string
+			terrain graph.Terrain
+		}{
+			{classRock, graph.Rock},
+			{classWater, graph.Water},
+			{classMud, graph.Mud},
+		} {
+			for _, r := range lz[ct.class] {
+				r = r.Intersect(area)
+				for y := r.Min.Y; y < r.Max.Y; y += s {
+					for x := r.Min.X; x < r.Max.X; x += s {
+						zy, zx := (y-area.Min.Y)/s, (x-area.Min.X)/s
+						if l.Terrains[zy][zx] != graph.Ground && l.Terrains[zy][zx] != ct.terrain {
+							return nil, fmt.Errorf("line %d: the %s area overlaps with another terrain", lid, ct.class)
+						}
+						l.Terrains[zy][zx] = ct.terrain
+					}
+				}
+			}
+		}
+
+		// It validates that the units can
+		// go from the spawn to the death area
+		if _, err := l.Graph(s); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lid, err)
+		}
+
+		res[lid] = l
+	}
+
+	return res, nil
+}
+
+// Graph returns the graph.Graph of the Line l with the scale s
+func (l *Line) Graph(s int) (*graph.Graph, error) {
+	return graph.NewWithZones(l.OffsetX, l.OffsetY, s, l.Zones, l.Terrains)
+}
+
+// Image renders all the Layers of the Map, the images of the Tilesets are
+// read with the open function that receives the Tileset.Image
+func (m *Map) Image(open func(p string) (image.Image, error)) (image.Image, error) {
+	imgs := make([]image.Image, len(m.Tilesets))
+	for i, ts := range m.Tilesets {
+		img, err := open(ts.Image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open tileset %q: %w", ts.Name, err)
+		}
+		imgs[i] = img
+	}
+
+	cols := m.W / m.TileW
+	dst := image.NewNRGBA(image.Rect(0, 0, m.W, m.H))
+	for _, l := range m.Layers {
+		for i, gid := range l.Tiles {
+			if gid == 0 {
+				continue
+			}
+			// The Tileset of the gid is the last one
+			// that starts before it
+			ti := sort.Search(len(m.Tilesets), func(i int) bool { return m.Tilesets[i].FirstGID > gid }) - 1
+			if ti < 0 {
+				return nil, fmt.Errorf("%w %q: tile %d has no tileset", ErrInvalidMap, m.Name, gid)
+			}
+			ts := m.Tilesets[ti]
+			id := gid - ts.FirstGID
+			sp := image.Pt((id%ts.Columns)*m.TileW, (id/ts.Columns)*m.TileH)
+			dp := image.Pt((i%cols)*m.TileW, (i/cols)*m.TileH)
+			draw.Draw(dst, image.Rectangle{Min: dp, Max: dp.Add(image.Pt(m.TileW, m.TileH))}, imgs[ti], imgs[ti].Bounds().Min.Add(sp), draw.Over)
+		}
+	}
+
+	return dst, nil
+}
+
+// Load loads all the .tmx files from fsys that have the LinesLayer,
+// the ones without it are not maps that can be played
+func Load(fsys fs.FS) (map[int]*Map, error) {
+	fns, err := fs.Glob(fsys, "*.tmx")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maps: %w", err)
+	}
+
+	maps := make(map[int]*Map)
+	for _, fn := range fns {
+		tm, err := decode(fsys, fn)
+		if err != nil {
+			return nil, err
+		}
+		if !tm.hasLines() {
+			continue
+		}
+		m, err := parse(fsys, fn, tm)
+		if err != nil {
+			return nil, err
+		}
+		if len(m.Lines) == 0 {
+			return nil, fmt.Errorf("%w %q: the %s layer has no lines", ErrInvalidMap, m.Name, LinesLayer)
+		}
+		if om, ok := maps[len(m.Lines)]; ok {
+			return nil, fmt.Errorf("%w %q: there is already the map %q with %d lines", ErrInvalidMap, m.Name, om.Name, len(m.Lines))
+		}
+		maps[len(m.Lines)] = m
+	}
+
+	return maps, nil
+}
+
+func init() {
+	var err error
+	Maps, err = Load(files)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,232 @@
+package tiled_test
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/tiled"
+	"github.com/xescugc/maze-wars/utils/graph"
+)
+
+const (
+	testTileset = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="test" tilewidth="16" tileheight="16" tilecount="2" columns="2">
+ <image source="images/test.png" width="32" height="16"/>
+</tileset>`
+
+	testObjects = `
+  <object id="1" type="line" x="0" y="0" width="64" height="96"><properties><property name="line" value="0"/></properties></object>
+  <object id="2" class="spawn" x="0" y="0" width="64" height="16"><properties><property name="line" value="0"/></properties></object>
+  <object id="3" class="building" x="0" y="16" width="64" height="64"><properties><property name="line" value="0"/></properties></object>
+  <object id="4" class="death" x="0" y="80" width="64" height="16"><properties><property name="line" value="0"/></properties></object>
+  <object id="5" class="blocked" x="0" y="48" width="48" height="16"><properties><property name="line" value="0"/></properties></object>`
+)
+
+func testMap(infinite int, data, objects string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<map width="4" height="6" tilewidth="16" tileheight="16" infinite="%d">
+ <tileset firstgid="1" source="test.tsx"/>
+ <layer id="1" name="ground" width="4" height="6">
+  <data encoding="csv">%s</data>
+ </layer>
+ <objectgroup id="2" name="lines">%s
+ </objectgroup>
+</map>`, infinite, data, objects)
+}
+
+func testFS(m string) fstest.MapFS {
+	return fstest.MapFS{
+		"test.tmx": &fstest.MapFile{Data: []byte(m)},
+		"test.tsx": &fstest.MapFile{Data: []byte(testTileset)},
+	}
+}
+
+var testData = `
+1,1,1,1,
+1,1,1,1,
+1,2,2,1,
+1,2,2,1,
+1,1,1,1,
+0,0,0,2147483650
+`
+
+func TestParse(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		m, err := tiled.Parse(testFS(testMap(0, testData, testObjects)), "test.tmx")
+		require.NoError(t, err)
+
+		assert.Equal(t, "test", m.Name)
+		assert.Equal(t, 64, m.W)
+		assert.Equal(t, 96, m.H)
+		assert.Equal(t, []*tiled.Tileset{{Name: "test", FirstGID: 1, Columns: 2, Image: "images/test.png"}}, m.Tilesets)
+		require.Len(t, m.Layers, 1)
+		assert.Equal(t, "ground", m.Layers[0].Name)
+		// The flip flags are removed from the last one
+		assert.Equal(t, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 1, 1, 1, 0, 0, 0, 2}, m.Layers[0].Tiles)
+
+		require.Len(t, m.Lines, 1)
+		l := m.Lines[0]
+		assert.Equal(t, 0, l.X)
+		assert.Equal(t, 0, l.Y)
+		assert.Equal(t, 64, l.W)
+		assert.Equal(t, 96, l.H)
+
+		var (
+			n = graph.NoZone
+			s = graph.SpawnZone
+			b = graph.BuildingZone
+			d = graph.DeathZone
+		)
+		assert.Equal(t, [][]graph.Zone{
+			{s, s, s, s},
+			{b, b, b, b},
+			{b, b, b, b},
+			{n, n, n, b},
+			{b, b, b, b},
+			{d, d, d, d},
+		}, l.Zones)
+
+		g, err := l.Graph(16)
+		require.NoError(t, err)
+		assert.Nil(t, g.GetNode(0, 48))
+		assert.NotNil(t, g.GetNode(48, 48))
+		assert.Len(t, g.Nodes, 4)
+	})
+	t.Run("Terrain", func(t *testing.T) {
+		objs := testObjects + `
+  <object id="6" class="rock" x="0" y="16" width="16" height="16"><properties><property name="line" value="0"/></properties></object>
+  <object id="7" class="water" x="16" y="16" width="32" height="16"><properties><property name="line" value="0"/></properties></object>
+  <object id="8" class="mud" x="0" y="64" width="64" height="48"><properties><property name="line" value="0"/></properties></object>`
+		m, err := tiled.Parse(testFS(testMap(0, testData, objs)), "test.tmx")
+		require.NoError(t, err)
+
+		var (
+			g = graph.Ground
+			r = graph.Rock
+			w = graph.Water
+			d = graph.Mud
+		)
+		l := m.Lines[0]
+		assert.Equal(t, [][]graph.Terrain{
+			{g, g, g, g},
+			{r, w, w, g},
+			{g, g, g, g},
+			{g, g, g, g},
+			{d, d, d, d},
+			{d, d, d, d},
+		}, l.Terrains)
+
+		gph, err := l.Graph(16)
+		require.NoError(t, err)
+		assert.Equal(t, graph.Water, gph.GetNode(16, 16).Terrain)
+		assert.False(t, gph.CanAddTower(16, 16, 16, 16))
+	})
+	t.Run("Image", func(t *testing.T) {
+		m, err := tiled.Parse(testFS(testMap(0, testData, testObjects)), "test.tmx")
+		require.NoError(t, err)
+
+		ts := image.NewNRGBA(image.Rect(0, 0, 32, 16))
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 32; x++ {
+				c := color.NRGBA{R: 255, A: 255}
+				if x >= 16 {
+					c = color.NRGBA{B: 255, A: 255}
+				}
+				ts.Set(x, y, c)
+			}
+		}
+
+		img, err := m.Image(func(p string) (image.Image, error) {
+			assert.Equal(t, "images/test.png", p)
+			return ts, nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, image.Rect(0, 0, 64, 96), img.Bounds())
+		assert.Equal(t, color.NRGBA{R: 255, A: 255}, color.NRGBAModel.Convert(img.At(0, 0)))
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, color.NRGBAModel.Convert(img.At(20, 40)))
+		assert.Equal(t, color.NRGBA{}, color.NRGBAModel.Convert(img.At(0, 90)))
+		assert.Equal(t, color.NRGBA{B: 255, A: 255}, color.NRGBAModel.Convert(img.At(60, 90)))
+	})
+
+	line := func(id int, class string, x, y, w, h int, lid string) string {
+		return fmt.Sprintf(`<object id="%d" class="%s" x="%d" y="%d" width="%d" height="%d"><properties><property name="line" value="%s"/></properties></object>`, id, class, x, y, w, h, lid)
+	}
+	tcs := map[string]string{
+		"Infinite":       testMap(1, testData, testObjects),
+		"MissingTiles":   testMap(0, "1,1,1", testObjects),
+		"InvalidTile":    testMap(0, "a"+testData[1:], testObjects),
+		"MissingLineID":  testMap(0, testData, testObjects+`<object id="6" class="blocked" x="0" y="0" width="16" height="16"/>`),
+		"NotAligned":     testMap(0, testData, testObjects+line(6, "blocked", 1, 0, 16, 16, "0")),
+		"UnknownClass":   testMap(0, testData, testObjects+line(6, "tower", 0, 0, 16, 16, "0")),
+		"DuplicatedLine": testMap(0, testData, testObjects+line(6, "line", 0, 0, 16, 16, "0")),
+		"UndefinedLine":  testMap(0, testData, testObjects+line(6, "spawn", 0, 0, 16, 16, "1")),
+		"InvalidLineID":  testMap(0, testData, line(1, "line", 0, 0, 16, 16, "1")),
+		"MissingZones":   testMap(0, testData, line(1, "line", 0, 0, 16, 16, "0")),
+		"Overlap":        testMap(0, testData, testObjects+line(6, "death", 0, 0, 16, 16, "0")),
+		"TerrainOverlap": testMap(0, testData, testObjects+line(6, "rock", 0, 0, 16, 16, "0")+line(7, "mud", 0, 0, 32, 16, "0")),
+		"ClosedLine":     testMap(0, testData, testObjects+line(6, "water", 48, 48, 16, 16, "0")),
+	}
+	for n, m := range tcs {
+		t.Run(n, func(t *testing.T) {
+			_, err := tiled.Parse(testFS(m), "test.tmx")
+			assert.ErrorIs(t, err, tiled.ErrInvalidMap)
+		})
+	}
+	t.Run("MissingTileset", func(t *testing.T) {
+		fs := testFS(testMap(0, testData, testObjects))
+		delete(fs, "test.tsx")
+		_, err := tiled.Parse(fs, "test.tmx")
+		assert.Error(t, err)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		fs := testFS(testMap(0, testData, testObjects))
+		// The ones without lines are skipped
+		fs["draft.tmx"] = &fstest.MapFile{Data: []byte(`<map width="1" height="1" tilewidth="16" tileheight="16" infinite="1"></map>`)}
+
+		maps, err := tiled.Load(fs)
+		require.NoError(t, err)
+		require.Len(t, maps, 1)
+		assert.Equal(t, "test", maps[1].Name)
+	})
+	t.Run("DuplicatedLines", func(t *testing.T) {
+		fs := testFS(testMap(0, testData, testObjects))
+		fs["other.tmx"] = fs["test.tmx"]
+
+		_, err := tiled.Load(fs)
+		assert.ErrorIs(t, err, tiled.ErrInvalidMap)
+	})
+}
+
+func TestMaps(t *testing.T) {
+	for i := 2; i <= 6; i++ {
+		m, ok := tiled.Maps[i]
+		require.True(t, ok, i)
+		require.Len(t, m.Lines, i)
+		for lid, l := range m.Lines {
+			assert.Equal(t, lid, l.ID)
+			assert.Equal(t, 688+lid*448, l.X)
+			assert.Equal(t, 688, l.Y)
+
+			// It has to be the same as the old hardcoded Graph
+			g, err := l.Graph(16)
+			require.NoError(t, err)
+			og, err := graph.New(l.X+16, l.Y+16, 16, 84, 16, 7, 74, 3)
+			require.NoError(t, err)
+			assert.Equal(t, og.DeathNode.X, g.DeathNode.X)
+			assert.Equal(t, og.DeathNode.Y, g.DeathNode.Y)
+			assert.Equal(t, og.SpawnZoneH, g.SpawnZoneH)
+			assert.Equal(t, og.BuildingZoneH, g.BuildingZoneH)
+			assert.Equal(t, og.DeathZoneH, g.DeathZoneH)
+			assert.Equal(t, len(og.Nodes), len(g.Nodes))
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package damage
+
+//go:generate enumer -type=Damage -transform=lower -json -text -transform=snake -output=damage_string.go
+
+// Damage is the type of damage a Tower does, the damage
+// the Unit receives depends on it and the armor.Armor
+type Damage int
+
+const (
+	Piercing Damage = iota
+	Siege
+	Magic
+)
+
+var (
+	names = map[Damage]string{
+		Piercing: "Piercing",
+		Siege:    "Siege",
+		Magic:    "Magic",
+	}
+)
+
+func Name(d Damage) string { return names[d] }
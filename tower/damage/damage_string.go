@@ -0,0 +1,112 @@
+// Code generated by "enumer -type=Damage -transform=lower -json -text -transform=snake -output=damage_string.go"; DO NOT EDIT.
+
+package damage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const _DamageName = "piercingsiegemagic"
+
+var _DamageIndex = [...]uint8{0, 8, 13, 18}
+
+const _DamageLowerName = "piercingsiegemagic"
+
+func (i Damage) String() string {
+	if i < 0 || i >= Damage(len(_DamageIndex)-1) {
+		return fmt.Sprintf("Damage(%d)", i)
+	}
+	return _DamageName[_DamageIndex[i]:_DamageIndex[i+1]]
+}
+
+// An "invalid array index" compiler error signifies that the constant values have changed.
+// Re-run the stringer command to generate them again.
+func _DamageNoOp() {
+	var x [1]struct{}
+	_ = x[Piercing-(0)]
+	_ = x[Siege-(1)]
+	_ = x[Magic-(2)]
+}
+
+var _DamageValues = []Damage{Piercing, Siege, Magic}
+
+var _DamageNameToValueMap = map[string]Damage{
+	_DamageName[0:8]:        Piercing,
+	_DamageLowerName[0:8]:   Piercing,
+	_DamageName[8:13]:       Siege,
+	_DamageLowerName[8:13]:  Siege,
+	_DamageName[13:18]:      Magic,
+	_DamageLowerName[13:18]: Magic,
+}
+
+var _DamageNames = []string{
+	_DamageName[0:8],
+	_DamageName[8:13],
+	_DamageName[13:18],
+}
+
+// DamageString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func DamageString(s string) (Damage, error) {
+	if val, ok := _DamageNameToValueMap[s]; ok {
+		return val, nil
+	}
+
+	if val, ok := _DamageNameToValueMap[strings.ToLower(s)]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Damage values", s)
+}
+
+// DamageValues returns all values of the enum
+func DamageValues() []Damage {
+	return _DamageValues
+}
+
+// DamageStrings returns a slice of all String values of the enum
+func DamageStrings() []string {
+	strs := make([]string, len(_DamageNames))
+	copy(strs, _DamageNames)
+	return strs
+}
+
+// IsADamage returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i Damage) IsADamage() bool {
+	for _, v := range _DamageValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements the json.Marshaler interface for Damage
+func (i Damage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Damage
+func (i *Damage) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Damage should be a string, got %s", data)
+	}
+
+	var err error
+	*i, err = DamageString(s)
+	return err
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for Damage
+func (i Damage) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Damage
+func (i *Damage) UnmarshalText(text []byte) error {
+	var err error
+	*i, err = DamageString(string(text))
+	return err
+}
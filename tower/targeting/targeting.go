@@ -0,0 +1,46 @@
+package targeting
+
+//go:generate enumer -type=Targeting -transform=lower -json -transform=snake -output=targeting_string.go
+
+// Targeting is the mode a Tower uses to choose
+// which of the Units on range it attacks
+type Targeting int
+
+const (
+	// MostExpensive attacks the Unit with the greatest cost
+	// giving priority to the ones that attack Towers
+	MostExpensive Targeting = iota
+	// First attacks the Unit closest to the end of the Line
+	First
+	// Last attacks the Unit farthest from the end of the Line
+	Last
+	// Strongest attacks the Unit with the most Health and Shield
+	Strongest
+	// Weakest attacks the Unit with the least Health and Shield
+	Weakest
+	// Closest attacks the Unit closest to the Tower
+	Closest
+	// AerialFirst attacks the Aerial Units before
+	// the rest and then the most expensive
+	AerialFirst
+)
+
+var (
+	names = map[Targeting]string{
+		MostExpensive: "Most expensive",
+		First:         "First",
+		Last:          "Last",
+		Strongest:     "Strongest",
+		Weakest:       "Weakest",
+		Closest:       "Closest",
+		AerialFirst:   "Aerial first",
+	}
+)
+
+func Name(t Targeting) string { return names[t] }
+
+// Next returns the Targeting after t, after
+// the last one it starts again from the first
+func (t Targeting) Next() Targeting {
+	return (t + 1) % Targeting(len(names))
+}
@@ -0,0 +1,116 @@
+// Code generated by "enumer -type=Targeting -transform=lower -json -transform=snake -output=targeting_string.go"; DO NOT EDIT.
+
+package targeting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const _TargetingName = "most_expensivefirstlaststrongestweakestclosestaerial_first"
+
+var _TargetingIndex = [...]uint8{0, 14, 19, 23, 32, 39, 46, 58}
+
+const _TargetingLowerName = "most_expensivefirstlaststrongestweakestclosestaerial_first"
+
+func (i Targeting) String() string {
+	if i < 0 || i >= Targeting(len(_TargetingIndex)-1) {
+		return fmt.Sprintf("Targeting(%d)", i)
+	}
+	return _TargetingName[_TargetingIndex[i]:_TargetingIndex[i+1]]
+}
+
+// An "invalid array index" compiler error signifies that the constant values have changed.
+// Re-run the stringer command to generate them again.
+func _TargetingNoOp() {
+	var x [1]struct{}
+	_ = x[MostExpensive-(0)]
+	_ = x[First-(1)]
+	_ = x[Last-(2)]
+	_ = x[Strongest-(3)]
+	_ = x[Weakest-(4)]
+	_ = x[Closest-(5)]
+	_ = x[AerialFirst-(6)]
+}
+
+var _TargetingValues = []Targeting{MostExpensive, First, Last, Strongest, Weakest, Closest, AerialFirst}
+
+var _TargetingNameToValueMap = map[string]Targeting{
+	_TargetingName[0:14]:       MostExpensive,
+	_TargetingLowerName[0:14]:  MostExpensive,
+	_TargetingName[14:19]:      First,
+	_TargetingLowerName[14:19]: First,
+	_TargetingName[19:23]:      Last,
+	_TargetingLowerName[19:23]: Last,
+	_TargetingName[23:32]:      Strongest,
+	_TargetingLowerName[23:32]: Strongest,
+	_TargetingName[32:39]:      Weakest,
+	_TargetingLowerName[32:39]: Weakest,
+	_TargetingName[39:46]:      Closest,
+	_TargetingLowerName[39:46]: Closest,
+	_TargetingName[46:58]:      AerialFirst,
+	_TargetingLowerName[46:58]: AerialFirst,
+}
+
+var _TargetingNames = []string{
+	_TargetingName[0:14],
+	_TargetingName[14:19],
+	_TargetingName[19:23],
+	_TargetingName[23:32],
+	_TargetingName[32:39],
+	_TargetingName[39:46],
+	_TargetingName[46:58],
+}
+
+// TargetingString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func TargetingString(s string) (Targeting, error) {
+	if val, ok := _TargetingNameToValueMap[s]; ok {
+		return val, nil
+	}
+
+	if val, ok := _TargetingNameToValueMap[strings.ToLower(s)]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Targeting values", s)
+}
+
+// TargetingValues returns all values of the enum
+func TargetingValues() []Targeting {
+	return _TargetingValues
+}
+
+// TargetingStrings returns a slice of all String values of the enum
+func TargetingStrings() []string {
+	strs := make([]string, len(_TargetingNames))
+	copy(strs, _TargetingNames)
+	return strs
+}
+
+// IsATargeting returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i Targeting) IsATargeting() bool {
+	for _, v := range _TargetingValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements the json.Marshaler interface for Targeting
+func (i Targeting) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Targeting
+func (i *Targeting) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Targeting should be a string, got %s", data)
+	}
+
+	var err error
+	*i, err = TargetingString(s)
+	return err
+}
@@ -2,40 +2,101 @@ package tower
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"log"
+	"sort"
 
 	"github.com/xescugc/maze-wars/assets"
+	"github.com/xescugc/maze-wars/tower/damage"
+	"github.com/xescugc/maze-wars/unit/buff"
 	"github.com/xescugc/maze-wars/unit/environment"
 	"github.com/xescugc/maze-wars/utils"
 )
 
+const (
+	Arrow      = "arrow"
+	Cannonball = "cannonball"
+
+	// sprites is the number of Towers on the sprite sheets
+	sprites = 12
+
+	// tileSize is the size in pixels of one node of the Graph
+	tileSize = 16
+	// maxFootprint is the maximum number of nodes a Tower
+	// can occupy on each side
+	maxFootprint = 3
+)
+
+var (
+	ErrInvalidTower = errors.New("invalid tower")
+)
+
 type Tower struct {
-	Type Type
+	// Type is the key of the Tower on the Towers
+	Type string `json:"-"`
 
-	Damage float64
-	Gold   int
-	Health float64
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Sprite is the position of the Tower on the sprite sheets
+	Sprite int `json:"sprite"`
+
+	// Keybind is the key to place the Tower, only
+	// the ones that are not an update have it
+	Keybind string `json:"keybind"`
+
+	Damage float64 `json:"damage"`
+	Gold   int     `json:"gold"`
+	Health float64 `json:"health"`
 	// Range is in a reduced version of 16 pixels, so Range == 1 == 16px
-	Range       float64
-	AttackSpeed float64
+	Range       float64 `json:"range"`
+	AttackSpeed float64 `json:"attack_speed"`
 	// AoE is the same as the Range, AoE == 1 == 16px
-	AoE       int
-	AoEDamage float64
+	AoE       int     `json:"aoe"`
+	AoEDamage float64 `json:"aoe_damage"`
+
+	// DamageType is the type of the Damage and AoEDamage,
+	// how much it hurts depends on the Unit armor
+	DamageType damage.Damage `json:"damage_type"`
+
+	// Effect is the Buff the projectiles apply
+	// to the Unit they hit, if any
+	Effect *buff.Effect `json:"effect"`
+
+	// Footprint is the number of nodes the Tower occupies,
+	// if not defined it's 2x2
+	Footprint Footprint `json:"footprint"`
+
+	// Projectile is the kind of projectile it
+	// shoots, Arrow or Cannonball
+	Projectile string `json:"projectile"`
 
 	Targets []environment.Environment `json:"targets"`
 	targets map[environment.Environment]struct{}
 
-	// Idle is 32x32 used on the map
-	Idle image.Image
+	// Idle is 32x32 used on the map, scaled to the Footprint
+	Idle image.Image `json:"-"`
 	// Faceset is 38x38 used on the buttons
-	Faceset image.Image
+	Faceset image.Image `json:"-"`
 	// Profile is 106x106 used when selected
-	Profile image.Image
+	Profile image.Image `json:"-"`
 
 	// The Update Cost is the Tower.Gold
-	Updates []Type
+	Updates []string `json:"updates"`
+}
+
+// Footprint is the size of a Tower on nodes of the Graph
+type Footprint struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Size returns the W and H in pixels of the Tower
+func (t *Tower) Size() (int, int) {
+	return t.Footprint.W * tileSize, t.Footprint.H * tileSize
 }
 
 func (t *Tower) FacesetKey() string { return fmt.Sprintf("t-f-%s", t.Type) }
@@ -47,8 +108,7 @@ func (t *Tower) CanTarget(env environment.Environment) bool {
 	return ok
 }
 func (t *Tower) ShootsArrows() bool {
-	_, ok := cannonball[t.Type]
-	return !ok
+	return t.Projectile != Cannonball
 }
 
 // initTargets will map the Targets to a map for easy access
@@ -59,230 +119,187 @@ func (t *Tower) initTargets() {
 	}
 }
 
-func (t *Tower) Name() string {
-	n, ok := names[t.Type]
-	if !ok {
-		return t.Type.String()
+var (
+	// Towers are all the Towers, the key is the Tower.Type
+	Towers map[string]*Tower
+
+	// FirstTowers are the Towers that can be placed,
+	// the rest are only reachable as Updates
+	FirstTowers []*Tower
+)
+
+// Load loads the Towers from the JSON b and validates them
+func Load(b []byte) (map[string]*Tower, error) {
+	var towers map[string]*Tower
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&towers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode towers: %w", err)
 	}
-	return n
-}
 
-func (t *Tower) Description() string {
-	n, ok := descriptions[t.Type]
-	if !ok {
-		return t.Type.String()
+	for tt, t := range towers {
+		t.Type = tt
+		if t.Footprint == (Footprint{}) {
+			t.Footprint = Footprint{W: 2, H: 2}
+		}
+		t.initTargets()
+	}
+
+	err = Validate(towers)
+	if err != nil {
+		return nil, err
 	}
-	return n
+
+	return towers, nil
 }
 
-var (
-	names = map[Type]string{
-		Range1:       "Range - T1",
-		Range2:       "Range - T2",
-		RangeSingle1: "Range Singe - T3",
-		RangeSingle2: "Range Singe - T4",
-		RangeAoE1:    "Range AoE - T3",
-		RangeAoE2:    "Range AoE - T4",
-
-		Melee1:       "Melee - T1",
-		Melee2:       "Melee - T2",
-		MeleeSingle1: "Melee Single - T3",
-		MeleeSingle2: "Melee Single - T4",
-		MeleeAoE1:    "Melee AoE - T3",
-		MeleeAoE2:    "Melee AoE - T4",
+// Validate checks that all the Towers have the required fields
+// and that the Updates are of existing Towers without cycles
+func Validate(towers map[string]*Tower) error {
+	keybinds := make(map[string]string)
+	for tt, t := range towers {
+		switch {
+		case t.Name == "":
+			return fmt.Errorf("%w %q: name is required", ErrInvalidTower, tt)
+		case t.Gold <= 0:
+			return fmt.Errorf("%w %q: gold has to be positive", ErrInvalidTower, tt)
+		case t.Health <= 0:
+			return fmt.Errorf("%w %q: health has to be positive", ErrInvalidTower, tt)
+		case t.Range <= 0:
+			return fmt.Errorf("%w %q: range has to be positive", ErrInvalidTower, tt)
+		case t.AttackSpeed <= 0:
+			return fmt.Errorf("%w %q: attack_speed has to be positive", ErrInvalidTower, tt)
+		case t.Damage < 0 || t.AoE < 0 || t.AoEDamage < 0:
+			return fmt.Errorf("%w %q: damage, aoe and aoe_damage can not be negative", ErrInvalidTower, tt)
+		case !t.DamageType.IsADamage():
+			return fmt.Errorf("%w %q: unknown damage_type %q", ErrInvalidTower, tt, t.DamageType)
+		case t.Projectile != Arrow && t.Projectile != Cannonball:
+			return fmt.Errorf("%w %q: projectile has to be %q or %q", ErrInvalidTower, tt, Arrow, Cannonball)
+		case len(t.Targets) == 0:
+			return fmt.Errorf("%w %q: targets are required", ErrInvalidTower, tt)
+		case t.Sprite < 0 || t.Sprite >= sprites:
+			return fmt.Errorf("%w %q: sprite has to be between 0 and %d", ErrInvalidTower, tt, sprites-1)
+		case t.Footprint.W < 1 || t.Footprint.W > maxFootprint || t.Footprint.H < 1 || t.Footprint.H > maxFootprint:
+			return fmt.Errorf("%w %q: footprint has to be between 1 and %d nodes", ErrInvalidTower, tt, maxFootprint)
+		}
+		if t.Effect != nil {
+			err := validateEffect(*t.Effect)
+			if err != nil {
+				return fmt.Errorf("%w %q: %w", ErrInvalidTower, tt, err)
+			}
+		}
+		for _, tg := range t.Targets {
+			if !tg.IsAEnvironment() {
+				return fmt.Errorf("%w %q: unknown target %q", ErrInvalidTower, tt, tg)
+			}
+		}
+		for _, u := range t.Updates {
+			ut, ok := towers[u]
+			if !ok {
+				return fmt.Errorf("%w %q: unknown update %q", ErrInvalidTower, tt, u)
+			}
+			// The Update replaces the Tower on the same position
+			// so it has to occupy the same nodes
+			if ut.Footprint != t.Footprint {
+				return fmt.Errorf("%w %q: update %q has a different footprint", ErrInvalidTower, tt, u)
+			}
+		}
+		if t.Keybind != "" {
+			if ott, ok := keybinds[t.Keybind]; ok {
+				return fmt.Errorf("%w %q: keybind %q already used by %q", ErrInvalidTower, tt, t.Keybind, ott)
+			}
+			keybinds[t.Keybind] = tt
+		}
 	}
 
-	descriptions = map[Type]string{
-		Range1:       `Basic range tower`,
-		Range2:       `Updated basic range tower`,
-		RangeSingle1: `Powerful single target range tower`,
-		RangeSingle2: `More powerful single target range tower`,
-		RangeAoE1:    `Ground AoE tower, slow but powerful`,
-		RangeAoE2:    `Updated ground AoE tower, slow but powerful`,
-
-		Melee1:       `Basic melee tower`,
-		Melee2:       `Updated basic melee tower`,
-		MeleeSingle1: `Improved single target melee tower`,
-		MeleeSingle2: `Powerful single target melee tower`,
-		MeleeAoE1:    `Flying AoE tower`,
-		MeleeAoE2:    `Updated Flying AoE tower`,
+	for _, t := range firstTowers(towers) {
+		if t.Keybind == "" {
+			return fmt.Errorf("%w %q: keybind is required as it's not an update", ErrInvalidTower, t.Type)
+		}
 	}
 
-	cannonball = map[Type]struct{}{
-		RangeAoE1: {},
-		RangeAoE2: {},
+	// All the Towers have to be reachable from the
+	// first ones, if not there is a cycle on the Updates
+	visited := make(map[string]struct{})
+	var visit func(tt string, path map[string]struct{}) error
+	visit = func(tt string, path map[string]struct{}) error {
+		if _, ok := path[tt]; ok {
+			return fmt.Errorf("%w %q: the updates have a cycle", ErrInvalidTower, tt)
+		}
+		path[tt] = struct{}{}
+		defer delete(path, tt)
+		visited[tt] = struct{}{}
+		for _, u := range towers[tt].Updates {
+			err := visit(u, path)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, t := range firstTowers(towers) {
+		err := visit(t.Type, make(map[string]struct{}))
+		if err != nil {
+			return err
+		}
+	}
+	for tt := range towers {
+		if _, ok := visited[tt]; !ok {
+			return fmt.Errorf("%w %q: the updates have a cycle", ErrInvalidTower, tt)
+		}
 	}
 
-	Towers = map[string]*Tower{
-		Range1.String(): &Tower{
-			Gold:        7,
-			Damage:      2,
-			Health:      25,
-			AttackSpeed: 0.6,
-			Range:       4,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-				environment.Aerial,
-			},
-			Updates: []Type{
-				Range2,
-			},
-		},
-		Range2.String(): &Tower{
-			Gold:        30,
-			Damage:      7,
-			Health:      50,
-			AttackSpeed: 0.6,
-			Range:       5,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-				environment.Aerial,
-			},
-			Updates: []Type{
-				RangeSingle1,
-				RangeAoE1,
-			},
-		},
-		RangeSingle1.String(): &Tower{
-			Gold:        250,
-			Damage:      40,
-			Health:      75,
-			AttackSpeed: 0.5,
-			Range:       6,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-				environment.Aerial,
-			},
-			Updates: []Type{
-				RangeSingle2,
-			},
-		},
-		RangeSingle2.String(): &Tower{
-			Gold:        500,
-			Damage:      80,
-			Health:      125,
-			AttackSpeed: 0.5,
-			Range:       7,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-				environment.Aerial,
-			},
-		},
-		RangeAoE1.String(): &Tower{
-			Gold:        250,
-			Damage:      150,
-			Health:      75,
-			AttackSpeed: 2,
-			Range:       5,
-			AoE:         3,
-			AoEDamage:   45,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-			},
-			Updates: []Type{
-				RangeAoE2,
-			},
-		},
-		RangeAoE2.String(): &Tower{
-			Gold:        500,
-			Damage:      180,
-			Health:      125,
-			AttackSpeed: 2,
-			Range:       5,
-			AoE:         3,
-			AoEDamage:   54,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-			},
-		},
-		Melee1.String(): &Tower{
-			Gold:        7,
-			Damage:      2,
-			Health:      25,
-			AttackSpeed: 0.3,
-			Range:       1,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-			},
-			Updates: []Type{
-				Melee2,
-			},
-		},
-		Melee2.String(): &Tower{
-			Gold:        30,
-			Damage:      8,
-			Health:      50,
-			AttackSpeed: 0.3,
-			Range:       1,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-			},
-			Updates: []Type{
-				MeleeSingle1,
-				MeleeAoE1,
-			},
-		},
-		MeleeSingle1.String(): &Tower{
-			Gold:        250,
-			Damage:      50,
-			Health:      75,
-			AttackSpeed: 0.3,
-			Range:       1,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-			},
-			Updates: []Type{
-				MeleeSingle2,
-			},
-		},
-		MeleeSingle2.String(): &Tower{
-			Gold:        500,
-			Damage:      100,
-			Health:      125,
-			AttackSpeed: 0.3,
-			Range:       1,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-			},
-		},
-		MeleeAoE1.String(): &Tower{
-			Gold:        250,
-			Damage:      100,
-			Health:      75,
-			AttackSpeed: 2,
-			Range:       1,
-			AoE:         3,
-			AoEDamage:   30,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-				environment.Aerial,
-			},
-			Updates: []Type{
-				MeleeAoE2,
-			},
-		},
-		MeleeAoE2.String(): &Tower{
-			Gold:        500,
-			Damage:      130,
-			Health:      125,
-			AttackSpeed: 2,
-			Range:       1,
-			AoE:         3,
-			AoEDamage:   39,
-			Targets: []environment.Environment{
-				environment.Terrestrial,
-				environment.Aerial,
-			},
-		},
+	return nil
+}
+
+// validateEffect checks that the Effect e can be applied
+func validateEffect(e buff.Effect) error {
+	switch {
+	case !e.Buff.IsEffect() || e.Buff.IsHeroEffect():
+		return fmt.Errorf("effect buff %q can not be applied", e.Buff)
+	case e.Duration <= 0:
+		return errors.New("effect duration has to be positive")
+	case e.Buff == buff.Slowed && (e.Value <= 0 || e.Value >= 1):
+		return errors.New("effect value of slowed has to be between 0 and 1")
+	case e.Buff != buff.Stunned && e.Value <= 0:
+		return errors.New("effect value has to be positive")
 	}
+	return nil
+}
 
-	FirstTowers = []*Tower{
-		Towers[Range1.String()],
-		Towers[Melee1.String()],
+// firstTowers returns the Towers that are not an
+// update of any other sorted by the Sprite
+func firstTowers(towers map[string]*Tower) []*Tower {
+	updates := make(map[string]struct{})
+	for _, t := range towers {
+		for _, u := range t.Updates {
+			updates[u] = struct{}{}
+		}
 	}
-)
+	res := make([]*Tower, 0)
+	for tt, t := range towers {
+		if _, ok := updates[tt]; !ok {
+			res = append(res, t)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Sprite == res[j].Sprite {
+			return res[i].Type < res[j].Type
+		}
+		return res[i].Sprite < res[j].Sprite
+	})
+	return res
+}
 
 func init() {
+	var err error
+	Towers, err = Load(assets.Towers_json)
+	if err != nil {
+		log.Fatal(err)
+	}
+	FirstTowers = firstTowers(Towers)
+
 	img, _, err := image.Decode(bytes.NewReader(assets.Towers_png))
 	if err != nil {
 		log.Fatal(err)
@@ -299,12 +316,12 @@ func init() {
 	wh := 32
 	fwh := 38
 	pwh := 106
-	for i, ty := range TypeValues() {
-		y := i / 6
+	for _, t := range Towers {
+		y := t.Sprite / 6
 		if y != 0 {
 			y += 1
 		}
-		x := i % 6
+		x := t.Sprite % 6
 		if x == 4 {
 			y += 1
 			x = 2
@@ -312,10 +329,8 @@ func init() {
 			y += 1
 			x = 3
 		}
-		Towers[ty.String()].Idle = img.(utils.SubImager).SubImage(image.Rect(x*wh, y*wh, x*wh+wh, y*wh+wh))
-		Towers[ty.String()].Faceset = face.(utils.SubImager).SubImage(image.Rect(x*fwh, y*fwh, x*fwh+fwh, y*fwh+fwh))
-		Towers[ty.String()].Profile = profiles.(utils.SubImager).SubImage(image.Rect(x*pwh, y*pwh, x*pwh+pwh, y*pwh+pwh))
-		Towers[ty.String()].Type = ty
-		Towers[ty.String()].initTargets()
+		t.Idle = img.(utils.SubImager).SubImage(image.Rect(x*wh, y*wh, x*wh+wh, y*wh+wh))
+		t.Faceset = face.(utils.SubImager).SubImage(image.Rect(x*fwh, y*fwh, x*fwh+fwh, y*fwh+fwh))
+		t.Profile = profiles.(utils.SubImager).SubImage(image.Rect(x*pwh, y*pwh, x*pwh+pwh, y*pwh+pwh))
 	}
 }
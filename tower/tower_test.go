@@ -1,15 +1,18 @@
 package tower_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/xescugc/maze-wars/tower"
+	"github.com/xescugc/maze-wars/tower/damage"
 	"github.com/xescugc/maze-wars/unit/environment"
 )
 
 func TestTowerKeyFunctions(t *testing.T) {
-	tw := tower.Towers[tower.Range1.String()]
+	tw := tower.Towers["range1"]
 	t.Run("FacesetKey", func(t *testing.T) {
 		assert.Equal(t, "t-f-range1", tw.FacesetKey())
 	})
@@ -22,33 +25,127 @@ func TestTowerKeyFunctions(t *testing.T) {
 }
 
 func TestTowerCanTarget(t *testing.T) {
-	tw := tower.Towers[tower.Melee1.String()]
+	tw := tower.Towers["melee1"]
 	assert.True(t, tw.CanTarget(environment.Terrestrial))
 	assert.False(t, tw.CanTarget(environment.Aerial))
 }
 
 func TestTowerShootsArrows(t *testing.T) {
-	tw := tower.Towers[tower.RangeAoE1.String()]
+	tw := tower.Towers["rangeaoe1"]
 	assert.False(t, tw.ShootsArrows())
 
-	tw = tower.Towers[tower.Melee1.String()]
+	tw = tower.Towers["melee1"]
 	assert.True(t, tw.ShootsArrows())
 }
 
 func TestTowerName(t *testing.T) {
-	pt := tower.Towers[tower.Range1.String()]
-	tw := *pt
-	assert.Equal(t, "Range - T1", tw.Name())
+	tw := tower.Towers["range1"]
+	assert.Equal(t, "Range - T1", tw.Name)
+	assert.Equal(t, "Basic range tower", tw.Description)
+}
 
-	tw.Type = tower.Type(100)
-	assert.Equal(t, "Type(100)", tw.Name())
+func TestFirstTowers(t *testing.T) {
+	require.Len(t, tower.FirstTowers, 2)
+	assert.Equal(t, "range1", tower.FirstTowers[0].Type)
+	assert.Equal(t, "melee1", tower.FirstTowers[1].Type)
 }
 
-func TestTowerDescription(t *testing.T) {
-	pt := tower.Towers[tower.Range1.String()]
-	tw := *pt
-	assert.Equal(t, "Basic range tower", tw.Description())
+func TestLoad(t *testing.T) {
+	valid := func() map[string]map[string]interface{} {
+		return map[string]map[string]interface{}{
+			"first": {
+				"name": "First", "keybind": "Q", "gold": 1, "health": 1, "range": 1, "attack_speed": 1,
+				"projectile": "arrow", "targets": []string{"terrestrial"}, "updates": []string{"second"},
+			},
+			"second": {
+				"name": "Second", "gold": 1, "health": 1, "range": 1, "attack_speed": 1, "sprite": 1,
+				"projectile": "cannonball", "targets": []string{"terrestrial", "aerial"},
+			},
+		}
+	}
+	load := func(towers interface{}) (map[string]*tower.Tower, error) {
+		b, err := json.Marshal(towers)
+		require.NoError(t, err)
+		return tower.Load(b)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		towers, err := load(valid())
+		require.NoError(t, err)
+		require.Len(t, towers, 2)
+		assert.Equal(t, "first", towers["first"].Type)
+		assert.Equal(t, []string{"second"}, towers["first"].Updates)
+		assert.True(t, towers["second"].CanTarget(environment.Aerial))
+		assert.False(t, towers["second"].ShootsArrows())
+		assert.Equal(t, tower.Footprint{W: 2, H: 2}, towers["first"].Footprint)
+	})
+
+	t.Run("Footprint", func(t *testing.T) {
+		towers := valid()
+		towers["first"]["footprint"] = map[string]int{"w": 1, "h": 3}
+		towers["second"]["footprint"] = map[string]int{"w": 1, "h": 3}
+		tws, err := load(towers)
+		require.NoError(t, err)
+		w, h := tws["first"].Size()
+		assert.Equal(t, 16, w)
+		assert.Equal(t, 48, h)
+	})
+
+	t.Run("DamageType", func(t *testing.T) {
+		towers := valid()
+		towers["second"]["damage_type"] = "magic"
+		tws, err := load(towers)
+		require.NoError(t, err)
+		assert.Equal(t, damage.Piercing, tws["first"].DamageType)
+		assert.Equal(t, damage.Magic, tws["second"].DamageType)
 
-	tw.Type = tower.Type(100)
-	assert.Equal(t, "Type(100)", tw.Description())
+		towers["second"]["damage_type"] = "fire"
+		_, err = load(towers)
+		assert.Error(t, err)
+	})
+
+	tcs := map[string]func(towers map[string]map[string]interface{}){
+		"MissingName":       func(towers map[string]map[string]interface{}) { delete(towers["first"], "name") },
+		"InvalidGold":       func(towers map[string]map[string]interface{}) { towers["first"]["gold"] = 0 },
+		"InvalidProjectile": func(towers map[string]map[string]interface{}) { towers["first"]["projectile"] = "laser" },
+		"MissingTargets":    func(towers map[string]map[string]interface{}) { delete(towers["first"], "targets") },
+		"UnknownUpdate":     func(towers map[string]map[string]interface{}) { towers["first"]["updates"] = []string{"third"} },
+		"InvalidSprite":     func(towers map[string]map[string]interface{}) { towers["first"]["sprite"] = 100 },
+		"MissingKeybind":    func(towers map[string]map[string]interface{}) { delete(towers["first"], "keybind") },
+		"InvalidFootprint": func(towers map[string]map[string]interface{}) {
+			towers["first"]["footprint"] = map[string]int{"w": 4, "h": 1}
+		},
+		"UpdateFootprint": func(towers map[string]map[string]interface{}) {
+			towers["second"]["footprint"] = map[string]int{"w": 3, "h": 3}
+		},
+		"Cycle": func(towers map[string]map[string]interface{}) { towers["second"]["updates"] = []string{"first"} },
+		"DuplicatedKeybind": func(towers map[string]map[string]interface{}) {
+			towers["third"] = map[string]interface{}{
+				"name": "Third", "keybind": "Q", "gold": 1, "health": 1, "range": 1, "attack_speed": 1,
+				"projectile": "arrow", "targets": []string{"terrestrial"},
+			}
+		},
+	}
+	for n, fn := range tcs {
+		t.Run(n, func(t *testing.T) {
+			towers := valid()
+			fn(towers)
+			_, err := load(towers)
+			assert.ErrorIs(t, err, tower.ErrInvalidTower)
+		})
+	}
+
+	t.Run("UnknownField", func(t *testing.T) {
+		towers := valid()
+		towers["first"]["unknown"] = 1
+		_, err := load(towers)
+		assert.Error(t, err)
+	})
+
+	t.Run("UnknownTarget", func(t *testing.T) {
+		towers := valid()
+		towers["first"]["targets"] = []string{"underground"}
+		_, err := load(towers)
+		assert.Error(t, err)
+	})
 }
@@ -27,6 +27,13 @@ const (
 	Hybrid
 	Attack
 	// End of default abilities
+
+	// Auras, they affect all the friendly
+	// Units around the Unit that has it
+	HealingAura
+	ShieldAura
+	SpeedAura
+	Commander
 )
 
 var (
@@ -36,27 +43,36 @@ var (
 		Efficiency: "Makes the unit cost efficient compared to the rest",
 		Tank:       "Provides extra Health",
 		Fast:       "Provides extra speed to the unit",
-		Split: `Once it dies it'll split in 2 small
+		Split: `Once it dies it'll split in 2 small and faster
 units (live of which is halved).`,
-		Burrow: `Burrows once it reaches 1/2 life,
+		Burrow: `Burrows once it reaches part of its life,
 unburrows once the next wave reaches it.
 
-This means that it'll be undergrond for 15s and
+This means that it'll be undergrond for a while and
 then the next unit that steps on it it'll unburrow,
-or after 45s it'll unburrow itself.`,
-		Resurrection: `Returns to life with 25% after
-1.5 seconds when killed. Can only trigger once.
-Next rank is 50% and next 75%.`,
+or after the max time it'll unburrow itself.`,
+		Resurrection: `Returns to life after a short delay
+when killed. Can only trigger once.
+Higher ranks return with more life.`,
 		Fly: `Allows unit to go over the towers
 and can only be targeted by  towers that
 can attack flying units.`,
 		Camouflage: `This unit won't draw attention from towers
 and will always be attacked as a last priority`,
 		Hybrid: `Gains life, shields, and movement speed base on
-percent difference between attacker and defender`,
+percent difference between attacker and defender.
+Higher ranks increase the bonus.`,
 		Attack: `Instead of traversing the maze to reach the end
 this units will attack the towers in order to destroy the
 maze.`,
+		HealingAura: `Heals over time the friendly units
+around it, including itself.`,
+		ShieldAura: `Regenerates over time the shields of
+the friendly units around it, including itself.`,
+		SpeedAura: `Increases the movement speed of the
+friendly units around it, including itself.`,
+		Commander: `Camouflages the friendly units around it,
+including itself, so they are attacked as a last priority`,
 	}
 	names = map[Ability]string{
 		Efficiency:   "Efficiency",
@@ -69,12 +85,32 @@ maze.`,
 		Camouflage:   "Camouflage",
 		Hybrid:       "Hybrid",
 		Attack:       "Attack",
+		HealingAura:  "Healing Aura",
+		ShieldAura:   "Shield Aura",
+		SpeedAura:    "Speed Aura",
+		Commander:    "Commander",
+	}
+
+	// auraImages are the default abilities which image
+	// is used for the auras as they are not on the asset
+	auraImages = map[Ability]Ability{
+		HealingAura: Tank,
+		ShieldAura:  Hybrid,
+		SpeedAura:   Fast,
+		Commander:   Camouflage,
 	}
 )
 
 func Key(a string) string          { return fmt.Sprintf("u-ab-%s", a) }
 func Name(a Ability) string        { return names[a] }
 func Description(a Ability) string { return descriptions[a] }
+
+// IsAura checks if the Ability a affects the Units around
+func (a Ability) IsAura() bool {
+	_, ok := auraImages[a]
+	return ok
+}
+
 func init() {
 	Images = make(map[string]image.Image)
 
@@ -89,4 +125,7 @@ func init() {
 		x := i % 5
 		Images[a.String()] = ubai.(utils.SubImager).SubImage(image.Rect(x*w, y*w, x*w+w, y*w+w))
 	}
+	for a, da := range auraImages {
+		Images[a.String()] = Images[da.String()]
+	}
 }
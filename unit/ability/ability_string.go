@@ -8,11 +8,11 @@ import (
 	"strings"
 )
 
-const _AbilityName = "efficiencytankfastsplitburrowresurrectionflycamouflagehybridattack"
+const _AbilityName = "efficiencytankfastsplitburrowresurrectionflycamouflagehybridattackhealing_aurashield_auraspeed_auracommander"
 
-var _AbilityIndex = [...]uint8{0, 10, 14, 18, 23, 29, 41, 44, 54, 60, 66}
+var _AbilityIndex = [...]uint8{0, 10, 14, 18, 23, 29, 41, 44, 54, 60, 66, 78, 89, 99, 108}
 
-const _AbilityLowerName = "efficiencytankfastsplitburrowresurrectionflycamouflagehybridattack"
+const _AbilityLowerName = "efficiencytankfastsplitburrowresurrectionflycamouflagehybridattackhealing_aurashield_auraspeed_auracommander"
 
 func (i Ability) String() string {
 	if i < 0 || i >= Ability(len(_AbilityIndex)-1) {
@@ -35,31 +35,43 @@ func _AbilityNoOp() {
 	_ = x[Camouflage-(7)]
 	_ = x[Hybrid-(8)]
 	_ = x[Attack-(9)]
+	_ = x[HealingAura-(10)]
+	_ = x[ShieldAura-(11)]
+	_ = x[SpeedAura-(12)]
+	_ = x[Commander-(13)]
 }
 
-var _AbilityValues = []Ability{Efficiency, Tank, Fast, Split, Burrow, Resurrection, Fly, Camouflage, Hybrid, Attack}
+var _AbilityValues = []Ability{Efficiency, Tank, Fast, Split, Burrow, Resurrection, Fly, Camouflage, Hybrid, Attack, HealingAura, ShieldAura, SpeedAura, Commander}
 
 var _AbilityNameToValueMap = map[string]Ability{
-	_AbilityName[0:10]:       Efficiency,
-	_AbilityLowerName[0:10]:  Efficiency,
-	_AbilityName[10:14]:      Tank,
-	_AbilityLowerName[10:14]: Tank,
-	_AbilityName[14:18]:      Fast,
-	_AbilityLowerName[14:18]: Fast,
-	_AbilityName[18:23]:      Split,
-	_AbilityLowerName[18:23]: Split,
-	_AbilityName[23:29]:      Burrow,
-	_AbilityLowerName[23:29]: Burrow,
-	_AbilityName[29:41]:      Resurrection,
-	_AbilityLowerName[29:41]: Resurrection,
-	_AbilityName[41:44]:      Fly,
-	_AbilityLowerName[41:44]: Fly,
-	_AbilityName[44:54]:      Camouflage,
-	_AbilityLowerName[44:54]: Camouflage,
-	_AbilityName[54:60]:      Hybrid,
-	_AbilityLowerName[54:60]: Hybrid,
-	_AbilityName[60:66]:      Attack,
-	_AbilityLowerName[60:66]: Attack,
+	_AbilityName[0:10]:        Efficiency,
+	_AbilityLowerName[0:10]:   Efficiency,
+	_AbilityName[10:14]:       Tank,
+	_AbilityLowerName[10:14]:  Tank,
+	_AbilityName[14:18]:       Fast,
+	_AbilityLowerName[14:18]:  Fast,
+	_AbilityName[18:23]:       Split,
+	_AbilityLowerName[18:23]:  Split,
+	_AbilityName[23:29]:       Burrow,
+	_AbilityLowerName[23:29]:  Burrow,
+	_AbilityName[29:41]:       Resurrection,
+	_AbilityLowerName[29:41]:  Resurrection,
+	_AbilityName[41:44]:       Fly,
+	_AbilityLowerName[41:44]:  Fly,
+	_AbilityName[44:54]:       Camouflage,
+	_AbilityLowerName[44:54]:  Camouflage,
+	_AbilityName[54:60]:       Hybrid,
+	_AbilityLowerName[54:60]:  Hybrid,
+	_AbilityName[60:66]:       Attack,
+	_AbilityLowerName[60:66]:  Attack,
+	_AbilityName[66:78]:       HealingAura,
+	_AbilityLowerName[66:78]:  HealingAura,
+	_AbilityName[78:89]:       ShieldAura,
+	_AbilityLowerName[78:89]:  ShieldAura,
+	_AbilityName[89:99]:       SpeedAura,
+	_AbilityLowerName[89:99]:  SpeedAura,
+	_AbilityName[99:108]:      Commander,
+	_AbilityLowerName[99:108]: Commander,
 }
 
 var _AbilityNames = []string{
@@ -73,6 +85,10 @@ var _AbilityNames = []string{
 	_AbilityName[44:54],
 	_AbilityName[54:60],
 	_AbilityName[60:66],
+	_AbilityName[66:78],
+	_AbilityName[78:89],
+	_AbilityName[89:99],
+	_AbilityName[99:108],
 }
 
 // AbilityString retrieves an enum value from the enum constants string name.
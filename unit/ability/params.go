@@ -0,0 +1,224 @@
+package ability
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Params are the parameters of the Abilities of a Unit, the key is the
+// Ability and the value the *Params type of it, like SplitParams, for
+// each rank. The first one is the rank 1
+type Params map[Ability][]interface{}
+
+// SplitParams are the parameters of the Split
+type SplitParams struct {
+	// SpeedFactor multiplies the MovementSpeed of the split Units
+	SpeedFactor float64 `json:"speed_factor"`
+	// Offset is the number of steps the second
+	// split Unit is moved forward
+	Offset int `json:"offset"`
+}
+
+// BurrowParams are the parameters of the Burrow
+type BurrowParams struct {
+	// Health is the percentage (0-1) of the Health in which it burrows
+	Health float64 `json:"health"`
+	// Duration is the seconds it stays burrowed
+	// until the next Unit can unburrow it
+	Duration float64 `json:"duration"`
+	// MaxDuration is the seconds after which it unburrows itself
+	MaxDuration float64 `json:"max_duration"`
+}
+
+// ResurrectionParams are the parameters of the Resurrection
+type ResurrectionParams struct {
+	// Delay is the seconds it takes to return to life
+	Delay float64 `json:"delay"`
+	// Health is the percentage (0-1) of the Health it returns with
+	Health float64 `json:"health"`
+}
+
+// HybridParams are the parameters of the Hybrid
+type HybridParams struct {
+	// Factor multiplies the percentage difference of
+	// Income used to increase the stats of the Unit
+	Factor float64 `json:"factor"`
+}
+
+var (
+	// paramsTypes are the types of the Params of the Abilities that have them,
+	// the values are only defined on the ability_params of the Units
+	paramsTypes = map[Ability]reflect.Type{
+		Split:        reflect.TypeOf(SplitParams{}),
+		Burrow:       reflect.TypeOf(BurrowParams{}),
+		Resurrection: reflect.TypeOf(ResurrectionParams{}),
+		Hybrid:       reflect.TypeOf(HybridParams{}),
+	}
+)
+
+// HasParams returns if the Ability a has parameters
+func HasParams(a Ability) bool {
+	_, ok := paramsTypes[a]
+	return ok
+}
+
+// Validate checks that p has the ranks of all
+// the Abilities of as with parameters
+func (p Params) Validate(as []Ability) error {
+	for _, a := range as {
+		if HasParams(a) && len(p[a]) == 0 {
+			return fmt.Errorf("ability %q params are required", a)
+		}
+	}
+	return nil
+}
+
+// Ranks returns the number of ranks the Ability a has on p,
+// it's 1 for the Abilities that have no parameters
+func Ranks(p Params, a Ability) int {
+	return max(1, len(p[a]))
+}
+
+// ParamsOf returns the parameters T of the rank r of the Ability a
+// from p. If the Ability does not have the rank r the closest one
+// is returned
+func ParamsOf[T any](p Params, a Ability, r int) T {
+	var v T
+	rs := p[a]
+	if len(rs) == 0 {
+		return v
+	}
+	r = min(max(r, 1), len(rs))
+	v, _ = rs[r-1].(T)
+	return v
+}
+
+// RankDescription returns the values of the rank r of the
+// Ability a from p, it's empty if the Ability has no parameters
+func RankDescription(p Params, a Ability, r int) string {
+	rs := p[a]
+	if len(rs) == 0 {
+		return ""
+	}
+	r = min(max(r, 1), len(rs))
+	d, ok := rs[r-1].(interface{ describe() string })
+	if !ok {
+		return ""
+	}
+	return d.describe()
+}
+
+// UnmarshalJSON decodes the list of ranks of each Ability into its type.
+// Each rank starts from the current value of it, or from the previous
+// rank if it's a new one, so only the fields that change have to be set
+func (p *Params) UnmarshalJSON(b []byte) error {
+	var raw map[string][]json.RawMessage
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+	np := make(Params, len(*p)+len(raw))
+	for a, rs := range *p {
+		np[a] = rs
+	}
+	for k, rrs := range raw {
+		a, err := AbilityString(k)
+		if err != nil {
+			return err
+		}
+		pt, ok := paramsTypes[a]
+		if !ok {
+			return fmt.Errorf("ability %q has no params", a)
+		}
+		if len(rrs) == 0 {
+			return fmt.Errorf("ability %q params has no ranks", a)
+		}
+		// The slice is always a new one as it may
+		// be shared with the Default or other Units
+		crs := np[a]
+		rs := make([]interface{}, max(len(crs), len(rrs)))
+		copy(rs, crs)
+		for i, r := range rrs {
+			v := rs[i]
+			if v == nil && i == 0 {
+				v = reflect.Zero(pt).Interface()
+			} else if v == nil {
+				v = rs[i-1]
+			}
+			nv := reflect.New(reflect.TypeOf(v))
+			nv.Elem().Set(reflect.ValueOf(v))
+			dec := json.NewDecoder(bytes.NewReader(r))
+			dec.DisallowUnknownFields()
+			err = dec.Decode(nv.Interface())
+			if err != nil {
+				return fmt.Errorf("ability %q params rank %d: %w", a, i+1, err)
+			}
+			if vl, ok := nv.Interface().(interface{ validate() error }); ok {
+				err = vl.validate()
+				if err != nil {
+					return fmt.Errorf("ability %q params rank %d: %w", a, i+1, err)
+				}
+			}
+			rs[i] = nv.Elem().Interface()
+		}
+		np[a] = rs
+	}
+	*p = np
+	return nil
+}
+
+func (p *SplitParams) validate() error {
+	switch {
+	case p.SpeedFactor <= 0:
+		return errors.New("speed_factor has to be positive")
+	case p.Offset < 0:
+		return errors.New("offset can not be negative")
+	}
+	return nil
+}
+
+func (p SplitParams) describe() string {
+	return fmt.Sprintf("Split units speed x%.1f", p.SpeedFactor)
+}
+
+func (p *BurrowParams) validate() error {
+	switch {
+	case p.Health <= 0 || p.Health > 1:
+		return errors.New("health has to be between 0 and 1")
+	case p.Duration < 0 || p.MaxDuration < p.Duration:
+		return errors.New("duration can not be negative or greater than max_duration")
+	}
+	return nil
+}
+
+func (p BurrowParams) describe() string {
+	return fmt.Sprintf("Burrows at %.0f%% life for %.0fs (max %.0fs)", p.Health*100, p.Duration, p.MaxDuration)
+}
+
+func (p *ResurrectionParams) validate() error {
+	switch {
+	case p.Health <= 0 || p.Health > 1:
+		return errors.New("health has to be between 0 and 1")
+	case p.Delay < 0:
+		return errors.New("delay can not be negative")
+	}
+	return nil
+}
+
+func (p ResurrectionParams) describe() string {
+	return fmt.Sprintf("Returns with %.0f%% life after %.1fs", p.Health*100, p.Delay)
+}
+
+func (p *HybridParams) validate() error {
+	if p.Factor < 0 {
+		return errors.New("factor can not be negative")
+	}
+	return nil
+}
+
+func (p HybridParams) describe() string {
+	return fmt.Sprintf("Income difference bonus x%.2f", p.Factor)
+}
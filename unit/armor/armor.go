@@ -0,0 +1,25 @@
+package armor
+
+//go:generate enumer -type=Armor -transform=lower -json -text -transform=snake -output=armor_string.go
+
+// Armor is the class of armor of a Unit, the damage it
+// receives depends on it and the damage.Damage of the Tower
+type Armor int
+
+const (
+	Light Armor = iota
+	Medium
+	Heavy
+	Fortified
+)
+
+var (
+	names = map[Armor]string{
+		Light:     "Light",
+		Medium:    "Medium",
+		Heavy:     "Heavy",
+		Fortified: "Fortified",
+	}
+)
+
+func Name(a Armor) string { return names[a] }
@@ -0,0 +1,116 @@
+// Code generated by "enumer -type=Armor -transform=lower -json -text -transform=snake -output=armor_string.go"; DO NOT EDIT.
+
+package armor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const _ArmorName = "lightmediumheavyfortified"
+
+var _ArmorIndex = [...]uint8{0, 5, 11, 16, 25}
+
+const _ArmorLowerName = "lightmediumheavyfortified"
+
+func (i Armor) String() string {
+	if i < 0 || i >= Armor(len(_ArmorIndex)-1) {
+		return fmt.Sprintf("Armor(%d)", i)
+	}
+	return _ArmorName[_ArmorIndex[i]:_ArmorIndex[i+1]]
+}
+
+// An "invalid array index" compiler error signifies that the constant values have changed.
+// Re-run the stringer command to generate them again.
+func _ArmorNoOp() {
+	var x [1]struct{}
+	_ = x[Light-(0)]
+	_ = x[Medium-(1)]
+	_ = x[Heavy-(2)]
+	_ = x[Fortified-(3)]
+}
+
+var _ArmorValues = []Armor{Light, Medium, Heavy, Fortified}
+
+var _ArmorNameToValueMap = map[string]Armor{
+	_ArmorName[0:5]:        Light,
+	_ArmorLowerName[0:5]:   Light,
+	_ArmorName[5:11]:       Medium,
+	_ArmorLowerName[5:11]:  Medium,
+	_ArmorName[11:16]:      Heavy,
+	_ArmorLowerName[11:16]: Heavy,
+	_ArmorName[16:25]:      Fortified,
+	_ArmorLowerName[16:25]: Fortified,
+}
+
+var _ArmorNames = []string{
+	_ArmorName[0:5],
+	_ArmorName[5:11],
+	_ArmorName[11:16],
+	_ArmorName[16:25],
+}
+
+// ArmorString retrieves an enum value from the enum constants string name.
+// Throws an error if the param is not part of the enum.
+func ArmorString(s string) (Armor, error) {
+	if val, ok := _ArmorNameToValueMap[s]; ok {
+		return val, nil
+	}
+
+	if val, ok := _ArmorNameToValueMap[strings.ToLower(s)]; ok {
+		return val, nil
+	}
+	return 0, fmt.Errorf("%s does not belong to Armor values", s)
+}
+
+// ArmorValues returns all values of the enum
+func ArmorValues() []Armor {
+	return _ArmorValues
+}
+
+// ArmorStrings returns a slice of all String values of the enum
+func ArmorStrings() []string {
+	strs := make([]string, len(_ArmorNames))
+	copy(strs, _ArmorNames)
+	return strs
+}
+
+// IsAArmor returns "true" if the value is listed in the enum definition. "false" otherwise
+func (i Armor) IsAArmor() bool {
+	for _, v := range _ArmorValues {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON implements the json.Marshaler interface for Armor
+func (i Armor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(i.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Armor
+func (i *Armor) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("Armor should be a string, got %s", data)
+	}
+
+	var err error
+	*i, err = ArmorString(s)
+	return err
+}
+
+// MarshalText implements the encoding.TextMarshaler interface for Armor
+func (i Armor) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface for Armor
+func (i *Armor) UnmarshalText(text []byte) error {
+	var err error
+	*i, err = ArmorString(string(text))
+	return err
+}
@@ -7,4 +7,60 @@ type Buff int
 const (
 	Burrowoed Buff = iota
 	Resurrecting
+
+	// Timed effects applied by the Towers projectiles
+	Slowed
+	Poisoned
+	Stunned
+	ArmorShredded
+
+	// Timed effects applied by the Heroes
+	Shielded
 )
+
+// Effect is a timed Buff that a Tower projectile
+// applies to the Unit it hits
+type Effect struct {
+	Buff Buff `json:"buff"`
+
+	// Value depends on the Buff:
+	// * Slowed: percentage (0-1) of the movement speed reduced
+	// * Poisoned: damage per second of each stack
+	// * Stunned: not used
+	// * ArmorShredded: armor reduced by each stack
+	// * Shielded: armor increased
+	Value float64 `json:"value"`
+
+	// Duration is the seconds the Buff lasts since
+	// the last time it was applied
+	Duration float64 `json:"duration"`
+}
+
+var (
+	// maxStacks is the number of times the same Buff can be
+	// applied to a Unit, the ones not here can not be an Effect
+	maxStacks = map[Buff]int{
+		Slowed:        1,
+		Poisoned:      5,
+		Stunned:       1,
+		ArmorShredded: 3,
+		Shielded:      1,
+	}
+
+	// heroEffects are the Effects that only the
+	// Heroes apply, the Towers can not use them
+	heroEffects = map[Buff]struct{}{
+		Shielded: {},
+	}
+)
+
+// IsEffect checks if b is a Buff that can be applied as an Effect
+func (b Buff) IsEffect() bool { _, ok := maxStacks[b]; return ok }
+
+// IsHeroEffect checks if b is an Effect applied only by the Heroes
+func (b Buff) IsHeroEffect() bool { _, ok := heroEffects[b]; return ok }
+
+// MaxStacks returns the maximum number of stacks of b, when
+// it's applied again with the max stacks it only refreshes
+// the duration
+func MaxStacks(b Buff) int { return maxStacks[b] }
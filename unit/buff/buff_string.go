@@ -8,11 +8,11 @@ import (
 	"strings"
 )
 
-const _BuffName = "burrowoedresurrecting"
+const _BuffName = "burrowoedresurrectingslowedpoisonedstunnedarmor_shreddedshielded"
 
-var _BuffIndex = [...]uint8{0, 9, 21}
+var _BuffIndex = [...]uint8{0, 9, 21, 27, 35, 42, 56, 64}
 
-const _BuffLowerName = "burrowoedresurrecting"
+const _BuffLowerName = "burrowoedresurrectingslowedpoisonedstunnedarmor_shreddedshielded"
 
 func (i Buff) String() string {
 	if i < 0 || i >= Buff(len(_BuffIndex)-1) {
@@ -27,20 +27,40 @@ func _BuffNoOp() {
 	var x [1]struct{}
 	_ = x[Burrowoed-(0)]
 	_ = x[Resurrecting-(1)]
+	_ = x[Slowed-(2)]
+	_ = x[Poisoned-(3)]
+	_ = x[Stunned-(4)]
+	_ = x[ArmorShredded-(5)]
+	_ = x[Shielded-(6)]
 }
 
-var _BuffValues = []Buff{Burrowoed, Resurrecting}
+var _BuffValues = []Buff{Burrowoed, Resurrecting, Slowed, Poisoned, Stunned, ArmorShredded, Shielded}
 
 var _BuffNameToValueMap = map[string]Buff{
-	_BuffName[0:9]:       Burrowoed,
-	_BuffLowerName[0:9]:  Burrowoed,
-	_BuffName[9:21]:      Resurrecting,
-	_BuffLowerName[9:21]: Resurrecting,
+	_BuffName[0:9]:        Burrowoed,
+	_BuffLowerName[0:9]:   Burrowoed,
+	_BuffName[9:21]:       Resurrecting,
+	_BuffLowerName[9:21]:  Resurrecting,
+	_BuffName[21:27]:      Slowed,
+	_BuffLowerName[21:27]: Slowed,
+	_BuffName[27:35]:      Poisoned,
+	_BuffLowerName[27:35]: Poisoned,
+	_BuffName[35:42]:      Stunned,
+	_BuffLowerName[35:42]: Stunned,
+	_BuffName[42:56]:      ArmorShredded,
+	_BuffLowerName[42:56]: ArmorShredded,
+	_BuffName[56:64]:      Shielded,
+	_BuffLowerName[56:64]: Shielded,
 }
 
 var _BuffNames = []string{
 	_BuffName[0:9],
 	_BuffName[9:21],
+	_BuffName[21:27],
+	_BuffName[27:35],
+	_BuffName[35:42],
+	_BuffName[42:56],
+	_BuffName[56:64],
 }
 
 // BuffString retrieves an enum value from the enum constants string name.
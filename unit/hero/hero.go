@@ -0,0 +1,35 @@
+package hero
+
+import "time"
+
+//go:generate enumer -type=Ability -transform=lower -json -text -transform=snake -output=ability_string.go
+
+// Ability is an active ability of the Hero
+// that the Player triggers when wanted
+type Ability int
+
+const (
+	Dash Ability = iota
+	Shield
+)
+
+var (
+	names = map[Ability]string{
+		Dash:   "Dash",
+		Shield: "Shield",
+	}
+	descriptions = map[Ability]string{
+		Dash: `Moves the hero forward 5 tiles
+on its current path.`,
+		Shield: `Increases the armor of all the friendly
+units on the line of the hero for 5 seconds.`,
+	}
+	cooldowns = map[Ability]time.Duration{
+		Dash:   10 * time.Second,
+		Shield: 30 * time.Second,
+	}
+)
+
+func Name(a Ability) string            { return names[a] }
+func Description(a Ability) string     { return descriptions[a] }
+func Cooldown(a Ability) time.Duration { return cooldowns[a] }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/xescugc/maze-wars/assets"
 	"github.com/xescugc/maze-wars/unit/ability"
+	"github.com/xescugc/maze-wars/unit/armor"
 	"github.com/xescugc/maze-wars/unit/environment"
 	"github.com/xescugc/maze-wars/utils"
 )
@@ -21,6 +22,14 @@ type Unit struct {
 	Environment environment.Environment `json:"environment"`
 	Abilities   []ability.Ability       `json:"abilities"`
 
+	// AbilityParams are the parameters of the Abilities,
+	// all the ones that have them have to be defined
+	AbilityParams ability.Params `json:"ability_params"`
+
+	// ArmorType changes the damage received
+	// depending on the damage.Damage of the Tower
+	ArmorType armor.Armor `json:"armor_type"`
+
 	Keybind string
 
 	Faceset image.Image
@@ -110,6 +119,12 @@ func init() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	for ut, u := range Units {
+		err = u.AbilityParams.Validate(u.Abilities)
+		if err != nil {
+			log.Fatalf("unit %q: %s", ut, err)
+		}
+	}
 
 	profiles, _, err := image.Decode(bytes.NewReader(assets.UnitsProfile_png))
 	if err != nil {
@@ -0,0 +1,375 @@
+package graph
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/xescugc/maze-wars/unit/environment"
+	"github.com/xescugc/maze-wars/utils"
+)
+
+const (
+	// unreachable is the flow distance of the Nodes
+	// from which the DeathNode can not be reached
+	unreachable = math.MaxInt
+)
+
+// EnableFlowField calculates the distance of all the Nodes to the DeathNode
+// for the environment.Terrestrial units and from then on it's updated
+// incrementally when Towers are added or removed. With it the Path
+// to the DeathNode follows the field instead of searching for it.
+func (g *Graph) EnableFlowField() {
+	g.flowField = true
+
+	fq := &flowQueue{}
+	for _, xn := range g.Nodes {
+		for _, n := range xn {
+			n.flowDistance = unreachable
+			n.flowNext = nil
+		}
+	}
+	g.DeathNode.flowDistance = 0
+	heap.Push(fq, g.DeathNode)
+	g.propagateFlow(fq)
+}
+
+// HasFlowField returns if the flow field is enabled
+func (g *Graph) HasFlowField() bool { return g.flowField }
+
+// FlowStep returns the next Step to go from the Node x,y to the DeathNode
+// by following the flow field, which is a constant lookup. Between the
+// neighbors at the same distance it prefers the ones that change the
+// facing d, as Path does. It returns false if there is no flow field,
+// the Node is the DeathNode or it can not reach it.
+func (g *Graph) FlowStep(x, y int, d utils.Direction) (Step, bool) {
+	n := g.GetNode(x, y)
+	if !g.flowField || n == nil || n == g.DeathNode || n.flowDistance == unreachable {
+		return Step{}, false
+	}
+	return nextFlowStep(n, d, func(n *Node) int { return n.flowDistance })
+}
+
+// FlowSteps returns the Steps to go from sx,sy to the next Node of the flow field in
+// the same format as Path at scale, so the units can follow the field one Node at a
+// time instead of having the whole path. It returns nil if there is no flow field,
+// sx,sy is on the DeathNode or it can not reach it.
+func (g *Graph) FlowSteps(sx, sy, ms float64, d utils.Direction) []Step {
+	sn := g.GetNodeOf(int(sx), int(sy))
+	if sn == nil {
+		return nil
+	}
+	s, ok := g.FlowStep(sn.X, sn.Y, d)
+	if !ok {
+		return nil
+	}
+	current := &queueItem{
+		step: s,
+		parent: &queueItem{
+			step: Step{Node: sn, Facing: d},
+		},
+	}
+	steps := g.buildSteps(current, sx, sy, ms, environment.Terrestrial, false, atScale)
+	// The first Step is the Node of sx,sy which
+	// is skipped if it's already on it
+	if len(steps) != 0 && steps[0].X == sx && steps[0].Y == sy {
+		steps = steps[1:]
+	}
+	return steps
+}
+
+// FlowDistance returns the distance in pixels from the Node x,y to the DeathNode
+// following the flow field, the Terrain makes the Nodes longer as it's slower
+// to move through them. It returns false if there is no flow field or
+// the Node can not reach the DeathNode.
+func (g *Graph) FlowDistance(x, y int) (int, bool) {
+	n := g.GetNodeOf(x, y)
+	if !g.flowField || n == nil || n.flowDistance == unreachable {
+		return 0, false
+	}
+	return n.flowDistance * g.Scale, true
+}
+
+// nextFlowStep returns the Step from n to the neighbor closer to the DeathNode with the
+// distances of dist, the ties are resolved by preferring the ones that change the facing d
+func nextFlowStep(n *Node, d utils.Direction, dist func(n *Node) int) (Step, bool) {
+	var (
+		ns  *Step
+		nsd int
+	)
+	for i, s := range n.NeighborSteps {
+		if dist(s.Node) == unreachable {
+			continue
+		}
+		sd := dist(s.Node) + s.Node.Terrain.Cost(environment.Terrestrial)
+		if ns == nil || sd < nsd || (sd == nsd && ns.Facing == d && s.Facing != d) {
+			ns, nsd = &n.NeighborSteps[i], sd
+		}
+	}
+	if ns == nil {
+		return Step{}, false
+	}
+	s := *ns
+	s.X, s.Y = float64(s.Node.X), float64(s.Node.Y)
+	return s, true
+}
+
+// shortestPath returns the shortest path from the Spawn Zone to the DeathNode as
+// if the blocked Nodes had a Tower, or nil if any of the Spawn Zone can not reach it.
+// It does not use nor change the flow field so it can be used without it
+func (g *Graph) shortestPath(blocked map[*Node]struct{}) []Step {
+	dists := map[*Node]int{g.DeathNode: 0}
+	dist := func(n *Node) int {
+		if d, ok := dists[n]; ok {
+			return d
+		}
+		return unreachable
+	}
+
+	// It's a Dijkstra from the DeathNode like the flow field
+	// in which the outdated items of the queue are skipped
+	dq := &distQueue{{node: g.DeathNode}}
+	for dq.Len() != 0 {
+		di := heap.Pop(dq).(distItem)
+		if di.distance != dists[di.node] {
+			continue
+		}
+		for _, nn := range di.node.Neighbors {
+			if _, ok := blocked[nn]; ok || !nn.canFlow() {
+				continue
+			}
+			d := di.distance + di.node.Terrain.Cost(environment.Terrestrial)
+			if d >= dist(nn) {
+				continue
+			}
+			dists[nn] = d
+			heap.Push(dq, distItem{node: nn, distance: d})
+		}
+	}
+
+	return g.spawnPath(dist)
+}
+
+// spawnPath returns the path from the closest Spawn Zone Node to the DeathNode
+// with the distances of dist, or nil if any of the Spawn Zone can not reach it
+func (g *Graph) spawnPath(dist func(n *Node) int) []Step {
+	var sn *Node
+	for _, n := range g.spawnNodes {
+		if dist(n) == unreachable {
+			return nil
+		}
+		if sn == nil || dist(n) < dist(sn) {
+			sn = n
+		}
+	}
+
+	steps := []Step{{Node: sn, X: float64(sn.X), Y: float64(sn.Y), Facing: utils.Down}}
+	for n := sn; n != g.DeathNode; {
+		s, _ := nextFlowStep(n, steps[len(steps)-1].Facing, dist)
+		steps = append(steps, s)
+		n = s.Node
+	}
+	for i := range steps {
+		steps[i].Node = nil
+	}
+	return steps
+}
+
+// flowPath returns the path from sn to the DeathNode following
+// the flow field in the same format as Path
+func (g *Graph) flowPath(sn *Node, sx, sy, ms float64, d utils.Direction, atScale bool) []Step {
+	current := &queueItem{
+		step: Step{Node: sn, Facing: d},
+	}
+	for current.step.Node != g.DeathNode {
+		s, ok := g.FlowStep(current.step.Node.X, current.step.Node.Y, current.step.Facing)
+		if !ok {
+			return nil
+		}
+		current = &queueItem{
+			step:   s,
+			parent: current,
+		}
+	}
+	return g.buildSteps(current, sx, sy, ms, environment.Terrestrial, false, atScale)
+}
+
+// canUseFlowField checks if the flow field can be used to calculate the path
+// from sn to tn with the rest of the arguments of Path
+func (g *Graph) canUseFlowField(sn, tn *Node, env environment.Environment, isAttacker bool) bool {
+	return g.flowField && tn == g.DeathNode && env == environment.Terrestrial && !isAttacker && sn.flowDistance != unreachable
+}
+
+// addFlowTowers updates the flow field after the nodes
+// have been blocked by a Tower
+func (g *Graph) addFlowTowers(nodes []*Node) {
+	if !g.flowField {
+		return
+	}
+
+	// All the Nodes that were going through the blocked ones
+	// have to be calculated again, the list keeps the order
+	// so the result is always the same
+	affected := make(map[*Node]struct{})
+	list := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		affected[n] = struct{}{}
+		list = append(list, n)
+	}
+	for i := 0; i < len(list); i++ {
+		for _, nn := range list[i].Neighbors {
+			if _, ok := affected[nn]; ok || nn.flowNext != list[i] {
+				continue
+			}
+			affected[nn] = struct{}{}
+			list = append(list, nn)
+		}
+	}
+	for _, n := range list {
+		n.flowDistance = unreachable
+		n.flowNext = nil
+	}
+
+	// The affected Nodes are calculated again from
+	// the ones around that were not affected
+	fq := &flowQueue{}
+	for _, n := range list {
+		if !n.canFlow() {
+			continue
+		}
+		for _, nn := range n.Neighbors {
+			if _, ok := affected[nn]; ok || nn.flowDistance == unreachable {
+				continue
+			}
+			if d := nn.flowDistance + nn.Terrain.Cost(environment.Terrestrial); d < n.flowDistance {
+				n.flowDistance = d
+				n.flowNext = nn
+			}
+		}
+		if n.flowDistance != unreachable {
+			heap.Push(fq, n)
+		}
+	}
+	g.propagateFlow(fq)
+}
+
+// removeFlowTowers updates the flow field after the
+// nodes have been unblocked from a Tower
+func (g *Graph) removeFlowTowers(nodes []*Node) {
+	if !g.flowField {
+		return
+	}
+
+	// The nodes come from a map so they are sorted
+	// to always have the same result
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].X != nodes[j].X {
+			return nodes[i].X < nodes[j].X
+		}
+		return nodes[i].Y < nodes[j].Y
+	})
+
+	fq := &flowQueue{}
+	for _, n := range nodes {
+		if !n.canFlow() {
+			continue
+		}
+		for _, nn := range n.Neighbors {
+			if nn.flowDistance == unreachable {
+				continue
+			}
+			if d := nn.flowDistance + nn.Terrain.Cost(environment.Terrestrial); d < n.flowDistance {
+				n.flowDistance = d
+				n.flowNext = nn
+			}
+		}
+		if n.flowDistance != unreachable {
+			heap.Push(fq, n)
+		}
+	}
+	g.propagateFlow(fq)
+}
+
+// propagateFlow runs a Dijkstra from the Nodes on fq
+// updating the ones that get a shorter distance
+func (g *Graph) propagateFlow(fq *flowQueue) {
+	for fq.Len() != 0 {
+		cn := heap.Pop(fq).(*Node)
+		for _, nn := range cn.Neighbors {
+			if !nn.canFlow() {
+				continue
+			}
+			// Moving from nn to cn costs as
+			// much as the Terrain of cn
+			d := cn.flowDistance + cn.Terrain.Cost(environment.Terrestrial)
+			if d >= nn.flowDistance {
+				continue
+			}
+			nn.flowDistance = d
+			nn.flowNext = cn
+			if nn.flowIndex != 0 {
+				heap.Fix(fq, nn.flowIndex-1)
+			} else {
+				heap.Push(fq, nn)
+			}
+		}
+	}
+}
+
+// canFlow checks if the Terrestrial units can go through n
+func (n *Node) canFlow() bool {
+	return !n.HasTower() && n.Terrain.CanPass(environment.Terrestrial)
+}
+
+// flowQueue is the priority queue of Nodes by the flowDistance,
+// the Node.flowIndex is the index on it plus 1 so the 0 means
+// that the Node is not on the queue
+type flowQueue []*Node
+
+func (q flowQueue) Len() int { return len(q) }
+
+func (q flowQueue) Less(i, j int) bool { return q[i].flowDistance < q[j].flowDistance }
+
+func (q flowQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].flowIndex = i + 1
+	q[j].flowIndex = j + 1
+}
+
+func (q *flowQueue) Push(x interface{}) {
+	n := x.(*Node)
+	n.flowIndex = len(*q) + 1
+	*q = append(*q, n)
+}
+
+func (q *flowQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	n.flowIndex = 0
+	*q = old[:len(old)-1]
+	return n
+}
+
+// distItem is a Node with a distance for the distQueue
+type distItem struct {
+	node     *Node
+	distance int
+}
+
+// distQueue is a priority queue of distItem by the distance
+type distQueue []distItem
+
+func (q distQueue) Len() int { return len(q) }
+
+func (q distQueue) Less(i, j int) bool { return q[i].distance < q[j].distance }
+
+func (q distQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *distQueue) Push(x interface{}) { *q = append(*q, x.(distItem)) }
+
+func (q *distQueue) Pop() interface{} {
+	old := *q
+	di := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return di
+}
@@ -0,0 +1,196 @@
+package graph_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/unit/environment"
+	"github.com/xescugc/maze-wars/utils"
+	"github.com/xescugc/maze-wars/utils/graph"
+)
+
+func TestGraph_FlowStep(t *testing.T) {
+	g, err := graph.New(0, 0, 3, 3, 1, 1, 1, 1)
+	require.NoError(t, err)
+
+	_, ok := g.FlowStep(1, 0, utils.Down)
+	assert.False(t, ok, "Without flow field")
+
+	g.EnableFlowField()
+	assert.True(t, g.HasFlowField())
+	require.Equal(t, g.GetNode(1, 2), g.DeathNode)
+
+	s, ok := g.FlowStep(1, 0, utils.Down)
+	require.True(t, ok)
+	assert.Equal(t, graph.Step{Node: g.GetNode(1, 1), X: 1, Y: 1, Facing: utils.Down}, s)
+
+	_, ok = g.FlowStep(1, 2, utils.Down)
+	assert.False(t, ok, "On the DeathNode")
+
+	require.NoError(t, g.AddTower("id", 1, 1, 1, 1))
+	_, ok = g.FlowStep(1, 1, utils.Down)
+	assert.False(t, ok, "On a Tower")
+
+	// It prefers to change the facing
+	s, ok = g.FlowStep(1, 0, utils.Down)
+	require.True(t, ok)
+	assert.NotEqual(t, utils.Down, s.Facing)
+
+	steps, _ := g.Path(1, 0, 60, utils.Down, 1, 2, environment.Terrestrial, !isAttacker, !atScale, useCache)
+	assert.Len(t, steps, 5)
+
+	require.True(t, g.RemoveTower("id"))
+	steps, _ = g.Path(1, 0, 60, utils.Down, 1, 2, environment.Terrestrial, !isAttacker, !atScale, useCache)
+	assert.Equal(t, []graph.Step{
+		{X: 1, Y: 0, Facing: utils.Down},
+		{X: 1, Y: 1, Facing: utils.Down},
+		{X: 1, Y: 2, Facing: utils.Down},
+	}, steps)
+}
+
+func TestGraph_FlowSteps(t *testing.T) {
+	g, err := graph.New(0, 0, 3, 3, 16, 1, 1, 1)
+	require.NoError(t, err)
+
+	assert.Nil(t, g.FlowSteps(16, 0, 60, utils.Down), "Without flow field")
+	_, ok := g.FlowDistance(16, 0)
+	assert.False(t, ok, "Without flow field")
+
+	g.EnableFlowField()
+	require.Equal(t, g.GetNode(16, 32), g.DeathNode)
+
+	// It only goes to the next Node moving 1px each Step
+	steps := g.FlowSteps(16, 0, 60, utils.Down)
+	require.Len(t, steps, 16)
+	assert.Equal(t, graph.Step{X: 16, Y: 1, Facing: utils.Down}, steps[0])
+	assert.Equal(t, graph.Step{X: 16, Y: 16, Facing: utils.Down}, steps[15])
+
+	d, ok := g.FlowDistance(16, 0)
+	require.True(t, ok)
+	assert.Equal(t, 32, d)
+
+	assert.Nil(t, g.FlowSteps(16, 32, 60, utils.Down), "On the DeathNode")
+}
+
+func TestGraph_FlowField(t *testing.T) {
+	t.Run("Incremental", func(t *testing.T) {
+		// After adding and removing towers the flow field has
+		// to be the same as the one calculated from scratch
+		g, err := graph.New(0, 0, 16, 30, 16, 3, 24, 3)
+		require.NoError(t, err)
+		g.EnableFlowField()
+
+		r := rand.New(rand.NewSource(42))
+		towers := make(map[string][2]int)
+		for i := 0; i < 200; i++ {
+			if len(towers) != 0 && r.Intn(4) == 0 {
+				for id := range towers {
+					require.True(t, g.RemoveTower(id))
+					delete(towers, id)
+					break
+				}
+			} else {
+				x, y := r.Intn(15)*16, (3+r.Intn(23))*16
+				id := fmt.Sprintf("t%d", i)
+				if g.AddTower(id, x, y, 32, 32) == nil {
+					towers[id] = [2]int{x, y}
+				}
+			}
+
+			fg, err := graph.New(0, 0, 16, 30, 16, 3, 24, 3)
+			require.NoError(t, err)
+			for id, p := range towers {
+				require.NoError(t, fg.AddTower(id, p[0], p[1], 32, 32))
+			}
+			fg.EnableFlowField()
+
+			for x := 0; x < 16; x++ {
+				for y := 0; y < 30; y++ {
+					for _, d := range []utils.Direction{utils.Up, utils.Down, utils.Left, utils.Right} {
+						s, ok := g.FlowStep(x*16, y*16, d)
+						fs, fok := fg.FlowStep(x*16, y*16, d)
+						require.Equal(t, fok, ok, "X: %d, Y: %d", x, y)
+						require.Equal(t, fs.X, s.X, "X: %d, Y: %d", x, y)
+						require.Equal(t, fs.Y, s.Y, "X: %d, Y: %d", x, y)
+					}
+				}
+			}
+		}
+		assert.NotEmpty(t, towers)
+	})
+	t.Run("SamePathLength", func(t *testing.T) {
+		// Following the flow field is as short as searching
+		g, err := graph.New(0, 0, 16, 30, 16, 3, 24, 3)
+		require.NoError(t, err)
+		require.NoError(t, g.AddTower("t1", 0, 96, 32, 32))
+		require.NoError(t, g.AddTower("t2", 64, 160, 32, 32))
+		require.NoError(t, g.AddTower("t3", 160, 96, 32, 32))
+
+		fg, err := graph.New(0, 0, 16, 30, 16, 3, 24, 3)
+		require.NoError(t, err)
+		fg.EnableFlowField()
+		require.NoError(t, fg.AddTower("t1", 0, 96, 32, 32))
+		require.NoError(t, fg.AddTower("t2", 64, 160, 32, 32))
+		require.NoError(t, fg.AddTower("t3", 160, 96, 32, 32))
+
+		for x := 0; x < 16; x++ {
+			steps, _ := g.Path(float64(x*16), 0, 60, utils.Down, g.DeathNode.X, g.DeathNode.Y, environment.Terrestrial, !isAttacker, !atScale, !useCache)
+			fsteps, _ := fg.Path(float64(x*16), 0, 60, utils.Down, fg.DeathNode.X, fg.DeathNode.Y, environment.Terrestrial, !isAttacker, !atScale, !useCache)
+			require.NotEmpty(t, fsteps)
+			assert.LessOrEqual(t, len(fsteps), len(steps))
+			assert.Equal(t, float64(fg.DeathNode.X), fsteps[len(fsteps)-1].X)
+			assert.Equal(t, float64(fg.DeathNode.Y), fsteps[len(fsteps)-1].Y)
+		}
+	})
+}
+
+// benchmarkGraph returns a Graph with the size of a Line
+// and some towers so the paths are not straight
+func benchmarkGraph(b *testing.B, flow bool) *graph.Graph {
+	g, err := graph.New(0, 0, 16, 84, 16, 7, 74, 3)
+	require.NoError(b, err)
+	if flow {
+		g.EnableFlowField()
+	}
+	for y := 7; y < 78; y += 6 {
+		for x := 0; x < 14; x += 2 {
+			// It leaves a gap alternating sides
+			if (y/6)%2 == 0 && x == 0 || (y/6)%2 != 0 && x == 12 {
+				continue
+			}
+			require.NoError(b, g.AddTower(fmt.Sprintf("%d-%d", x, y), x*16, y*16, 32, 32))
+		}
+	}
+	return g
+}
+
+// benchmarkRecalculate places and removes a Tower and recalculates the Path
+// of 100 units like the store does on each tower change. With the flow field
+// the units only need the Steps to the next Node
+func benchmarkRecalculate(b *testing.B, flow bool) {
+	g := benchmarkGraph(b, flow)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := "bench"
+		if i%2 == 0 {
+			require.NoError(b, g.AddTower(id, 14*16, 40*16, 32, 32))
+		} else {
+			g.RemoveTower(id)
+		}
+		for u := 0; u < 100; u++ {
+			if flow {
+				g.FlowSteps(float64((u%16)*16), float64((u%7)*16), 60, utils.Down)
+				continue
+			}
+			g.Path(float64((u%16)*16), float64((u%7)*16), 60, utils.Down, g.DeathNode.X, g.DeathNode.Y, environment.Terrestrial, !isAttacker, atScale, useCache)
+		}
+	}
+}
+
+func BenchmarkGraph_Recalculate(b *testing.B) {
+	b.Run("Path", func(b *testing.B) { benchmarkRecalculate(b, false) })
+	b.Run("FlowField", func(b *testing.B) { benchmarkRecalculate(b, true) })
+}
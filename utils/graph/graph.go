@@ -14,6 +14,18 @@ var (
 	ErrInvalidBoundaries   = errors.New("this position is exceeds the boundaries")
 	ErrInvalidBlockingPath = errors.New("this position is blocking the path")
 	ErrInvalidZoneHeights  = errors.New("the heights of the zones does not add up to the expected height")
+	ErrInvalidZones        = errors.New("the zones need at least one spawn and one death node")
+)
+
+// Zone is the zone of a Node of the Graph
+type Zone int
+
+const (
+	// NoZone means there is no Node
+	NoZone Zone = iota
+	SpawnZone
+	BuildingZone
+	DeathZone
 )
 
 const (
@@ -46,6 +58,18 @@ type Graph struct {
 	// DeathNode is the Node that will be the default
 	// node in which the units will try to move to
 	DeathNode *Node
+
+	// spawnNodes are the Nodes of the Spawn Zone
+	// in order so they can be picked randomly
+	spawnNodes []*Node
+
+	// flowField is true when the flow
+	// field is enabled and kept updated
+	flowField bool
+
+	// towersVersion changes each time
+	// a Tower is added or removed
+	towersVersion int
 }
 
 // New crates a Graph with
@@ -64,54 +88,131 @@ func New(ox, oy, w, h, s, szh, bzh, dzh int) (*Graph, error) {
 	if szh+bzh+dzh != h {
 		return nil, ErrInvalidZoneHeights
 	}
+
+	zones := make([][]Zone, h)
+	for hi := range zones {
+		z := DeathZone
+		if hi < szh {
+			z = SpawnZone
+		} else if hi < szh+bzh {
+			z = BuildingZone
+		}
+		zones[hi] = make([]Zone, w)
+		for wi := range zones[hi] {
+			zones[hi][wi] = z
+		}
+	}
+
+	return NewWithZones(ox, oy, s, zones, nil)
+}
+
+// NewWithZones creates a Graph with ox, oy as Offsets and scale s
+// in which zones[y][x] is the Zone of the Node x,y. The ones with
+// NoZone have no Node so the lines do not need to be a rectangle.
+// The terrains[y][x] is the Terrain of the Node x,y and it can be
+// nil if all of them are Ground.
+// The DeathNode is the Node of the Death Zone closest to the
+// center of the Spawn Zone
+func NewWithZones(ox, oy, s int, zones [][]Zone, terrains [][]Terrain) (*Graph, error) {
 	// The Scale has to be at least 1
 	// it cannot be lower
 	if s <= 0 {
 		s = 1
 	}
 
+	var w, h int
+	h = len(zones)
+	for _, zr := range zones {
+		w = max(w, len(zr))
+	}
+
 	g := &Graph{
 		Nodes: make(map[int]map[int]*Node),
 
 		OffsetX: ox, OffsetY: oy,
 		W: w, H: h,
 
-		SpawnZoneH:    szh,
-		BuildingZoneH: bzh,
-		DeathZoneH:    dzh,
-
 		Scale: s,
 	}
 
 	// Initialize all the Nodes
+	var (
+		sminx, sminy = w, h
+		smaxx, smaxy = -1, -1
+		zoneRows     = make(map[Zone]map[int]struct{})
+	)
 	for wi := 0; wi < w; wi++ {
 		nx := ox + (s * wi)
 		g.Nodes[nx] = make(map[int]*Node)
 		for hi := 0; hi < h; hi++ {
+			if wi >= len(zones[hi]) || zones[hi][wi] == NoZone {
+				continue
+			}
 			ny := oy + (s * hi)
 			n := NewNode(nx, ny)
+			if hi < len(terrains) && wi < len(terrains[hi]) {
+				n.Terrain = terrains[hi][wi]
+			}
 
-			if hi < szh {
+			z := zones[hi][wi]
+			switch z {
+			case SpawnZone:
 				n.IsSpawnZone = true
-			} else if hi < szh+bzh {
+				// The units can not spawn on
+				// something they can not walk
+				if n.Terrain.CanPass(environment.Terrestrial) {
+					g.spawnNodes = append(g.spawnNodes, n)
+				}
+				sminx, sminy = min(sminx, wi), min(sminy, hi)
+				smaxx, smaxy = max(smaxx, wi), max(smaxy, hi)
+			case BuildingZone:
 				n.IsBuildingZone = true
-			} else if hi < szh+bzh+dzh {
+			case DeathZone:
 				n.IsDeathZone = true
-				if g.DeathNode == nil && wi == w/2 {
-					g.DeathNode = n
-				}
 			}
+			if _, ok := zoneRows[z]; !ok {
+				zoneRows[z] = make(map[int]struct{})
+			}
+			zoneRows[z][hi] = struct{}{}
 
 			g.Nodes[nx][ny] = n
 		}
 	}
 
+	g.SpawnZoneH = len(zoneRows[SpawnZone])
+	g.BuildingZoneH = len(zoneRows[BuildingZone])
+	g.DeathZoneH = len(zoneRows[DeathZone])
+
+	if len(g.spawnNodes) == 0 || g.DeathZoneH == 0 {
+		return nil, ErrInvalidZones
+	}
+
+	// The center of the Spawn Zone
+	scx := ox + s*(sminx+(smaxx-sminx+1)/2)
+	scy := oy + s*(sminy+(smaxy-sminy+1)/2)
+	sc := NewNode(scx, scy)
+	for wi := 0; wi < w; wi++ {
+		nx := ox + (s * wi)
+		for hi := 0; hi < h; hi++ {
+			n := g.GetNode(nx, oy+(s*hi))
+			if n == nil || !n.IsDeathZone || !n.Terrain.CanPass(environment.Terrestrial) {
+				continue
+			}
+			if g.DeathNode == nil || n.MDistance(sc) < g.DeathNode.MDistance(sc) {
+				g.DeathNode = n
+			}
+		}
+	}
+
 	// Set the Neighbors of all the Nodes
 	for wi := 0; wi < w; wi++ {
 		nx := ox + (s * wi)
 		for hi := 0; hi < h; hi++ {
 			ny := oy + (s * hi)
 			n := g.GetNode(nx, ny)
+			if n == nil {
+				continue
+			}
 
 			// Set the specific Neighbors
 			n.TopNeighbor = g.GetNode(nx, oy+(s*(hi-1)))
@@ -139,6 +240,18 @@ func New(ox, oy, w, h, s, szh, bzh, dzh int) (*Graph, error) {
 		}
 	}
 
+	if g.DeathNode == nil {
+		return nil, ErrInvalidZones
+	}
+
+	// All the Spawn Zone has to be able to reach the DeathNode
+	rns := reachable(g.DeathNode, environment.Terrestrial, false)
+	for _, sn := range g.spawnNodes {
+		if _, ok := rns[sn]; !ok {
+			return nil, ErrInvalidBlockingPath
+		}
+	}
+
 	return g, nil
 }
 
@@ -162,12 +275,9 @@ func (g *Graph) GetNodeOf(x, y int) *Node {
 }
 
 // GetRandomSpawnNode returns a random node on the Spawn zone
-func (g *Graph) GetRandomSpawnNode() *Node {
-	p := rand.Intn(g.W * g.SpawnZoneH)
-	x := p % g.W
-	y := p % g.SpawnZoneH
-	return g.GetNode(g.OffsetX+(x*g.Scale), g.OffsetY+(y*g.Scale))
-	//return g.GetNode(g.OffsetX, g.OffsetY)
+// using r so the same seed returns always the same nodes
+func (g *Graph) GetRandomSpawnNode(r *rand.Rand) *Node {
+	return g.spawnNodes[r.Intn(len(g.spawnNodes))]
 }
 
 // AddTower adds a tower to the desired X,Y location
@@ -181,6 +291,8 @@ func (g *Graph) AddTower(id string, x, y, w, h int) error {
 	for _, n := range nodes {
 		n.TowerID = id
 	}
+	g.addFlowTowers(nodes)
+	g.towersVersion++
 
 	// When a new tower is added we remove all the
 	// cached paths by removing the Node.NextStep
@@ -194,6 +306,38 @@ func (g *Graph) AddTower(id string, x, y, w, h int) error {
 }
 
 func (g *Graph) canAddTower(x, y, w, h int) ([]*Node, error) {
+	nodes, err := g.towerNodes(x, y, w, h)
+	if err != nil {
+		return nil, err
+	}
+
+	// In order for it to work we'll momentarily add the
+	// Tower to the graph and remove it afterwards so we
+	// can check the path
+	for _, n := range nodes {
+		n.TowerID = "test"
+	}
+	defer func() {
+		for _, n := range nodes {
+			n.TowerID = ""
+		}
+	}()
+
+	// Validates that adding the tower will not block the path
+	// from any of the Spawn Zone to the DeathNode
+	rns := reachable(g.DeathNode, environment.Terrestrial, true)
+	for _, sn := range g.spawnNodes {
+		if _, ok := rns[sn]; !ok {
+			return nil, ErrInvalidBlockingPath
+		}
+	}
+
+	return nodes, nil
+}
+
+// towerNodes returns the Nodes a Tower on x, y with w, h would use
+// if they exist and it's possible to place a Tower on them
+func (g *Graph) towerNodes(x, y, w, h int) ([]*Node, error) {
 	w = w / g.Scale
 	h = h / g.Scale
 
@@ -219,49 +363,77 @@ func (g *Graph) canAddTower(x, y, w, h int) ([]*Node, error) {
 			return nil, ErrInvalidPosition
 		} else if n.IsDeathZone {
 			return nil, ErrInvalidPosition
+		} else if !n.Terrain.CanBuild() {
+			return nil, ErrInvalidPosition
 		}
 	}
 
-	// In order for it to work we'll momentarily add the
-	// Tower to the graph and remove it afterwards so we
-	// can run Path
+	return nodes, nil
+}
+
+// PreviewTower returns the shortest path, and its length in tiles, from the
+// Spawn Zone to the DeathNode if a Tower was placed on x, y with w, h.
+// The Graph is not changed and if the Tower would block the path
+// it returns ErrInvalidBlockingPath
+func (g *Graph) PreviewTower(x, y, w, h int) ([]Step, int, error) {
+	nodes, err := g.towerNodes(x, y, w, h)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	blocked := make(map[*Node]struct{}, len(nodes))
 	for _, n := range nodes {
-		n.TowerID = "test"
+		blocked[n] = struct{}{}
 	}
-	defer func() {
-		for _, n := range nodes {
-			n.TowerID = ""
-		}
-	}()
 
-	// Validates that adding the tower will not block the path
-	// from top to bottom
-	steps, _ := g.Path(float64(g.OffsetX), float64(g.OffsetY), basicTPS, utils.Down, g.DeathNode.X, g.DeathNode.Y, environment.Terrestrial, !isAttacker, !atScale, !useCache)
-	if len(steps) == 0 {
-		return nil, ErrInvalidBlockingPath
+	steps := g.shortestPath(blocked)
+	if steps == nil {
+		return nil, 0, ErrInvalidBlockingPath
 	}
+	return steps, len(steps) - 1, nil
+}
 
-	return nodes, nil
+// ShortestPath returns the shortest path, and its length in tiles, from the
+// Spawn Zone to the DeathNode. With the flow field it's just followed
+func (g *Graph) ShortestPath() ([]Step, int) {
+	var steps []Step
+	if g.flowField {
+		steps = g.spawnPath(func(n *Node) int { return n.flowDistance })
+	} else {
+		steps = g.shortestPath(nil)
+	}
+	if steps == nil {
+		return nil, 0
+	}
+	return steps, len(steps) - 1
 }
 
+// TowersVersion returns a value that changes each time a Tower is added
+// or removed, so what depends on them can be cached until it changes
+func (g *Graph) TowersVersion() int { return g.towersVersion }
+
 func (g *Graph) CanAddTower(x, y, w, h int) bool {
 	_, err := g.canAddTower(x, y, w, h)
 	return err == nil
 }
 
 func (g *Graph) RemoveTower(id string) bool {
-	var found bool
+	var nodes []*Node
 
 	for _, xn := range g.Nodes {
 		for _, n := range xn {
 			if n.TowerID == id {
-				found = true
+				nodes = append(nodes, n)
 				n.TowerID = ""
 			}
 		}
 	}
 
+	found := len(nodes) != 0
 	if found {
+		g.removeFlowTowers(nodes)
+		g.towersVersion++
+
 		for _, xn := range g.Nodes {
 			for _, n := range xn {
 				n.NextStep = nil
@@ -271,3 +443,23 @@ func (g *Graph) RemoveTower(id string) bool {
 
 	return found
 }
+
+// reachable returns all the Nodes that can be reached from n
+// by the units of the env, if towers is true the Nodes with
+// Towers can not be crossed
+func reachable(n *Node, env environment.Environment, towers bool) map[*Node]struct{} {
+	rns := map[*Node]struct{}{n: {}}
+	q := []*Node{n}
+	for len(q) != 0 {
+		cn := q[0]
+		q = q[1:]
+		for _, nn := range cn.Neighbors {
+			if _, ok := rns[nn]; ok || !nn.Terrain.CanPass(env) || (towers && nn.HasTower()) {
+				continue
+			}
+			rns[nn] = struct{}{}
+			q = append(q, nn)
+		}
+	}
+	return rns
+}
@@ -3,10 +3,13 @@ package graph_test
 import (
 	"errors"
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xescugc/maze-wars/unit/environment"
+	"github.com/xescugc/maze-wars/utils"
 	"github.com/xescugc/maze-wars/utils/graph"
 )
 
@@ -180,6 +183,179 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestNewWithZones(t *testing.T) {
+	var (
+		n = graph.NoZone
+		s = graph.SpawnZone
+		b = graph.BuildingZone
+		d = graph.DeathZone
+	)
+	t.Run("Success", func(t *testing.T) {
+		// A lane that goes to the right and then down
+		g, err := graph.NewWithZones(10, 10, 1, [][]graph.Zone{
+			{s, b, b, b},
+			{n, n, n, b},
+			{n, n, n, d},
+		}, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, g.W)
+		assert.Equal(t, 3, g.H)
+		assert.Equal(t, 1, g.SpawnZoneH)
+		assert.Equal(t, 2, g.BuildingZoneH)
+		assert.Equal(t, 1, g.DeathZoneH)
+		assert.Equal(t, g.GetNode(13, 12), g.DeathNode)
+
+		assert.Nil(t, g.GetNode(10, 11))
+		assert.True(t, g.GetNode(10, 10).IsSpawnZone)
+		assert.Nil(t, g.GetNode(10, 10).BottomNeighbor)
+		assert.Equal(t, g.GetNode(13, 11), g.GetNode(13, 10).BottomNeighbor)
+
+		r := rand.New(rand.NewSource(0))
+		assert.Equal(t, g.GetNode(10, 10), g.GetRandomSpawnNode(r))
+
+		steps, _ := g.Path(10, 10, 1, utils.Right, 13, 12, environment.Terrestrial, false, false, false)
+		require.NotEmpty(t, steps)
+		assert.Len(t, steps, 6)
+
+		// It would close the lane
+		assert.False(t, g.CanAddTower(13, 11, 1, 1))
+	})
+	t.Run("Error", func(t *testing.T) {
+		_, err := graph.NewWithZones(0, 0, 1, [][]graph.Zone{{b, b}, {d, d}}, nil)
+		assert.ErrorIs(t, err, graph.ErrInvalidZones)
+
+		_, err = graph.NewWithZones(0, 0, 1, [][]graph.Zone{{s, s}, {b, b}}, nil)
+		assert.ErrorIs(t, err, graph.ErrInvalidZones)
+
+		// The Rocks and Water close the line
+		_, err = graph.NewWithZones(0, 0, 1, [][]graph.Zone{{s, s}, {b, b}, {d, d}}, [][]graph.Terrain{
+			{graph.Ground, graph.Ground},
+			{graph.Rock, graph.Water},
+			{graph.Ground, graph.Ground},
+		})
+		assert.ErrorIs(t, err, graph.ErrInvalidBlockingPath)
+	})
+}
+
+func TestGraph_Terrain(t *testing.T) {
+	var (
+		s = graph.SpawnZone
+		b = graph.BuildingZone
+		d = graph.DeathZone
+
+		gr = graph.Ground
+		rk = graph.Rock
+		wt = graph.Water
+		md = graph.Mud
+	)
+	zones := [][]graph.Zone{
+		{s, s, s},
+		{b, b, b},
+		{b, b, b},
+		{d, d, d},
+	}
+	t.Run("Rock", func(t *testing.T) {
+		g, err := graph.NewWithZones(0, 0, 1, zones, [][]graph.Terrain{
+			{rk, gr, gr},
+			{gr, rk, gr},
+			{gr, rk, gr},
+			{gr, gr, gr},
+		})
+		require.NoError(t, err)
+
+		// The units do not spawn on it
+		r := rand.New(rand.NewSource(0))
+		for i := 0; i < 20; i++ {
+			assert.NotEqual(t, g.GetNode(0, 0), g.GetRandomSpawnNode(r))
+		}
+		// The DeathNode has to be reachable
+		assert.Equal(t, g.GetNode(1, 3), g.DeathNode)
+
+		assert.False(t, g.CanAddTower(1, 1, 1, 1))
+		assert.True(t, g.CanAddTower(0, 1, 1, 1))
+		// It would close the line
+		assert.False(t, g.CanAddTower(2, 2, 1, 1))
+
+		// Nobody can cross it so the Aerial ones
+		// can not fly straight down
+		for _, env := range []environment.Environment{environment.Terrestrial, environment.Aerial} {
+			steps, _ := g.Path(1, 0, 60, utils.Down, 1, 3, env, !isAttacker, !atScale, !useCache)
+			require.NotEmpty(t, steps, env.String())
+			for _, st := range steps {
+				assert.NotEqual(t, rk, g.GetNode(int(st.X), int(st.Y)).Terrain, env.String())
+			}
+		}
+	})
+	t.Run("Water", func(t *testing.T) {
+		g, err := graph.NewWithZones(0, 0, 1, zones, [][]graph.Terrain{
+			{gr, gr, gr},
+			{gr, wt, gr},
+			{gr, wt, gr},
+			{gr, gr, gr},
+		})
+		require.NoError(t, err)
+
+		assert.False(t, g.CanAddTower(1, 1, 1, 1))
+
+		steps, _ := g.Path(1, 0, 60, utils.Down, 1, 3, environment.Terrestrial, !isAttacker, !atScale, !useCache)
+		assert.Len(t, steps, 6)
+
+		steps, _ = g.Path(1, 0, 60, utils.Down, 1, 3, environment.Aerial, !isAttacker, !atScale, !useCache)
+		assert.Equal(t, []graph.Step{
+			{X: 1, Y: 0, Facing: utils.Down},
+			{X: 1, Y: 1, Facing: utils.Down},
+			{X: 1, Y: 2, Facing: utils.Down},
+			{X: 1, Y: 3, Facing: utils.Down},
+		}, steps)
+	})
+	t.Run("Mud", func(t *testing.T) {
+		g, err := graph.NewWithZones(0, 0, 1, [][]graph.Zone{
+			{s, s, s, s},
+			{b, b, b, b},
+			{b, b, b, b},
+			{b, b, b, b},
+			{d, d, d, d},
+		}, [][]graph.Terrain{
+			{gr, gr, gr, gr},
+			{md, md, gr, gr},
+			{md, md, gr, gr},
+			{md, md, gr, gr},
+			{gr, gr, gr, gr},
+		})
+		require.NoError(t, err)
+
+		// It's possible to build on it
+		assert.True(t, g.CanAddTower(0, 1, 1, 1))
+
+		// It goes around it as it's cheaper than going straight
+		steps, _ := g.Path(0, 0, 60, utils.Down, 0, 4, environment.Terrestrial, !isAttacker, !atScale, !useCache)
+		require.NotEmpty(t, steps)
+		var mns int
+		for _, st := range steps {
+			if g.GetNode(int(st.X), int(st.Y)).Terrain == md {
+				mns++
+			}
+		}
+		assert.Less(t, mns, 3)
+
+		// It's slower to walk over it
+		g, err = graph.NewWithZones(0, 0, 16, zones, [][]graph.Terrain{
+			{md, md, md},
+			{md, md, md},
+			{md, md, md},
+			{gr, gr, gr},
+		})
+		require.NoError(t, err)
+		gg, err := graph.NewWithZones(0, 0, 16, zones, nil)
+		require.NoError(t, err)
+
+		msteps, _ := g.Path(16, 0, 1, utils.Down, 16, 48, environment.Terrestrial, !isAttacker, atScale, !useCache)
+		gsteps, _ := gg.Path(16, 0, 1, utils.Down, 16, 48, environment.Terrestrial, !isAttacker, atScale, !useCache)
+		assert.Greater(t, len(msteps), len(gsteps))
+	})
+}
+
 func TestGraph_GetNode(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		t.Run("Basic", func(t *testing.T) {
@@ -270,8 +446,9 @@ func TestGraph_GetRandomSpawnNode(t *testing.T) {
 				g.GetNode(0, 0): {}, g.GetNode(1, 0): {}, g.GetNode(2, 0): {},
 			}
 
+			r := rand.New(rand.NewSource(0))
 			for i := 0; i < 20; i++ {
-				n := g.GetRandomSpawnNode()
+				n := g.GetRandomSpawnNode(r)
 				require.NotNil(t, n)
 
 				_, ok := snds[n]
@@ -286,14 +463,25 @@ func TestGraph_GetRandomSpawnNode(t *testing.T) {
 				g.GetNode(10, 10): {}, g.GetNode(26, 10): {}, g.GetNode(42, 10): {},
 			}
 
+			r := rand.New(rand.NewSource(0))
 			for i := 0; i < 20; i++ {
-				n := g.GetRandomSpawnNode()
+				n := g.GetRandomSpawnNode(r)
 				require.NotNil(t, n)
 
 				_, ok := snds[n]
 				assert.True(t, ok, fmt.Sprintf("X: %d, Y: %d", n.X, n.Y))
 			}
 		})
+		t.Run("SameSeed", func(t *testing.T) {
+			g, err := graph.New(0, 0, 16, 84, 16, 7, 74, 3)
+			require.NoError(t, err)
+
+			r1 := rand.New(rand.NewSource(42))
+			r2 := rand.New(rand.NewSource(42))
+			for i := 0; i < 20; i++ {
+				assert.Equal(t, g.GetRandomSpawnNode(r1), g.GetRandomSpawnNode(r2))
+			}
+		})
 	})
 }
 
@@ -401,6 +589,23 @@ func TestGraph_AddTower(t *testing.T) {
 				}
 			}
 		})
+		t.Run("WithScaleAndOffset1x3", func(t *testing.T) {
+			g, err := graph.New(10, 10, 3, 5, 16, 1, 3, 1)
+			require.NoError(t, err)
+
+			err = g.AddTower("id", 26, 26, 16, 48)
+			require.NoError(t, err)
+
+			for _, n := range g.Nodes {
+				for _, nn := range n {
+					if nn.X == 26 && nn.Y >= 26 && nn.Y <= 58 {
+						assert.True(t, nn.HasTower(), "%d,%d", nn.X, nn.Y)
+					} else {
+						assert.False(t, nn.HasTower(), "%d,%d", nn.X, nn.Y)
+					}
+				}
+			}
+		})
 	})
 	t.Run("Error", func(t *testing.T) {
 		t.Run("ErrInvalidBoundaries", func(t *testing.T) {
@@ -491,6 +696,79 @@ func TestGraph_CanAddTower(t *testing.T) {
 	})
 }
 
+func TestGraph_PreviewTower(t *testing.T) {
+	g, err := graph.New(0, 0, 3, 5, 1, 1, 3, 1)
+	require.NoError(t, err)
+
+	steps, l := g.ShortestPath()
+	assert.Equal(t, 4, l)
+	assert.Equal(t, []graph.Step{
+		{X: 1, Y: 0, Facing: utils.Down},
+		{X: 1, Y: 1, Facing: utils.Down},
+		{X: 1, Y: 2, Facing: utils.Down},
+		{X: 1, Y: 3, Facing: utils.Down},
+		{X: 1, Y: 4, Facing: utils.Down},
+	}, steps)
+
+	t.Run("Success", func(t *testing.T) {
+		steps, l, err := g.PreviewTower(1, 2, 1, 1)
+		require.NoError(t, err)
+		// It starts from the closest Spawn Node
+		assert.Equal(t, 5, l)
+		assert.Len(t, steps, 6)
+		for _, s := range steps {
+			assert.False(t, s.X == 1 && s.Y == 2, "It goes around the Tower")
+		}
+
+		// The Graph is not changed
+		assert.False(t, g.GetNode(1, 2).HasTower())
+		_, l = g.ShortestPath()
+		assert.Equal(t, 4, l)
+	})
+	t.Run("BlockingPath", func(t *testing.T) {
+		require.NoError(t, g.AddTower("1", 0, 2, 1, 1))
+		require.NoError(t, g.AddTower("2", 1, 2, 1, 1))
+		defer g.RemoveTower("1")
+		defer g.RemoveTower("2")
+
+		_, l := g.ShortestPath()
+		assert.Equal(t, 5, l)
+
+		_, _, err := g.PreviewTower(2, 2, 1, 1)
+		assert.ErrorIs(t, err, graph.ErrInvalidBlockingPath)
+	})
+	t.Run("FlowField", func(t *testing.T) {
+		fg, err := graph.New(0, 0, 3, 5, 1, 1, 3, 1)
+		require.NoError(t, err)
+		fg.EnableFlowField()
+
+		v := fg.TowersVersion()
+		require.NoError(t, fg.AddTower("1", 0, 2, 1, 1))
+		assert.NotEqual(t, v, fg.TowersVersion())
+		require.NoError(t, g.AddTower("1", 0, 2, 1, 1))
+		defer g.RemoveTower("1")
+
+		// It's the same path as without the flow field
+		fsteps, fl := fg.ShortestPath()
+		steps, l := g.ShortestPath()
+		assert.Equal(t, l, fl)
+		assert.Equal(t, steps, fsteps)
+
+		v = fg.TowersVersion()
+		assert.True(t, fg.RemoveTower("1"))
+		assert.NotEqual(t, v, fg.TowersVersion())
+		_, fl = fg.ShortestPath()
+		assert.Equal(t, 4, fl)
+	})
+	t.Run("InvalidPosition", func(t *testing.T) {
+		_, _, err := g.PreviewTower(1, 0, 1, 1)
+		assert.ErrorIs(t, err, graph.ErrInvalidPosition)
+
+		_, _, err = g.PreviewTower(5, 5, 1, 1)
+		assert.ErrorIs(t, err, graph.ErrInvalidBoundaries)
+	})
+}
+
 func TestGraph_RemoveTower(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		t.Run("Basic", func(t *testing.T) {
@@ -23,6 +23,9 @@ type Node struct {
 
 	HasPath bool
 
+	// Terrain is the kind of ground of the Node
+	Terrain Terrain
+
 	IsSpawnZone    bool
 	IsBuildingZone bool
 	IsDeathZone    bool
@@ -36,6 +39,13 @@ type Node struct {
 	NeighborSteps []Step
 
 	NextStep *Step
+
+	// flowDistance is the cost to reach the DeathNode, flowNext
+	// the Node it goes through and flowIndex the position on
+	// the flowQueue, they are only used with the flow field
+	flowDistance int
+	flowNext     *Node
+	flowIndex    int
 }
 
 // GenerateID will generate the ID concatenating X and Y
@@ -63,6 +63,9 @@ func (g *Graph) Path(sx, sy, ms float64, d utils.Direction, tx, ty int, env envi
 	if sn == nil || tn == nil {
 		return nil, ""
 	}
+	if g.canUseFlowField(sn, tn, env, isAttacker) {
+		return g.flowPath(sn, sx, sy, ms, d, atScale), ""
+	}
 	ss := Step{
 		Node:   sn,
 		Facing: d,
@@ -70,6 +73,11 @@ func (g *Graph) Path(sx, sy, ms float64, d utils.Direction, tx, ty int, env envi
 	sqi := nm.get(ss)
 	sqi.open = true
 	heap.Push(nq, sqi)
+
+	// The Aerial units go straight down to the Death Zone
+	// and if the line does not allow it they fly over the
+	// Towers to the target
+	flyDown := env == environment.Aerial && canFlyDown(sn)
 	for {
 		if nq.Len() == 0 {
 			// There's no path, return found false.
@@ -85,8 +93,8 @@ func (g *Graph) Path(sx, sy, ms float64, d utils.Direction, tx, ty int, env envi
 		// * It's aerial, which means it just goes straight down, noting to calculate
 		// * It's an attacker
 		if current.step.Node.ID == tn.ID ||
-			(checkConsecutiveSteps(current, consecutiveSteps) && useCache) ||
-			env == environment.Aerial ||
+			(checkConsecutiveSteps(current, consecutiveSteps) && useCache && env != environment.Aerial) ||
+			flyDown ||
 			(isAttacker && current.step.Node.HasTower()) {
 
 			var twID string
@@ -101,7 +109,7 @@ func (g *Graph) Path(sx, sy, ms float64, d utils.Direction, tx, ty int, env envi
 				current.step.Facing = cf
 			}
 
-			if env == environment.Aerial {
+			if flyDown {
 				// If it's an Aerial environment it has to go straight down until
 				// the next node is Death Zone
 				current = &queueItem{
@@ -121,7 +129,7 @@ func (g *Graph) Path(sx, sy, ms float64, d utils.Direction, tx, ty int, env envi
 					}
 				}
 
-			} else {
+			} else if env != environment.Aerial {
 				// This is to build up from the cache of NextStep
 				// If it has a NextStep then it builds it up from the
 				// cache that is NextStep by following it up until the end
@@ -139,93 +147,21 @@ func (g *Graph) Path(sx, sy, ms float64, d utils.Direction, tx, ty int, env envi
 				}
 			}
 			// Found a path to the goal.
-			// And it's on reverse order
-			p := []Step{}
-			curr := current
-			for curr != nil {
-				s := curr.step
-				s.X = float64(s.Node.X)
-				s.Y = float64(s.Node.Y)
-				curr = curr.parent
-				// If it's the first node of the path it has
-				// no parent so we have to check it
-				if curr != nil && env != environment.Aerial {
-					if !isAttacker {
-						curr.step.Node.NextStep = &Step{
-							Node:   s.Node,
-							Facing: s.Facing,
-						}
-					}
-				}
-				s.Node = nil
-				p = append(p, s)
-				if atScale {
-					if curr != nil {
-						dx := s.X - float64(curr.step.Node.X)
-						dy := s.Y - float64(curr.step.Node.Y)
-
-						// We calculate the number of movements needed to reach
-						// with the MS defined and on the basicTPS
-						msdx := (absF(dx) * basicTPS) / ms
-						msdy := (absF(dy) * basicTPS) / ms
-
-						// We calculate the actual distance it has to move to reach
-						// the position with the MS
-						distx := absF(dx) / msdx
-						disty := absF(dy) / msdy
-						// As diagonal moves do not exist I just need
-						// to move the difference between nodes in
-						// X and Y, which is the DX and DY
-						// Moving in X position
-						for i := 1; i < int(absF(math.Round(msdx))); i++ {
-							if dx > 0 {
-								s.X -= distx
-							} else {
-								s.X += distx
-							}
-							p = append(p, s)
-
-							// We check if the current step is the one we passed
-							// as source so we can return as it as it was at Scale
-							if math.Round(s.X) == sx && math.Round(s.Y) == sy {
-								goto REVERSE_PATH
-							}
-						}
-						// Moving in Y position
-						for i := 1; i < int(absF(math.Round(msdy))); i++ {
-							if dy > 0 {
-								s.Y -= disty
-							} else {
-								s.Y += disty
-							}
-							p = append(p, s)
-
-							// We check if the current step is the one we passed
-							// as source so we can return as it as it was at Scale
-							if math.Round(s.X) == sx && math.Round(s.Y) == sy {
-								goto REVERSE_PATH
-							}
-						}
-					}
-				}
-			}
-		REVERSE_PATH:
-			for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
-				p[i], p[j] = p[j], p[i]
-			}
-
-			return p, twID
+			return g.buildSteps(current, sx, sy, ms, env, env != environment.Aerial && !isAttacker, atScale), twID
 		}
 
 		for _, neighbor := range current.step.Node.NeighborSteps {
 			// If we know we are not gonna go to it just don't even push it to the queue
 			// If it has a tower or is not a unit zone then don't use the node
-			if neighbor.Node.HasTower() && !isAttacker {
+			if neighbor.Node.HasTower() && !isAttacker && env != environment.Aerial {
+				continue
+			}
+			if !neighbor.Node.Terrain.CanPass(env) {
 				continue
 			}
 
-			// The cost to the neighbor is always 1
-			cost := current.cost + 1
+			// The cost to the neighbor depends on the Terrain
+			cost := current.cost + neighbor.Node.Terrain.Cost(env)
 			neighborStep := nm.get(neighbor)
 			if cost < neighborStep.cost {
 				if neighborStep.open {
@@ -258,6 +194,87 @@ func (g *Graph) Path(sx, sy, ms float64, d utils.Direction, tx, ty int, env envi
 	}
 }
 
+// buildSteps returns the Steps from the start of the tree of current to it.
+// If cache is true the Node.NextStep are set so other Paths can use them
+// and the atScale is the same as on Path
+func (g *Graph) buildSteps(current *queueItem, sx, sy, ms float64, env environment.Environment, cache, atScale bool) []Step {
+	// The tree of current is on reverse order
+	p := []Step{}
+	curr := current
+	for curr != nil {
+		s := curr.step
+		s.X = float64(s.Node.X)
+		s.Y = float64(s.Node.Y)
+		// The Terrain can make it slower to move to the Node
+		sms := ms / float64(s.Node.Terrain.Cost(env))
+		curr = curr.parent
+		// If it's the first node of the path it has
+		// no parent so we have to check it
+		if curr != nil && cache {
+			curr.step.Node.NextStep = &Step{
+				Node:   s.Node,
+				Facing: s.Facing,
+			}
+		}
+		s.Node = nil
+		p = append(p, s)
+		if atScale {
+			if curr != nil {
+				dx := s.X - float64(curr.step.Node.X)
+				dy := s.Y - float64(curr.step.Node.Y)
+
+				// We calculate the number of movements needed to reach
+				// with the MS defined and on the basicTPS
+				msdx := (absF(dx) * basicTPS) / sms
+				msdy := (absF(dy) * basicTPS) / sms
+
+				// We calculate the actual distance it has to move to reach
+				// the position with the MS
+				distx := absF(dx) / msdx
+				disty := absF(dy) / msdy
+				// As diagonal moves do not exist I just need
+				// to move the difference between nodes in
+				// X and Y, which is the DX and DY
+				// Moving in X position
+				for i := 1; i < int(absF(math.Round(msdx))); i++ {
+					if dx > 0 {
+						s.X -= distx
+					} else {
+						s.X += distx
+					}
+					p = append(p, s)
+
+					// We check if the current step is the one we passed
+					// as source so we can return as it as it was at Scale
+					if math.Round(s.X) == sx && math.Round(s.Y) == sy {
+						goto REVERSE_PATH
+					}
+				}
+				// Moving in Y position
+				for i := 1; i < int(absF(math.Round(msdy))); i++ {
+					if dy > 0 {
+						s.Y -= disty
+					} else {
+						s.Y += disty
+					}
+					p = append(p, s)
+
+					// We check if the current step is the one we passed
+					// as source so we can return as it as it was at Scale
+					if math.Round(s.X) == sx && math.Round(s.Y) == sy {
+						goto REVERSE_PATH
+					}
+				}
+			}
+		}
+	}
+REVERSE_PATH:
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
 type queue []*queueItem
 
 func (q queue) Len() int {
@@ -290,6 +307,20 @@ func (q *queue) Pop() interface{} {
 	return item
 }
 
+// canFlyDown checks if going straight down from n reaches the Death Zone
+// without crossing any Terrain the Aerial units can not pass
+func canFlyDown(n *Node) bool {
+	for ; n != nil; n = n.BottomNeighbor {
+		if !n.Terrain.CanPass(environment.Aerial) {
+			return false
+		}
+		if n.IsDeathZone {
+			return true
+		}
+	}
+	return false
+}
+
 // checkConsecutiveSteps will check in the queueItem tree if it has
 // 'c' consecutive steps before returning true or false
 func checkConsecutiveSteps(qi *queueItem, c int) bool {
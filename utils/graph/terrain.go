@@ -0,0 +1,47 @@
+package graph
+
+import "github.com/xescugc/maze-wars/unit/environment"
+
+// Terrain is the kind of ground of a Node
+type Terrain int
+
+const (
+	// Ground is the default Terrain with no restrictions
+	Ground Terrain = iota
+	// Rock can not be crossed by any unit
+	Rock
+	// Water can only be crossed by the environment.Aerial units
+	Water
+	// Mud slows down the units that walk over it
+	Mud
+)
+
+const (
+	// mudCost is how much more expensive is to move
+	// to a Mud Node compared to a Ground one
+	mudCost = 3
+)
+
+// CanPass checks if the units of the env can move over t
+func (t Terrain) CanPass(env environment.Environment) bool {
+	switch t {
+	case Rock:
+		return false
+	case Water:
+		return env == environment.Aerial
+	}
+	return true
+}
+
+// CanBuild checks if it's possible to place a Tower on t
+func (t Terrain) CanBuild() bool {
+	return t != Rock && t != Water
+}
+
+// Cost is the cost to move to a Node of t for the units of the env
+func (t Terrain) Cost(env environment.Environment) int {
+	if t == Mud && env != environment.Aerial {
+		return mudCost
+	}
+	return 1
+}